@@ -0,0 +1,75 @@
+// Command media-gc finds product photos in media storage that no product
+// uses any more, because the product was deleted or saving the photo failed
+// after it was uploaded.
+//
+//	go run ./cmd/media-gc                       # report only
+//	go run ./cmd/media-gc -apply                # delete the orphans
+//	go run ./cmd/media-gc -apply -quarantine    # move them under quarantine/
+//
+// Objects newer than -grace (24h by default) are skipped, since their photo
+// may still be being saved. Cached transformations of orphans are always
+// deleted rather than quarantined. It uses the same MEDIA_STORAGE settings as
+// the API.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/rdsarjito/marketplace-backend/config"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/services"
+	"github.com/rdsarjito/marketplace-backend/storage"
+)
+
+func main() {
+	apply := flag.Bool("apply", false, "remove the orphans instead of only reporting them")
+	quarantine := flag.Bool("quarantine", false, "with -apply, move orphans under "+constants.MediaQuarantinePrefix+" instead of deleting them")
+	grace := flag.Duration("grace", constants.MediaGCGracePeriod, "skip objects modified more recently than this")
+	flag.Parse()
+
+	config.LoadConfig()
+	db := config.InitDatabase()
+
+	mediaStorage, err := storage.NewMediaStorageFromEnv()
+	if err != nil {
+		log.Fatal("Error opening media storage: ", err)
+	}
+
+	gcService := services.NewMediaGCService(repositories.NewProductRepository(db), mediaStorage)
+	report, err := gcService.RemoveOrphans(context.Background(), services.MediaGCOptions{
+		GracePeriod: *grace,
+		DryRun:      !*apply,
+		Quarantine:  *quarantine,
+	})
+	if err != nil {
+		log.Fatal("Error removing orphaned media: ", err)
+	}
+
+	fmt.Printf("Scanned %d objects\n", report.Scanned)
+	if len(report.Orphans) == 0 {
+		fmt.Println("No orphaned objects found")
+		return
+	}
+	fmt.Printf("%d orphaned objects, %d bytes:\n", len(report.Orphans), report.OrphanedBytes)
+	for _, orphan := range report.Orphans {
+		line := fmt.Sprintf("  %s size=%d modified=%s", orphan.Name, orphan.Size, orphan.LastModified.Format("2006-01-02 15:04:05"))
+		if orphan.Error != "" {
+			line += " error=" + orphan.Error
+		}
+		fmt.Println(line)
+	}
+
+	if !*apply {
+		fmt.Println("Run with -apply to remove them")
+		return
+	}
+	verb := "Deleted"
+	if *quarantine {
+		verb = "Quarantined"
+	}
+	fmt.Printf("%s %d objects, %d failed\n", verb, report.Removed, report.Failed)
+}
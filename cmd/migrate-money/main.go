@@ -0,0 +1,51 @@
+// Command migrate-money converts prices still stored as text to whole rupiah.
+// The API refuses to start until this has been applied.
+//
+//	go run ./cmd/migrate-money          # report only
+//	go run ./cmd/migrate-money -apply   # also rewrite the values
+//
+// -apply writes nothing while any price cannot be parsed; fix the reported
+// rows first. After -apply, starting the API changes the columns to bigint.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/rdsarjito/marketplace-backend/config"
+)
+
+func main() {
+	apply := flag.Bool("apply", false, "rewrite prices as plain digits instead of only reporting")
+	flag.Parse()
+
+	config.LoadConfig()
+	db := config.OpenDatabase()
+
+	report, err := config.MigrateMoneyColumns(db, !*apply)
+	if err != nil && !errors.Is(err, config.ErrInvalidMoneyValues) {
+		log.Fatal("Error converting prices: ", err)
+	}
+
+	if len(report.Invalid) > 0 {
+		fmt.Printf("%d prices cannot be parsed, fix them before converting:\n", len(report.Invalid))
+		for _, invalid := range report.Invalid {
+			fmt.Printf("  %s.%s id=%d value=%q\n", invalid.Table, invalid.Column, invalid.ID, invalid.Value)
+		}
+		if *apply {
+			fmt.Println("Nothing was converted")
+			os.Exit(1)
+		}
+	} else {
+		fmt.Println("All prices can be parsed")
+	}
+
+	verb := "Would convert"
+	if *apply {
+		verb = "Converted"
+	}
+	fmt.Printf("%s %d prices\n", verb, report.Converted)
+}
@@ -0,0 +1,53 @@
+// Command reindex rebuilds the product search index from the database.
+//
+//	go run ./cmd/reindex
+//
+// It uses the same SEARCH_ENGINE and SEARCH_INDEX_PATH settings as the API.
+// The embedded Bleve index can only be opened by one process, so stop the API
+// before reindexing it.
+package main
+
+import (
+	"log"
+
+	"github.com/rdsarjito/marketplace-backend/config"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/search"
+)
+
+const batchSize = 500
+
+func main() {
+	config.LoadConfig()
+	db := config.InitDatabase()
+
+	searchIndex, err := search.NewSearchIndexFromEnv(db)
+	if err != nil {
+		log.Fatal("Error opening search index: ", err)
+	}
+	defer searchIndex.Close()
+
+	productRepository := repositories.NewProductRepository(db)
+
+	indexed := 0
+	err = searchIndex.Rebuild(func(add func(docs []search.Document) error) error {
+		return productRepository.FindInBatches(batchSize, func(products []model.Product) error {
+			docs := make([]search.Document, 0, len(products))
+			for _, product := range products {
+				docs = append(docs, search.DocumentFromProduct(product))
+			}
+			if err := add(docs); err != nil {
+				return err
+			}
+			indexed += len(docs)
+			log.Printf("Indexed %d products", indexed)
+			return nil
+		})
+	})
+	if err != nil {
+		log.Fatal("Error rebuilding search index: ", err)
+	}
+
+	log.Println("Search index rebuilt")
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/joho/godotenv"
+	"github.com/rdsarjito/marketplace-backend/constants"
 )
 
 type Config struct {
@@ -14,6 +15,14 @@ type Config struct {
 	MidtransClientKey    string
 	MidtransIsProduction bool
 	FrontendURL          string // Frontend URL for payment redirect
+
+	// Limits on shops that have not been verified yet
+	UnverifiedShopMaxProducts int
+	UnverifiedShopPayouts     bool
+
+	// Seller ledger settings
+	PlatformCommissionBps int
+	SettlementHoldDays    int
 }
 
 func LoadConfig() *Config {
@@ -26,6 +35,12 @@ func LoadConfig() *Config {
 		MidtransClientKey:    getEnv("MIDTRANS_CLIENT_KEY", ""),
 		MidtransIsProduction: getEnvBool("MIDTRANS_IS_PRODUCTION", false),
 		FrontendURL:          getEnv("FRONTEND_URL", "http://localhost:5173"),
+
+		UnverifiedShopMaxProducts: getEnvInt("UNVERIFIED_SHOP_MAX_PRODUCTS", constants.DefaultUnverifiedShopMaxProducts),
+		UnverifiedShopPayouts:     getEnvBool("UNVERIFIED_SHOP_PAYOUTS", false),
+
+		PlatformCommissionBps: getEnvInt("PLATFORM_COMMISSION_BPS", constants.DefaultPlatformCommissionBps),
+		SettlementHoldDays:    getEnvInt("SETTLEMENT_HOLD_DAYS", constants.DefaultSettlementHoldDays),
 	}
 }
 
@@ -36,6 +51,15 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := strconv.ParseBool(value); err == nil {
@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/rdsarjito/marketplace-backend/domain/model"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-func InitDatabase() *gorm.DB {
+// OpenDatabase connects to the database without migrating it.
+func OpenDatabase() *gorm.DB {
 	var (
 		DBHost     = os.Getenv("DB_HOST")
 		DBPort     = os.Getenv("DB_PORT")
@@ -27,17 +29,81 @@ func InitDatabase() *gorm.DB {
 		log.Fatal(err.Error())
 	}
 
+	return db
+}
+
+func InitDatabase() *gorm.DB {
+	db := OpenDatabase()
+
+	// Prices must be numeric before AutoMigrate turns their columns into
+	// bigint. Converting them is left to cmd/migrate-money so no price is
+	// rewritten without someone looking at the report first.
+	pending, err := PendingMoneyColumns(db)
+	if err != nil {
+		log.Fatal("Error checking prices: ", err.Error())
+	}
+	if len(pending) > 0 {
+		log.Fatalf("Prices in %s are still stored as text; run go run ./cmd/migrate-money -apply first", strings.Join(pending, ", "))
+	}
+
+	// Handles must be unique before AutoMigrate adds their index
+	renamed, err := MigrateShopHandles(db)
+	if err != nil {
+		log.Fatal("Error migrating shop handles: ", err.Error())
+	}
+	if renamed > 0 {
+		log.Printf("[Shop] Gave %d shops a new handle", renamed)
+	}
+
+	// A user may own only one shop; which one to keep is left to a person
+	owners, err := DuplicateShopOwners(db)
+	if err != nil {
+		log.Fatal("Error checking shop owners: ", err.Error())
+	}
+	if len(owners) > 0 {
+		log.Fatalf("Users %v own more than one shop; keep one shop per user before starting", owners)
+	}
+
 	err = db.AutoMigrate(
 		&model.User{},
 		&model.Product{},
 		&model.PhotoProduct{},
+		&model.PhotoRendition{},
+		&model.PhotoUpload{},
+		&model.PrivateMedia{},
+		&model.ProductOption{},
+		&model.ProductOptionValue{},
+		&model.ProductVariant{},
 		&model.LogProduct{},
+		&model.ProductImport{},
+		&model.ProductImportError{},
 		&model.Category{},
 		&model.Address{},
 		&model.Shop{},
+		&model.ShopOpeningHour{},
+		&model.ShopVerification{},
+		&model.ShopVerificationDocument{},
 		&model.TRX{},
 		&model.DetailTRX{},
+		&model.LedgerJournal{},
+		&model.LedgerEntry{},
+		&model.ShopBankAccount{},
+		&model.Payout{},
 		&model.PasswordResetToken{},
+		&model.PhoneOTP{},
+		&model.UserTwoFactor{},
+		&model.RecoveryCode{},
+		&model.UserIdentity{},
+		&model.OAuthExchangeCode{},
+		&model.LoginThrottle{},
+		&model.FailedLogin{},
+		&model.AccountUnlockToken{},
+		&model.Permission{},
+		&model.Role{},
+		&model.UserRole{},
+		&model.ShopMember{},
+		&model.ShopInvitation{},
+		&model.ResellerApplication{},
 	)
 	if err != nil {
 		log.Fatal("Error: ", err.Error())
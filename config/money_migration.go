@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rdsarjito/marketplace-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Prices used to be free-form varchar columns. They are now whole rupiah in
+// bigint columns; these are the columns converted by MigrateMoneyColumns.
+var moneyColumns = map[string][]string{
+	"produk":           {"harga_reseller", "harga_konsumen"},
+	"log_produk":       {"harga_reseller", "harga_konsumen"},
+	"product_variants": {"harga_reseller", "harga_konsumen"},
+}
+
+const moneyMigrationBatchSize = 1000
+
+// ErrInvalidMoneyValues is returned by MigrateMoneyColumns when some prices
+// cannot be parsed; nothing is converted until they are fixed.
+var ErrInvalidMoneyValues = errors.New("some prices cannot be parsed as rupiah")
+
+// InvalidMoneyValue is a stored price that could not be read as rupiah.
+type InvalidMoneyValue struct {
+	Table  string
+	Column string
+	ID     int
+	Value  string
+}
+
+// MoneyMigrationReport summarizes a run of MigrateMoneyColumns.
+type MoneyMigrationReport struct {
+	Converted int
+	Invalid   []InvalidMoneyValue
+}
+
+// MigrateMoneyColumns rewrites prices still stored as text ("15.000",
+// "Rp 15.000,00", ...) as plain digits so AutoMigrate can change the columns
+// to bigint. If any value cannot be parsed, nothing is written: the report
+// lists those values and ErrInvalidMoneyValues is returned, so no price is
+// lost to a guess. With dryRun nothing is written either. Columns that are
+// already numeric are skipped, so running it again is harmless.
+func MigrateMoneyColumns(db *gorm.DB, dryRun bool) (*MoneyMigrationReport, error) {
+	report, err := migrateMoneyColumns(db, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Invalid) > 0 {
+		if dryRun {
+			return report, nil
+		}
+		return report, ErrInvalidMoneyValues
+	}
+	if dryRun {
+		return report, nil
+	}
+	return migrateMoneyColumns(db, false)
+}
+
+// PendingMoneyColumns lists the price columns ("table.column") still stored
+// as text.
+func PendingMoneyColumns(db *gorm.DB) ([]string, error) {
+	var pending []string
+	err := forEachTextMoneyColumn(db, func(table, column string) error {
+		pending = append(pending, table+"."+column)
+		return nil
+	})
+	sort.Strings(pending)
+	return pending, err
+}
+
+func migrateMoneyColumns(db *gorm.DB, dryRun bool) (*MoneyMigrationReport, error) {
+	report := &MoneyMigrationReport{}
+	err := forEachTextMoneyColumn(db, func(table, column string) error {
+		return migrateMoneyColumn(db, table, column, dryRun, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func forEachTextMoneyColumn(db *gorm.DB, fn func(table, column string) error) error {
+	for table := range moneyColumns {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+		columnTypes, err := db.Migrator().ColumnTypes(table)
+		if err != nil {
+			return err
+		}
+		for _, columnType := range columnTypes {
+			if !isMoneyColumn(table, columnType.Name()) || !isTextType(columnType.DatabaseTypeName()) {
+				continue
+			}
+			if err := fn(table, columnType.Name()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func migrateMoneyColumn(db *gorm.DB, table, column string, dryRun bool, report *MoneyMigrationReport) error {
+	lastID := 0
+	for {
+		var rows []struct {
+			ID    int
+			Value string
+		}
+		err := db.Table(table).Select("id, "+column+" AS value").
+			Where("id > ?", lastID).Order("id ASC").Limit(moneyMigrationBatchSize).
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		lastID = rows[len(rows)-1].ID
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				amount, err := utils.ParseRupiah(row.Value)
+				if err != nil {
+					report.Invalid = append(report.Invalid, InvalidMoneyValue{Table: table, Column: column, ID: row.ID, Value: row.Value})
+					continue
+				}
+
+				normalized := strconv.FormatInt(int64(amount), 10)
+				if normalized == row.Value {
+					continue
+				}
+				report.Converted++
+				if dryRun {
+					continue
+				}
+				if err := tx.Table(table).Where("id = ?", row.ID).Update(column, normalized).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func isMoneyColumn(table, column string) bool {
+	for _, name := range moneyColumns[table] {
+		if strings.EqualFold(name, column) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTextType(databaseType string) bool {
+	databaseType = strings.ToLower(databaseType)
+	return strings.Contains(databaseType, "char") || strings.Contains(databaseType, "text")
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"github.com/rdsarjito/marketplace-backend/utils"
+	"gorm.io/gorm"
+)
+
+// MigrateShopHandles gives every shop a valid, unique handle in url_toko
+// before AutoMigrate adds its unique index. Shops created at registration
+// used "shop-" followed by the owner's email, which exposed the address;
+// those and any other invalid or duplicate handles are replaced with one
+// made from the shop name. Deleted shops are included, as they keep their
+// handle. It returns how many shops were changed; running it again changes
+// nothing.
+func MigrateShopHandles(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable("toko") {
+		return 0, nil
+	}
+
+	var shops []struct {
+		ID       int
+		NamaToko string
+		URLToko  string
+	}
+	err := db.Table("toko").Select("id, nama_toko, url_toko").Order("id ASC").Scan(&shops).Error
+	if err != nil {
+		return 0, err
+	}
+
+	// The oldest shop keeps a handle that is valid but used more than once
+	taken := make(map[string]bool, len(shops))
+	var rename []int
+	for i, shop := range shops {
+		if !utils.ValidShopHandle(shop.URLToko) || taken[shop.URLToko] {
+			rename = append(rename, i)
+			continue
+		}
+		taken[shop.URLToko] = true
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, i := range rename {
+			base := utils.ShopHandleFromName(shops[i].NamaToko)
+			handle := base
+			for n := 2; !utils.ValidShopHandle(handle) || taken[handle]; n++ {
+				handle = utils.NumberedShopHandle(base, n)
+			}
+			taken[handle] = true
+
+			if err := tx.Table("toko").Where("id = ?", shops[i].ID).Update("url_toko", handle).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(rename), nil
+}
+
+// DuplicateShopOwners returns the users that own more than one shop, which
+// would stop AutoMigrate from adding the unique index on id_user. Deleted
+// shops are included, as the index covers them too.
+func DuplicateShopOwners(db *gorm.DB) ([]int, error) {
+	if !db.Migrator().HasTable("toko") {
+		return nil, nil
+	}
+
+	var users []int
+	err := db.Table("toko").Select("id_user").Group("id_user").Having("COUNT(*) > 1").Order("id_user ASC").Scan(&users).Error
+	return users, err
+}
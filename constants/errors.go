@@ -35,4 +35,131 @@ const (
 	ErrExternalAPI        = "External API error"
 	ErrProvinceNotFound   = "Province not found"
 	ErrCityNotFound       = "City not found"
+
+	// OTP errors
+	ErrInvalidOTP         = "Kode OTP tidak valid"
+	ErrOTPExpired         = "Kode OTP sudah expired"
+	ErrOTPTooManyAttempts = "Terlalu banyak percobaan, silakan minta kode OTP baru"
+	ErrOTPResendCooldown  = "Silakan tunggu sebelum meminta kode OTP baru"
+	ErrPhoneAlreadyVerified = "Nomor telepon sudah terverifikasi"
+
+	// Two-factor authentication errors
+	ErrTwoFactorNotSetup       = "Two-factor authentication has not been set up"
+	ErrTwoFactorNotEnabled     = "Two-factor authentication is not enabled"
+	ErrTwoFactorAlreadyEnabled = "Two-factor authentication is already enabled"
+	ErrInvalidTwoFactorCode    = "Invalid authentication code"
+
+	// OAuth errors
+	ErrOAuthProviderNotFound = "Login provider is not available"
+	ErrOAuthFailed           = "oauth_failed"
+	ErrEmailNotVerified      = "Email not verified"
+	ErrInvalidExchangeCode   = "Invalid or expired login code"
+	ErrIdentityAlreadyLinked = "This account is already linked to another user"
+	ErrOAuthAccountExists    = "An account with this email already exists; sign in and link it from your account settings"
+	ErrInvalidLinkCode       = "Invalid or expired link code"
+	ErrIdentityNotFound      = "Linked account not found"
+	ErrCannotUnlinkLastLogin = "Set a password before removing your only login method"
+
+	// Login throttling errors
+	ErrTooManyLoginAttempts = "Too many failed attempts, please try again later"
+	ErrAccountLocked        = "Account temporarily locked due to too many failed attempts"
+	ErrInvalidUnlockToken   = "Invalid or expired unlock link"
+
+	// Role errors
+	ErrRoleNotFound         = "Role not found"
+	ErrCannotChangeOwnRoles = "You cannot change your own roles"
+
+	// Shop errors
+	ErrShopAlreadyExists   = "You already have a shop"
+	ErrInvalidShopHandle   = "Handles must be 3 to 30 lowercase letters, digits or hyphens, not only digits, and not a reserved word"
+	ErrShopHandleTaken     = "This handle is already taken"
+	ErrInvalidOpeningHours = "Each day can be listed once and must close after it opens"
+	ErrInvalidVacationEnd  = "buka_kembali must be a future date"
+	ErrShopOnVacation      = "This shop is on vacation and is not taking orders"
+	ErrProductNotInShop    = "This product is not sold by this shop"
+
+	// Shop verification errors
+	ErrShopVerificationNotFound    = "Shop verification not found"
+	ErrShopVerificationExists      = "This shop is already verified or awaiting review"
+	ErrShopVerificationNotPending  = "Only pending verifications can be approved or rejected"
+	ErrIdentityDocumentRequired    = "An identity card (ktp) document is required"
+	ErrInvalidVerificationDocument = "Documents must be your own private files of category kyc, each listed once"
+	ErrUnverifiedProductLimit      = "Unverified shops cannot have more products; verify the shop to add more"
+	ErrShopNotVerified             = "Only verified shops can do this"
+
+	// Ledger and payout errors
+	ErrTRXNotFound         = "Transaction not found"
+	ErrTRXNotPaid          = "Only paid orders can be completed"
+	ErrTRXAlreadyCompleted = "This order has already been completed"
+	ErrBankAccountRequired = "Register a bank account before requesting a payout"
+	ErrInsufficientBalance = "The amount is more than the available balance"
+	ErrPayoutBelowMinimum  = "Payouts must be at least Rp10.000"
+	ErrPayoutNotFound      = "Payout not found"
+	ErrPayoutNotPending    = "Only pending payouts can be approved or rejected"
+	ErrUnbalancedJournal   = "Ledger journal debits and credits do not match"
+	ErrInvalidSaleLine     = "Order lines must have an amount greater than 0"
+
+	// Shop membership errors
+	ErrShopMemberNotFound      = "Shop member not found"
+	ErrAlreadyShopMember       = "User is already a member of this shop"
+	ErrShopInvitationNotFound  = "Invitation not found or no longer valid"
+	ErrInvitationEmailMismatch = "This invitation was sent to a different email address"
+	ErrCannotRemoveShopOwner   = "The shop owner cannot be removed"
+
+	// Product search errors
+	ErrInvalidCursor     = "Invalid cursor"
+	ErrInvalidPriceRange = "min_harga must not be greater than max_harga"
+
+	// Product variant errors
+	ErrVariantNotFound           = "Product variant not found"
+	ErrVariantRequired           = "Please choose a variant of this product"
+	ErrVariantAttributesMismatch = "Variant attributes must use the same options as the product's other variants"
+	ErrDuplicateVariant          = "A variant with these attributes already exists"
+	ErrSKUAlreadyUsed            = "SKU is already in use"
+	ErrPhotoNotFound             = "Photo not found"
+
+	// Product photo errors
+	ErrPhotoLimitReached = "A product can have at most 8 photos"
+	ErrInvalidPhotoOrder = "photo_ids must list each of the product's photos exactly once"
+	ErrPhotoUploadFailed = "Upload failed"
+	ErrUnsupportedImage  = "Only JPEG, PNG, GIF and WebP images are supported"
+	ErrPhotoTooLarge     = "Photos must not be larger than 10 MB"
+	ErrImageTooLarge     = "The image has too many pixels"
+
+	// Direct photo upload errors
+	ErrPhotoUploadNotFound     = "Upload not found or expired"
+	ErrPhotoUploadIncomplete   = "The file has not been uploaded yet"
+	ErrPhotoUploadMismatch     = "The uploaded file does not match the declared size"
+	ErrDirectUploadUnsupported = "Direct uploads are not supported by the configured media storage"
+
+	// Media errors
+	ErrMediaNotFound            = "File not found"
+	ErrInvalidTransform         = "Invalid image transformation"
+	ErrInvalidMediaSignature    = "Invalid or missing media signature"
+	ErrMediaURLExpired          = "This link has expired"
+	ErrInvalidMediaCategory     = "Invalid file category"
+	ErrUnsupportedPrivateMedia  = "Only PDF, JPEG, PNG, GIF and WebP files are supported"
+	ErrPrivateMediaTooLarge     = "Files must not be larger than 10 MB"
+	ErrPrivateMediaUploadFailed = "Unable to store the file"
+	ErrPrivateMediaInUse        = "This file is part of a shop verification and cannot be deleted"
+
+	// Product price errors
+	ErrResellerPriceTooHigh = "harga_reseller must not be greater than harga_konsumen"
+	ErrProductPriceNotSet   = "This product has no price and cannot be ordered yet"
+
+	// Reseller program errors
+	ErrResellerApplicationExists      = "You already have a pending or approved reseller application"
+	ErrResellerApplicationNotFound    = "Reseller application not found"
+	ErrResellerApplicationNotPending  = "Only pending applications can be approved or rejected"
+	ErrResellerApplicationNotApproved = "Only approved resellers can be revoked"
+
+	// Product import errors
+	ErrProductImportNotFound    = "Product import not found"
+	ErrUnsupportedSpreadsheet   = "Only .csv and .xlsx files are supported"
+	ErrInvalidSpreadsheet       = "The file could not be read as a spreadsheet"
+	ErrSpreadsheetTooLarge      = "The file must not be larger than 10 MB"
+	ErrImportMissingColumns     = "The file is missing required columns"
+	ErrImportNoRows             = "The file has no product rows"
+	ErrImportTooManyRows        = "The file must not have more than 5000 product rows"
+	ErrProductImportInterrupted = "The import was interrupted by a server restart, please upload the file again"
 )
@@ -0,0 +1,51 @@
+package constants
+
+import "time"
+
+// Ledger accounts. Shop accounts hold what the platform owes each shop and
+// have credit balances; platform_cash is the money in the platform's
+// Midtrans account and has a debit balance.
+const (
+	LedgerAccountPlatformCash = "platform_cash"
+	LedgerAccountCommission   = "platform_commission"
+	// LedgerAccountShopPending holds sale proceeds until the order is completed
+	LedgerAccountShopPending = "shop_pending"
+	// LedgerAccountShopAvailable can be paid out
+	LedgerAccountShopAvailable = "shop_available"
+	// LedgerAccountShopPayout is reserved for payouts awaiting approval
+	LedgerAccountShopPayout = "shop_payout"
+)
+
+// Ledger journal kinds
+const (
+	LedgerJournalSale           = "sale"
+	LedgerJournalRelease        = "release"
+	LedgerJournalPayoutRequest  = "payout_request"
+	LedgerJournalPayoutApproved = "payout_approved"
+	LedgerJournalPayoutRejected = "payout_rejected"
+)
+
+// Payout statuses. Approving a payout records that the money was
+// transferred to the shop's bank account.
+const (
+	PayoutPending  = "pending"
+	PayoutApproved = "approved"
+	PayoutRejected = "rejected"
+)
+
+// DefaultPlatformCommissionBps is the commission taken from each paid order
+// line, in basis points (500 = 5%), unless PLATFORM_COMMISSION_BPS says
+// otherwise
+const DefaultPlatformCommissionBps = 500
+
+// DefaultSettlementHoldDays is how long after payment an order the buyer
+// has not completed is completed automatically, releasing its funds to the
+// shops, unless SETTLEMENT_HOLD_DAYS says otherwise
+const DefaultSettlementHoldDays = 7
+
+// SettlementReleaseInterval is how often orders past the hold period are
+// looked for
+const SettlementReleaseInterval = time.Hour
+
+// MinPayoutAmount is the smallest payout a shop can request, in rupiah
+const MinPayoutAmount = 10000
@@ -0,0 +1,32 @@
+package constants
+
+import "time"
+
+// Login failure reasons recorded in the audit log
+const (
+	LoginFailureUnknownEmail    = "unknown_email"
+	LoginFailureInvalidPassword = "invalid_password"
+	LoginFailureThrottled       = "throttled"
+)
+
+// Login throttling policy. Failures within the window count towards an
+// exponential backoff and, past the lockout threshold, a temporary lockout.
+const (
+	LoginFailureWindow = 15 * time.Minute
+	LoginBackoffBase   = time.Second
+	LoginBackoffMax    = 5 * time.Minute
+
+	AccountBackoffAfter    = 3
+	AccountLockoutAfter    = 10
+	AccountLockoutDuration = time.Hour
+
+	IPBackoffAfter    = 20
+	IPLockoutAfter    = 100
+	IPLockoutDuration = time.Hour
+
+	AccountUnlockTokenTTL = 24 * time.Hour
+
+	// A 2FA challenge is discarded after this many wrong codes; failures
+	// per user are throttled like failed passwords
+	TwoFactorChallengeMaxAttempts = 5
+)
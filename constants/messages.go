@@ -13,10 +13,29 @@ const (
 
 	MsgShopCreated        = "Shop created successfully"
 	MsgShopUpdated        = "Shop updated successfully"
+	MsgShopImageUploaded  = "Image uploaded"
+	MsgShopImageDeleted   = "Image deleted"
+
+	MsgShopInvitationSent     = "Invitation sent successfully"
+	MsgShopInvitationAccepted = "Invitation accepted"
+	MsgShopInvitationDeclined = "Invitation declined"
+	MsgShopInvitationRevoked  = "Invitation revoked"
+	MsgShopMemberUpdated      = "Shop member updated successfully"
+	MsgShopMemberRemoved      = "Shop member removed successfully"
 
 	MsgProductCreated     = "Product created successfully"
 	MsgProductUpdated     = "Product updated successfully"
 	MsgProductDeleted     = "Product deleted successfully"
+	MsgVariantCreated     = "Product variant created successfully"
+	MsgVariantUpdated     = "Product variant updated successfully"
+	MsgVariantDeleted     = "Product variant deleted successfully"
+	MsgProductHistoryRetrieved = "Product history retrieved successfully"
+	MsgProductImportStarted    = "Product import started"
+	MsgPhotoUploaded           = "Photo uploaded"
+	MsgPhotosReordered         = "Photos reordered successfully"
+	MsgPrimaryPhotoSet         = "Primary photo set successfully"
+	MsgPhotoDeleted            = "Photo deleted successfully"
+	MsgPhotoUploadCreated      = "Upload URL created"
 
 	MsgAddressCreated     = "Address created successfully"
 	MsgAddressUpdated     = "Address updated successfully"
@@ -24,6 +43,41 @@ const (
 
 	MsgTransactionCreated = "Transaction created successfully"
 
+	MsgOTPSent            = "Kode OTP telah dikirim"
+	MsgLoginOTPSent       = "Jika nomor terdaftar, kode OTP telah dikirim"
+	MsgPhoneVerified      = "Nomor telepon berhasil diverifikasi"
+
+	MsgTwoFactorRequired        = "Two-factor authentication required"
+	MsgTwoFactorSetup           = "Scan the QR code and confirm with a code from your authenticator app"
+	MsgTwoFactorEnabled         = "Two-factor authentication enabled"
+	MsgTwoFactorDisabled        = "Two-factor authentication disabled"
+	MsgRecoveryCodesRegenerated = "Recovery codes regenerated"
+
+	MsgIdentityLinked   = "Account linked"
+	MsgIdentityUnlinked = "Linked account removed"
+
+	MsgAccountUnlocked = "Account unlocked, you can sign in again"
+
+	MsgRolesUpdated = "User roles updated successfully"
+
+	MsgResellerApplied  = "Reseller application submitted"
+	MsgResellerApproved = "Reseller application approved"
+	MsgResellerRejected = "Reseller application rejected"
+	MsgResellerRevoked  = "Reseller status revoked"
+
+	MsgShopVerificationSubmitted = "Verification submitted for review"
+	MsgShopVerified              = "Shop verified"
+	MsgShopVerificationRejected  = "Shop verification rejected"
+
+	MsgTRXCompleted     = "Order completed"
+	MsgBankAccountSaved = "Bank account saved"
+	MsgPayoutRequested  = "Payout requested"
+	MsgPayoutApproved   = "Payout approved"
+	MsgPayoutRejected   = "Payout rejected"
+
+	MsgPrivateMediaUploaded = "File uploaded"
+	MsgPrivateMediaDeleted  = "File deleted"
+
 	// General messages
 	MsgSuccess            = "Success"
 	MsgDataRetrieved      = "Data retrieved successfully"
@@ -0,0 +1,17 @@
+package constants
+
+import "time"
+
+// OTP purposes
+const (
+	OTPPurposePhoneVerification = "phone_verification"
+	OTPPurposeLogin             = "login"
+)
+
+// OTP policy
+const (
+	OTPLength         = 6
+	OTPTTL            = 5 * time.Minute
+	OTPMaxAttempts    = 5
+	OTPResendCooldown = 60 * time.Second
+)
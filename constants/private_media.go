@@ -0,0 +1,21 @@
+package constants
+
+import "time"
+
+// Private media is stored under PrivateMediaPrefix and only served through
+// signed URLs that expire after PrivateMediaURLExpiry
+const (
+	PrivateMediaPrefix      = "private/"
+	PrivateMediaURLExpiry   = 15 * time.Minute
+	MaxPrivateMediaFileSize = 10 << 20
+)
+
+// Private media categories
+const (
+	PrivateMediaInvoice = "invoice"
+	PrivateMediaKYC     = "kyc"
+	PrivateMediaRefund  = "refund"
+)
+
+// PrivateMediaCategories lists the categories a file can be uploaded as.
+var PrivateMediaCategories = []string{PrivateMediaInvoice, PrivateMediaKYC, PrivateMediaRefund}
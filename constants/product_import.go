@@ -0,0 +1,53 @@
+package constants
+
+// Product import statuses
+const (
+	ProductImportPending    = "pending"
+	ProductImportProcessing = "processing"
+	ProductImportCompleted  = "completed"
+	ProductImportFailed     = "failed"
+)
+
+// Spreadsheet formats for product import and export
+const (
+	SpreadsheetCSV  = "csv"
+	SpreadsheetXLSX = "xlsx"
+)
+
+// Product import and export columns. An import file must have a header row
+// with at least the required columns, in any order
+const (
+	ProductColumnNama                 = "nama_produk"
+	ProductColumnKategori             = "kategori"
+	ProductColumnHargaReseller        = "harga_reseller"
+	ProductColumnHargaKonsumen        = "harga_konsumen"
+	ProductColumnMinKuantitasReseller = "min_kuantitas_reseller"
+	ProductColumnStok                 = "stok"
+	ProductColumnDeskripsi            = "deskripsi"
+)
+
+var ProductColumns = []string{
+	ProductColumnNama,
+	ProductColumnKategori,
+	ProductColumnHargaReseller,
+	ProductColumnHargaKonsumen,
+	ProductColumnMinKuantitasReseller,
+	ProductColumnStok,
+	ProductColumnDeskripsi,
+}
+
+// Every column is required except min_kuantitas_reseller
+var ProductImportRequiredColumns = []string{
+	ProductColumnNama,
+	ProductColumnKategori,
+	ProductColumnHargaReseller,
+	ProductColumnHargaKonsumen,
+	ProductColumnStok,
+	ProductColumnDeskripsi,
+}
+
+// Product import limits
+const (
+	ProductImportMaxFileSize = 10 << 20
+	ProductImportMaxRows     = 5000
+)
@@ -0,0 +1,59 @@
+package constants
+
+import "time"
+
+// MaxProductPhotos is how many photos a product can have
+const MaxProductPhotos = 8
+
+// Product photo upload limits. MaxPhotoPixels guards against small files
+// that decode to huge images
+const (
+	MaxPhotoFileSize = 10 << 20
+	MaxPhotoPixels   = 40_000_000
+)
+
+// Versions of each product photo kept in media storage. The original is the
+// uploaded image re-encoded without its metadata; the others are resized to
+// fit in a square of the given size and are never enlarged
+const (
+	PhotoOriginal  = "original"
+	PhotoThumbnail = "thumbnail"
+	PhotoMedium    = "medium"
+	PhotoLarge     = "large"
+	PhotoWebP      = "webp"
+
+	PhotoThumbnailSize = 150
+	PhotoMediumSize    = 600
+	PhotoLargeSize     = 1200
+)
+
+// PhotoJPEGQuality is the quality used to encode JPEG versions of photos
+const PhotoJPEGQuality = 85
+
+// Direct uploads: how long an upload URL is valid, how often expired
+// uploads are cleaned up, and their statuses
+const (
+	PhotoUploadExpiry          = 15 * time.Minute
+	PhotoUploadCleanupInterval = 10 * time.Minute
+
+	PhotoUploadPending    = "pending"
+	PhotoUploadProcessing = "processing"
+	PhotoUploadConfirmed  = "confirmed"
+)
+
+// On-the-fly transformations of media served from /media. Derived images are
+// cached in media storage under MediaCachePrefix.
+const (
+	MaxTransformSize       = 2000
+	MaxTransformSourceSize = 64 << 20
+	MediaCachePrefix       = "cache/"
+)
+
+// Cleanup of orphaned product photos. Objects under ProductMediaPrefix that
+// no photo of a live product uses are removed once they are older than
+// MediaGCGracePeriod, or moved under MediaQuarantinePrefix when quarantined.
+const (
+	ProductMediaPrefix    = "products/"
+	MediaQuarantinePrefix = "quarantine/"
+	MediaGCGracePeriod    = 24 * time.Hour
+)
@@ -0,0 +1,20 @@
+package constants
+
+// Product list sort orders
+const (
+	ProductSortNewest      = "newest"
+	ProductSortPriceAsc    = "price_asc"
+	ProductSortPriceDesc   = "price_desc"
+	ProductSortBestSelling = "best_selling"
+	ProductSortRelevance   = "relevance"
+)
+
+// Product list page sizes
+const (
+	ProductPageSizeDefault = 20
+	ProductPageSizeMax     = 100
+)
+
+// ProductSearchMaxHits caps how many keyword matches are ranked by the search
+// index before filters, sorting and pagination are applied
+const ProductSearchMaxHits = 1000
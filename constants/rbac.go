@@ -0,0 +1,24 @@
+package constants
+
+// Roles
+const (
+	RoleAdmin    = "admin"
+	RoleSupport  = "support"
+	RoleFinance  = "finance"
+	RoleSeller   = "seller"
+	RoleReseller = "reseller"
+)
+
+// DefaultRole is granted implicitly to every user, since any user may open a shop
+const DefaultRole = RoleSeller
+
+// Permissions
+const (
+	PermCategoryManage = "category:manage"
+	PermRoleManage     = "role:manage"
+	PermPayoutManage   = "payout:manage"
+	PermResellerManage = "reseller:manage"
+	PermResellerPrice  = "price:reseller"
+	PermMediaPrivate   = "media:private"
+	PermShopVerify     = "shop:verify"
+)
@@ -0,0 +1,19 @@
+package constants
+
+// Reseller application statuses
+const (
+	ResellerPending  = "pending"
+	ResellerApproved = "approved"
+	ResellerRejected = "rejected"
+	ResellerRevoked  = "revoked"
+)
+
+// Price tiers recorded on each transaction line
+const (
+	PriceTierKonsumen = "konsumen"
+	PriceTierReseller = "reseller"
+)
+
+// Minimum quantity per order line for the reseller price, unless a product
+// sets its own
+const DefaultMinKuantitasReseller = 1
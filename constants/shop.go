@@ -0,0 +1,28 @@
+package constants
+
+// Shop handles are the slugs that identify a shop's public storefront, at
+// /toko/by-handle/<handle>. They are stored in url_toko.
+const (
+	ShopHandleMinLength = 3
+	ShopHandleMaxLength = 30
+)
+
+// ReservedShopHandles cannot be taken by a shop, because they clash with
+// routes or could pass for the marketplace itself
+var ReservedShopHandles = []string{
+	"admin", "api", "auth", "by-handle", "cart", "checkout", "help", "login",
+	"logout", "marketplace", "media", "my", "official", "payment", "product",
+	"register", "search", "seller", "settings", "shop", "support", "toko",
+	"trx", "user",
+}
+
+// Shop images, stored under ShopMediaPrefix. Logos are cropped to a square
+// and banners to a 3:1 strip.
+const (
+	ShopMediaPrefix  = "shops/"
+	ShopImageLogo    = "logo"
+	ShopImageBanner  = "banner"
+	ShopLogoSize     = 400
+	ShopBannerWidth  = 1500
+	ShopBannerHeight = 500
+)
@@ -0,0 +1,30 @@
+package constants
+
+import "time"
+
+// Shop member roles
+const (
+	ShopRoleOwner   = "owner"
+	ShopRoleManager = "manager"
+	ShopRoleStaff   = "staff"
+)
+
+// Shop-scoped permissions, granted through shop membership
+const (
+	ShopPermProductManage = "product:manage"
+	ShopPermOrderRead     = "order:read"
+	ShopPermProfileUpdate = "shop:update"
+	ShopPermMemberManage  = "member:manage"
+	ShopPermVerification  = "verification:submit"
+	ShopPermFinance       = "finance:manage"
+)
+
+// Shop invitation statuses
+const (
+	ShopInvitationPending  = "pending"
+	ShopInvitationAccepted = "accepted"
+	ShopInvitationDeclined = "declined"
+	ShopInvitationRevoked  = "revoked"
+)
+
+const ShopInvitationTTL = 7 * 24 * time.Hour
@@ -0,0 +1,24 @@
+package constants
+
+// Shop verification statuses, kept on both the shop and each verification
+// request. Shops start unverified.
+const (
+	ShopUnverified           = "unverified"
+	ShopVerificationPending  = "pending"
+	ShopVerified             = "verified"
+	ShopVerificationRejected = "rejected"
+)
+
+// Documents that can be attached to a verification request, as private
+// files of category PrivateMediaKYC. An identity card is always required.
+const (
+	KYCDocumentKTP    = "ktp"
+	KYCDocumentSelfie = "selfie"
+	KYCDocumentNIB    = "nib"
+	KYCDocumentNPWP   = "npwp"
+	KYCDocumentOther  = "lainnya"
+)
+
+// DefaultUnverifiedShopMaxProducts is how many products an unverified shop
+// may have unless UNVERIFIED_SHOP_MAX_PRODUCTS says otherwise
+const DefaultUnverifiedShopMaxProducts = 10
@@ -27,3 +27,24 @@ type ResetPasswordRequest struct {
 	KataSandi   string `json:"kata_sandi" validate:"required,min=6"`
 	ConfirmPass string `json:"confirm_password" validate:"required,eqfield=KataSandi"`
 }
+
+type RequestLoginOTPRequest struct {
+	NoTelp string `json:"no_telp" validate:"required"`
+}
+
+type LoginOTPRequest struct {
+	NoTelp string `json:"no_telp" validate:"required"`
+	Kode   string `json:"kode" validate:"required,numeric,len=6"`
+}
+
+type OAuthExchangeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type ConfirmOAuthLinkRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,25 @@
+package request
+
+type ShopLedgerQuery struct {
+	// Before continues a listing below the given entry ID
+	Before int `query:"before" validate:"omitempty,min=1"`
+	Limit  int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type SaveBankAccountRequest struct {
+	NamaBank      string `json:"nama_bank" validate:"required,max=100"`
+	NomorRekening string `json:"nomor_rekening" validate:"required,numeric,min=5,max=32"`
+	NamaPemilik   string `json:"nama_pemilik" validate:"required,max=255"`
+}
+
+type RequestPayoutRequest struct {
+	Jumlah int64 `json:"jumlah" validate:"required,min=1"`
+}
+
+type ReviewPayoutRequest struct {
+	Catatan string `json:"catatan" validate:"max=2000"`
+}
+
+type PayoutQuery struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending approved rejected"`
+}
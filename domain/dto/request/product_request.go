@@ -1,20 +1,68 @@
 package request
 
+import "github.com/rdsarjito/marketplace-backend/utils"
+
 type CreateProductRequest struct {
-	NamaProduk    string `json:"nama_produk" validate:"required"`
-	HargaReseller string `json:"harga_reseller" validate:"required"`
-	HargaKonsumen string `json:"harga_konsumen" validate:"required"`
-	Stok          int    `json:"stok" validate:"required,min=0"`
-	Deskripsi     string `json:"deskripsi" validate:"required"`
-	IDToko        int    `json:"id_toko" validate:"required"`
-	IDCategory    int    `json:"id_category" validate:"required"`
+	NamaProduk           string       `json:"nama_produk" validate:"required"`
+	HargaReseller        utils.Rupiah `json:"harga_reseller" validate:"required,gt=0"`
+	HargaKonsumen        utils.Rupiah `json:"harga_konsumen" validate:"required,gt=0"`
+	MinKuantitasReseller int          `json:"min_kuantitas_reseller" validate:"omitempty,min=1"`
+	Stok                 int          `json:"stok" validate:"required,min=0"`
+	Deskripsi            string       `json:"deskripsi" validate:"required"`
+	IDToko               int          `json:"id_toko" validate:"required"`
+	IDCategory           int          `json:"id_category" validate:"required"`
+
+	Variants []ProductVariantRequest `json:"variants" validate:"omitempty,dive"`
 }
 
 type UpdateProductRequest struct {
-	NamaProduk    string `json:"nama_produk" validate:"required"`
-	HargaReseller string `json:"harga_reseller" validate:"required"`
-	HargaKonsumen string `json:"harga_konsumen" validate:"required"`
-	Stok          int    `json:"stok" validate:"required,min=0"`
-	Deskripsi     string `json:"deskripsi" validate:"required"`
-	IDCategory    int    `json:"id_category" validate:"required"`
+	NamaProduk           string       `json:"nama_produk" validate:"required"`
+	HargaReseller        utils.Rupiah `json:"harga_reseller" validate:"required,gt=0"`
+	HargaKonsumen        utils.Rupiah `json:"harga_konsumen" validate:"required,gt=0"`
+	MinKuantitasReseller int          `json:"min_kuantitas_reseller" validate:"omitempty,min=1"`
+	Stok                 int          `json:"stok" validate:"required,min=0"`
+	Deskripsi            string       `json:"deskripsi" validate:"required"`
+	IDCategory           int          `json:"id_category" validate:"required"`
+}
+
+// ProductVariantRequest describes one variant. Atribut maps option types to
+// values, e.g. {"Ukuran": "XL", "Warna": "Merah"}; every variant of a product
+// must use the same option types.
+type ProductVariantRequest struct {
+	SKU           string            `json:"sku" validate:"required,max=64"`
+	Atribut       map[string]string `json:"atribut" validate:"required,min=1,max=3,dive,keys,required,max=50,endkeys,required,max=50"`
+	HargaReseller utils.Rupiah      `json:"harga_reseller" validate:"required,gt=0"`
+	HargaKonsumen utils.Rupiah      `json:"harga_konsumen" validate:"required,gt=0"`
+	Stok          int               `json:"stok" validate:"min=0"`
+	IDFoto        *int              `json:"id_foto"`
+}
+
+// ReorderPhotosRequest lists every photo of a product in its new order.
+type ReorderPhotosRequest struct {
+	PhotoIDs []int `json:"photo_ids" validate:"required,min=1,dive,min=1"`
+}
+
+type ProductListQuery struct {
+	Q          string `query:"q" validate:"max=100"`
+	IDCategory int    `query:"id_category" validate:"omitempty,min=1"`
+	IDToko     int    `query:"id_toko" validate:"omitempty,min=1"`
+	MinHarga   *int64 `query:"min_harga" validate:"omitempty,min=0"`
+	MaxHarga   *int64 `query:"max_harga" validate:"omitempty,min=0"`
+	InStock    bool   `query:"in_stock"`
+	Sort       string `query:"sort" validate:"omitempty,oneof=relevance newest price_asc price_desc best_selling"`
+	Cursor     string `query:"cursor"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type ProductExportQuery struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv xlsx"`
+}
+
+// CreatePhotoUploadRequest describes a photo the client is about to upload
+// directly to media storage. The file must be sent with exactly this type
+// and size.
+type CreatePhotoUploadRequest struct {
+	NamaFile    string `json:"nama_file" validate:"required,max=255"`
+	ContentType string `json:"content_type" validate:"required"`
+	Size        int64  `json:"size" validate:"required,gt=0"`
 }
@@ -0,0 +1,14 @@
+package request
+
+type ApplyResellerRequest struct {
+	NamaUsaha string `json:"nama_usaha" validate:"required,max=255"`
+	Alasan    string `json:"alasan" validate:"required,max=2000"`
+}
+
+type ReviewResellerRequest struct {
+	Catatan string `json:"catatan" validate:"max=2000"`
+}
+
+type ResellerApplicationQuery struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending approved rejected revoked"`
+}
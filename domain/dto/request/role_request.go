@@ -0,0 +1,5 @@
+package request
+
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" validate:"required,dive,required"`
+}
@@ -1,11 +1,55 @@
 package request
 
+// CreateShopRequest opens a shop. URLToko is the shop's handle; when left
+// out, one is made from the name.
 type CreateShopRequest struct {
-	NamaToko string `json:"nama_toko" validate:"required"`
-	URLToko  string `json:"url_toko" validate:"required"`
+	NamaToko string `json:"nama_toko" validate:"required,max=255"`
+	URLToko  string `json:"url_toko" validate:"omitempty,max=30"`
 }
 
 type UpdateShopRequest struct {
-	NamaToko string `json:"nama_toko" validate:"required"`
-	URLToko  string `json:"url_toko" validate:"required"`
+	NamaToko  string `json:"nama_toko" validate:"required"`
+	URLToko   string `json:"url_toko" validate:"required"`
+	Deskripsi string `json:"deskripsi" validate:"max=2000"`
+}
+
+// ShopAddressRequest sets the address a shop ships its orders from.
+type ShopAddressRequest struct {
+	DetailAlamat string `json:"detail_alamat" validate:"required,max=500"`
+	IDProvinsi   string `json:"id_provinsi" validate:"required,numeric"`
+	IDKota       string `json:"id_kota" validate:"required,numeric"`
+	KodePos      string `json:"kode_pos" validate:"omitempty,numeric,len=5"`
+}
+
+// ShopOpeningHoursRequest replaces a shop's opening hours. Days that are
+// not listed are closed.
+type ShopOpeningHoursRequest struct {
+	JamOperasional []ShopOpeningHourRequest `json:"jam_operasional" validate:"max=7,dive"`
+}
+
+type ShopOpeningHourRequest struct {
+	Hari  int    `json:"hari" validate:"min=0,max=6"`
+	Buka  string `json:"buka" validate:"required,datetime=15:04"`
+	Tutup string `json:"tutup" validate:"required,datetime=15:04"`
+}
+
+// ShopVacationRequest turns vacation mode on or off. BukaKembali is the
+// date, YYYY-MM-DD, on which the shop opens again by itself.
+type ShopVacationRequest struct {
+	Libur       bool   `json:"libur"`
+	PesanLibur  string `json:"pesan_libur" validate:"max=255"`
+	BukaKembali string `json:"buka_kembali" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type InviteShopMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=manager staff"`
+}
+
+type UpdateShopMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=manager staff"`
+}
+
+type RespondShopInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
 }
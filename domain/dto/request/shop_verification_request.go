@@ -0,0 +1,23 @@
+package request
+
+// SubmitShopVerificationRequest asks for a shop to be verified. Documents
+// are private files uploaded beforehand with kategori kyc.
+type SubmitShopVerificationRequest struct {
+	NamaPemilik string                            `json:"nama_pemilik" validate:"required,max=255"`
+	NIK         string                            `json:"nik" validate:"required,numeric,len=16"`
+	NPWP        string                            `json:"npwp" validate:"omitempty,numeric,min=15,max=16"`
+	Dokumen     []ShopVerificationDocumentRequest `json:"dokumen" validate:"required,min=1,max=10,dive"`
+}
+
+type ShopVerificationDocumentRequest struct {
+	Jenis   string `json:"jenis" validate:"required,oneof=ktp selfie nib npwp lainnya"`
+	IDMedia int    `json:"id_media" validate:"required,min=1"`
+}
+
+type ReviewShopVerificationRequest struct {
+	Catatan string `json:"catatan" validate:"max=2000"`
+}
+
+type ShopVerificationQuery struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending verified rejected"`
+}
@@ -0,0 +1,20 @@
+package request
+
+type EnableTwoFactorRequest struct {
+	Kode string `json:"kode" validate:"required,numeric,len=6"`
+}
+
+type DisableTwoFactorRequest struct {
+	KataSandi string `json:"kata_sandi" validate:"required"`
+	Kode      string `json:"kode" validate:"required,numeric,len=6"`
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	Kode string `json:"kode" validate:"required,numeric,len=6"`
+}
+
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Kode           string `json:"kode" validate:"required_without=RecoveryCode,omitempty,numeric,len=6"`
+	RecoveryCode   string `json:"recovery_code" validate:"required_without=Kode"`
+}
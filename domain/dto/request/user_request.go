@@ -11,3 +11,7 @@ type UpdateProfileRequest struct {
 	IDProvinsi   string `json:"id_provinsi" validate:"omitempty"`
 	IDKota       string `json:"id_kota" validate:"omitempty"`
 }
+
+type VerifyPhoneRequest struct {
+	Kode string `json:"kode" validate:"required,numeric,len=6"`
+}
@@ -3,21 +3,29 @@ package response
 type AuthResponse struct {
 	Token string      `json:"token"`
 	User  UserProfile `json:"user"`
+
+	// Set instead of Token when the account requires a second login step
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	// Set for admins who must enroll in 2FA before admin access is granted
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
 }
 
 type UserProfile struct {
-	ID            int    `json:"id"`
-	Nama          string `json:"nama"`
-	NoTelp        string `json:"no_telp"`
-	TanggalLahir  string `json:"tanggal_lahir"`
-	JenisKelamin  string `json:"jenis_kelamin"`
-	Tentang       string `json:"tentang"`
-	Pekerjaan     string `json:"pekerjaan"`
-	Email         string `json:"email"`
-	IDProvinsi    string `json:"id_provinsi"`
-	IDKota        string `json:"id_kota"`
-	PhotoURL      string `json:"photo_url,omitempty"`
-	IsAdmin       bool   `json:"is_admin"`
+	ID             int    `json:"id"`
+	Nama           string `json:"nama"`
+	NoTelp         string `json:"no_telp"`
+	NoTelpVerified bool   `json:"no_telp_verified"`
+	TanggalLahir   string `json:"tanggal_lahir"`
+	JenisKelamin   string `json:"jenis_kelamin"`
+	Tentang        string `json:"tentang"`
+	Pekerjaan      string `json:"pekerjaan"`
+	Email          string `json:"email"`
+	EmailVerified  bool   `json:"email_verified"`
+	IDProvinsi     string `json:"id_provinsi"`
+	IDKota         string `json:"id_kota"`
+	PhotoURL       string `json:"photo_url,omitempty"`
+	IsAdmin        bool   `json:"is_admin"`
 }
 
 type ForgotPasswordResponse struct {
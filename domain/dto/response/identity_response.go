@@ -0,0 +1,11 @@
+package response
+
+type IdentityResponse struct {
+	Provider  string `json:"provider"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+type OAuthLinkResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
@@ -0,0 +1,101 @@
+package response
+
+// ShopBalanceResponse shows what the platform owes a shop.
+type ShopBalanceResponse struct {
+	IDToko int `json:"id_toko"`
+	// SaldoTertahan is held until the shop's orders are completed
+	SaldoTertahan int64 `json:"saldo_tertahan"`
+	// SaldoTersedia can be paid out
+	SaldoTersedia int64 `json:"saldo_tersedia"`
+	// SaldoDiproses is reserved for payouts awaiting approval
+	SaldoDiproses  int64 `json:"saldo_diproses"`
+	TotalDicairkan int64 `json:"total_dicairkan"`
+	// BisaDicairkan is false when the shop must be verified first
+	BisaDicairkan bool `json:"bisa_dicairkan"`
+}
+
+type LedgerEntryResponse struct {
+	ID          int    `json:"id"`
+	IDJurnal    int    `json:"id_jurnal"`
+	Jenis       string `json:"jenis"`
+	Keterangan  string `json:"keterangan"`
+	Akun        string `json:"akun"`
+	IDTRX       *int   `json:"id_trx,omitempty"`
+	IDDetailTRX *int   `json:"id_detail_trx,omitempty"`
+	IDPenarikan *int   `json:"id_penarikan,omitempty"`
+	Debit       int64  `json:"debit"`
+	Kredit      int64  `json:"kredit"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type ShopLedgerResponse struct {
+	Entri []LedgerEntryResponse `json:"entri"`
+	// NextBefore is passed as before to get the next page
+	NextBefore int  `json:"next_before,omitempty"`
+	HasMore    bool `json:"has_more"`
+}
+
+type BankAccountResponse struct {
+	IDToko        int    `json:"id_toko"`
+	NamaBank      string `json:"nama_bank"`
+	NomorRekening string `json:"nomor_rekening"`
+	NamaPemilik   string `json:"nama_pemilik"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type PayoutResponse struct {
+	ID            int    `json:"id"`
+	IDToko        int    `json:"id_toko"`
+	NamaToko      string `json:"nama_toko"`
+	IDUser        int    `json:"id_user"`
+	Jumlah        int64  `json:"jumlah"`
+	NamaBank      string `json:"nama_bank"`
+	NomorRekening string `json:"nomor_rekening"`
+	NamaPemilik   string `json:"nama_pemilik"`
+	Status        string `json:"status"`
+	Catatan       string `json:"catatan,omitempty"`
+	ReviewedBy    *int   `json:"reviewed_by,omitempty"`
+	ReviewedAt    string `json:"reviewed_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// LedgerReconciliationResponse compares the ledger with the orders and
+// checks that it balances.
+type LedgerReconciliationResponse struct {
+	// TotalPenjualan sums the orders paid since the ledger was introduced,
+	// and TotalDicatat what their sale journals recorded
+	TotalPenjualan int64 `json:"total_penjualan"`
+	TotalDicatat   int64 `json:"total_dicatat"`
+	TotalKomisi    int64 `json:"total_komisi"`
+	TotalDicairkan int64 `json:"total_dicairkan"`
+	// SaldoKas is the money that should be in the platform's account, and
+	// KewajibanToko what of it is owed to shops
+	SaldoKas      int64 `json:"saldo_kas"`
+	KewajibanToko int64 `json:"kewajiban_toko"`
+	// Seimbang is true when every journal balances and the cash equals the
+	// shops' balances plus the commission
+	Seimbang            bool                        `json:"seimbang"`
+	JurnalTidakSeimbang []int                       `json:"jurnal_tidak_seimbang"`
+	TransaksiTidakCocok []LedgerTRXMismatchResponse `json:"transaksi_tidak_cocok"`
+	Toko                []ShopLedgerSummaryResponse `json:"toko"`
+}
+
+type LedgerTRXMismatchResponse struct {
+	IDTRX         int    `json:"id_trx"`
+	KodeInvoice   string `json:"kode_invoice"`
+	PaymentStatus string `json:"payment_status"`
+	HargaTotal    int64  `json:"harga_total"`
+	Dicatat       int64  `json:"dicatat"`
+}
+
+type ShopLedgerSummaryResponse struct {
+	IDToko         int    `json:"id_toko"`
+	NamaToko       string `json:"nama_toko"`
+	Penjualan      int64  `json:"penjualan"`
+	Komisi         int64  `json:"komisi"`
+	SaldoTertahan  int64  `json:"saldo_tertahan"`
+	SaldoTersedia  int64  `json:"saldo_tersedia"`
+	SaldoDiproses  int64  `json:"saldo_diproses"`
+	TotalDicairkan int64  `json:"total_dicairkan"`
+}
@@ -0,0 +1,12 @@
+package response
+
+// PhotoUploadResponse tells the client where to upload a photo. The file is
+// sent as the body of a Method request to UploadURL with Headers set.
+type PhotoUploadResponse struct {
+	ID        int               `json:"id"`
+	IDProduk  int               `json:"id_produk"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt string            `json:"expires_at"`
+}
@@ -0,0 +1,15 @@
+package response
+
+// PrivateMediaResponse describes a private file. URL is a signed download
+// link that stops working at URLExpiresAt.
+type PrivateMediaResponse struct {
+	ID           int    `json:"id"`
+	IDUser       int    `json:"id_user"`
+	Kategori     string `json:"kategori"`
+	NamaFile     string `json:"nama_file"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	URL          string `json:"url"`
+	URLExpiresAt string `json:"url_expires_at"`
+	CreatedAt    string `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package response
+
+type ProductImportResponse struct {
+	ID         int    `json:"id"`
+	IDToko     int    `json:"id_toko"`
+	IDUser     int    `json:"id_user"`
+	NamaFile   string `json:"nama_file"`
+	Format     string `json:"format"`
+	DryRun     bool   `json:"dry_run"`
+	Status     string `json:"status"`
+	TotalRows  int    `json:"total_rows"`
+	Processed  int    `json:"processed"`
+	Berhasil   int    `json:"berhasil"`
+	Gagal      int    `json:"gagal"`
+	Pesan      string `json:"pesan,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
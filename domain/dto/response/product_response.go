@@ -4,8 +4,9 @@ type ProductResponse struct {
 	ID             int                `json:"id"`
 	NamaProduk     string             `json:"nama_produk"`
 	Slug           string             `json:"slug"`
-	HargaReseller  string             `json:"harga_reseller"`
-	HargaKonsumen  string             `json:"harga_konsumen"`
+	HargaReseller  int64              `json:"harga_reseller"`
+	HargaKonsumen  int64              `json:"harga_konsumen"`
+	MinKuantitasReseller int          `json:"min_kuantitas_reseller"`
 	Stok           int                `json:"stok"`
 	Deskripsi      string             `json:"deskripsi"`
 	CreatedAt      string             `json:"created_at"`
@@ -15,12 +16,65 @@ type ProductResponse struct {
 	Toko           ShopResponse       `json:"toko"`
 	Category       CategoryResponse   `json:"category"`
 	PhotosProduct  []PhotoProductResponse `json:"photos_product"`
+	Options        []ProductOptionResponse  `json:"options"`
+	Variants       []ProductVariantResponse `json:"variants"`
+	Highlights     map[string][]string    `json:"highlights,omitempty"`
+}
+
+type ProductOptionResponse struct {
+	ID    int      `json:"id"`
+	Nama  string   `json:"nama"`
+	Nilai []string `json:"nilai"`
+}
+
+type ProductVariantResponse struct {
+	ID            int               `json:"id"`
+	IDProduk      int               `json:"id_produk"`
+	SKU           string            `json:"sku"`
+	Atribut       map[string]string `json:"atribut"`
+	HargaReseller int64             `json:"harga_reseller"`
+	HargaKonsumen int64             `json:"harga_konsumen"`
+	Stok          int               `json:"stok"`
+	IDFoto        *int              `json:"id_foto,omitempty"`
+	FotoURL       string            `json:"foto_url,omitempty"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
 }
 
 type PhotoProductResponse struct {
-	ID        int    `json:"id"`
-	IDProduk  int    `json:"id_produk"`
-	URL       string `json:"url"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID         int    `json:"id"`
+	IDProduk   int    `json:"id_produk"`
+	URL        string `json:"url"`
+	Posisi     int    `json:"posisi"`
+	IsPrimary  bool   `json:"is_primary"`
+	// Renditions maps each resized version (thumbnail, medium, large,
+	// webp) to its URL; URL is the original
+	Renditions map[string]string `json:"renditions,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type ProductListResponse struct {
+	Products   []ProductResponse `json:"products"`
+	Total      int64             `json:"total"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+}
+
+// LogProductResponse is one snapshot in a product's change history.
+type LogProductResponse struct {
+	ID                   int    `json:"id"`
+	IDProduk             int    `json:"id_produk"`
+	NamaProduk           string `json:"nama_produk"`
+	Slug                 string `json:"slug"`
+	HargaReseller        int64  `json:"harga_reseller"`
+	HargaKonsumen        int64  `json:"harga_konsumen"`
+	MinKuantitasReseller int    `json:"min_kuantitas_reseller"`
+	Stok                 int    `json:"stok"`
+	Deskripsi            string `json:"deskripsi"`
+	IDToko               int    `json:"id_toko"`
+	IDCategory           int    `json:"id_category"`
+	IDUser               *int   `json:"id_user,omitempty"`
+	NamaUser             string `json:"nama_user,omitempty"`
+	CreatedAt            string `json:"created_at"`
 }
@@ -0,0 +1,16 @@
+package response
+
+type ResellerApplicationResponse struct {
+	ID         int    `json:"id"`
+	IDUser     int    `json:"id_user"`
+	Nama       string `json:"nama"`
+	Email      string `json:"email"`
+	NamaUsaha  string `json:"nama_usaha"`
+	Alasan     string `json:"alasan"`
+	Status     string `json:"status"`
+	Catatan    string `json:"catatan,omitempty"`
+	ReviewedBy *int   `json:"reviewed_by,omitempty"`
+	ReviewedAt string `json:"reviewed_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package response
+
+type RoleResponse struct {
+	ID                int      `json:"id"`
+	Name              string   `json:"name"`
+	Description       string   `json:"description"`
+	RequiresTwoFactor bool     `json:"requires_two_factor"`
+	Permissions       []string `json:"permissions"`
+}
+
+type UserAccessResponse struct {
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	// Set when some roles are withheld until the session completes 2FA
+	TwoFactorRequired bool `json:"two_factor_required,omitempty"`
+}
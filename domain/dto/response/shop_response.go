@@ -1,10 +1,65 @@
 package response
 
 type ShopResponse struct {
+	ID             int                       `json:"id"`
+	NamaToko       string                    `json:"nama_toko"`
+	URLToko        string                    `json:"url_toko"`
+	Deskripsi      string                    `json:"deskripsi"`
+	LogoURL        string                    `json:"logo_url,omitempty"`
+	BannerURL      string                    `json:"banner_url,omitempty"`
+	Alamat         *ShopAddressResponse      `json:"alamat,omitempty"`
+	JamOperasional []ShopOpeningHourResponse `json:"jam_operasional,omitempty"`
+	// Libur is whether the shop is on vacation now
+	Libur       bool   `json:"libur"`
+	PesanLibur  string `json:"pesan_libur,omitempty"`
+	BukaKembali string `json:"buka_kembali,omitempty"`
+	// StatusVerifikasi is unverified, pending, verified or rejected
+	StatusVerifikasi string `json:"status_verifikasi"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+	IDUser           int    `json:"id_user"`
+}
+
+type ShopAddressResponse struct {
+	DetailAlamat string `json:"detail_alamat"`
+	IDProvinsi   string `json:"id_provinsi"`
+	IDKota       string `json:"id_kota"`
+	KodePos      string `json:"kode_pos,omitempty"`
+}
+
+type ShopOpeningHourResponse struct {
+	Hari  int    `json:"hari"`
+	Buka  string `json:"buka"`
+	Tutup string `json:"tutup"`
+}
+
+// StorefrontResponse is a shop's public page: the shop and one page of its
+// products.
+type StorefrontResponse struct {
+	Toko ShopResponse `json:"toko"`
+	ProductListResponse
+}
+
+type ShopMemberResponse struct {
+	IDUser   int    `json:"id_user"`
+	Nama     string `json:"nama"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+}
+
+type ShopMembershipResponse struct {
+	Shop        ShopResponse `json:"shop"`
+	Role        string       `json:"role"`
+	Permissions []string     `json:"permissions"`
+}
+
+type ShopInvitationResponse struct {
 	ID        int    `json:"id"`
-	NamaToko  string `json:"nama_toko"`
-	URLToko   string `json:"url_toko"`
+	IDToko    int    `json:"id_toko"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`
+	ExpiresAt string `json:"expires_at"`
 	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
-	IDUser    int    `json:"id_user"`
 }
@@ -0,0 +1,26 @@
+package response
+
+type ShopVerificationResponse struct {
+	ID          int                                `json:"id"`
+	IDToko      int                                `json:"id_toko"`
+	NamaToko    string                             `json:"nama_toko"`
+	IDUser      int                                `json:"id_user"`
+	Email       string                             `json:"email"`
+	NamaPemilik string                             `json:"nama_pemilik"`
+	NIK         string                             `json:"nik"`
+	NPWP        string                             `json:"npwp,omitempty"`
+	Dokumen     []ShopVerificationDocumentResponse `json:"dokumen"`
+	Status      string                             `json:"status"`
+	Catatan     string                             `json:"catatan,omitempty"`
+	ReviewedBy  *int                               `json:"reviewed_by,omitempty"`
+	ReviewedAt  string                             `json:"reviewed_at,omitempty"`
+	CreatedAt   string                             `json:"created_at"`
+	UpdatedAt   string                             `json:"updated_at"`
+}
+
+// ShopVerificationDocumentResponse is one attached document. The file's URL
+// is signed and expires like any private file's.
+type ShopVerificationDocumentResponse struct {
+	Jenis string                `json:"jenis"`
+	Media *PrivateMediaResponse `json:"media"`
+}
@@ -2,7 +2,7 @@ package response
 
 type TRXResponse struct {
 	ID               int                 `json:"id"`
-	HargaTotal       int                 `json:"harga_total"`
+	HargaTotal       int64               `json:"harga_total"`
 	KodeInvoice      string              `json:"kode_invoice"`
 	MethodBayar      string              `json:"method_bayar"`
 	PaymentStatus    string              `json:"payment_status,omitempty"`
@@ -11,6 +11,8 @@ type TRXResponse struct {
 	PaymentVANumbers []PaymentVANumber   `json:"payment_va_numbers,omitempty"`
 	PaymentActions   []PaymentAction     `json:"payment_actions,omitempty"`
 	PaymentQRString  string              `json:"payment_qr_string,omitempty"`
+	DibayarAt        string              `json:"dibayar_at,omitempty"`
+	SelesaiAt        string              `json:"selesai_at,omitempty"`
 	CreatedAt        string              `json:"created_at"`
 	UpdatedAt        string              `json:"updated_at"`
 	IDUser           int                 `json:"id_user"`
@@ -20,6 +22,26 @@ type TRXResponse struct {
 	DetailTRX        []DetailTRXResponse `json:"detail_trx"`
 }
 
+// ShopTRXResponse is an order as seen by one of its shops: only the shop's
+// lines, their subtotal and where to ship them.
+type ShopTRXResponse struct {
+	ID            int                 `json:"id"`
+	KodeInvoice   string              `json:"kode_invoice"`
+	PaymentStatus string              `json:"payment_status,omitempty"`
+	DibayarAt     string              `json:"dibayar_at,omitempty"`
+	SelesaiAt     string              `json:"selesai_at,omitempty"`
+	CreatedAt     string              `json:"created_at"`
+	Subtotal      int64               `json:"subtotal"`
+	Pengiriman    ShopTRXShipping     `json:"pengiriman"`
+	DetailTRX     []DetailTRXResponse `json:"detail_trx"`
+}
+
+type ShopTRXShipping struct {
+	NamaPenerima string `json:"nama_penerima"`
+	NoTelp       string `json:"no_telp"`
+	DetailAlamat string `json:"detail_alamat"`
+}
+
 type PaymentVANumber struct {
 	Bank     string `json:"bank"`
 	VANumber string `json:"va_number"`
@@ -32,14 +54,19 @@ type PaymentAction struct {
 }
 
 type DetailTRXResponse struct {
-	ID         int             `json:"id"`
-	IDTRX      int             `json:"id_trx"`
-	IDProduk   int             `json:"id_produk"`
-	IDToko     int             `json:"id_toko"`
-	Kuantitas  int             `json:"kuantitas"`
-	HargaTotal int             `json:"harga_total"`
-	CreatedAt  string          `json:"created_at"`
-	UpdatedAt  string          `json:"updated_at"`
-	Product    ProductResponse `json:"product"`
-	Shop       ShopResponse    `json:"shop"`
+	ID          int                     `json:"id"`
+	IDTRX       int                     `json:"id_trx"`
+	IDProduk    int                     `json:"id_produk"`
+	IDVariant   *int                    `json:"id_variant,omitempty"`
+	IDLogProduk *int                    `json:"id_log_produk,omitempty"`
+	IDToko      int                     `json:"id_toko"`
+	Kuantitas   int                     `json:"kuantitas"`
+	HargaSatuan int64                   `json:"harga_satuan"`
+	TierHarga   string                  `json:"tier_harga"`
+	HargaTotal  int64                   `json:"harga_total"`
+	CreatedAt   string                  `json:"created_at"`
+	UpdatedAt   string                  `json:"updated_at"`
+	Product     ProductResponse         `json:"product"`
+	Variant     *ProductVariantResponse `json:"variant,omitempty"`
+	Shop        ShopResponse            `json:"shop"`
 }
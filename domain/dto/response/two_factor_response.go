@@ -0,0 +1,18 @@
+package response
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"` // PNG data URI of OTPAuthURI
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool   `json:"enabled"`
+	Required               bool   `json:"required"`
+	EnabledAt              string `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64  `json:"recovery_codes_remaining"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
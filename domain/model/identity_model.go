@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// UserIdentity links an external identity provider account to a user.
+type UserIdentity struct {
+	ID        int       `gorm:"type:int;primaryKey;autoIncrement"`
+	IDUser    int       `gorm:"type:int;not null;index"`
+	Provider  string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_identity_provider_subject"`
+	Email     string    `gorm:"type:varchar(255)"`
+	CreatedAt time.Time `gorm:"type:timestamp"`
+	UpdatedAt time.Time `gorm:"type:timestamp"`
+
+	User User `gorm:"foreignKey:IDUser;references:ID"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OAuthExchangeCode is a short-lived, single-use code handed to the frontend
+// after an OAuth callback, so the JWT never appears in a redirect URL.
+type OAuthExchangeCode struct {
+	ID        int        `gorm:"type:int;primaryKey;autoIncrement"`
+	CodeHash  string     `gorm:"type:varchar(128);not null;uniqueIndex"`
+	IDUser    int        `gorm:"type:int;not null"`
+	ExpiresAt time.Time  `gorm:"type:timestamp;not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt time.Time  `gorm:"type:timestamp"`
+}
+
+func (OAuthExchangeCode) TableName() string {
+	return "oauth_exchange_codes"
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// LedgerJournal is one balanced posting to the ledger: its entries' debits
+// add up to their credits. Journals are never changed once written.
+type LedgerJournal struct {
+	ID    int    `gorm:"type:int;primaryKey;autoIncrement"`
+	Jenis string `gorm:"type:varchar(32);not null;index"`
+	// Kunci makes postings for an order idempotent, e.g. sale:trx:12; it
+	// is nil for payout journals, which follow the payout's status
+	Kunci       *string   `gorm:"type:varchar(100);uniqueIndex:idx_ledger_jurnal_kunci"`
+	IDTRX       *int      `gorm:"type:int;index"`
+	IDPenarikan *int      `gorm:"type:int;index"`
+	Keterangan  string    `gorm:"type:varchar(255)"`
+	CreatedAt   time.Time `gorm:"type:timestamp;not null;default:current_timestamp"`
+
+	Entri []LedgerEntry `gorm:"foreignKey:IDJurnal;references:ID"`
+}
+
+// LedgerEntry debits or credits one account. Entries about a shop's money,
+// including the platform's side of them, carry the shop's ID.
+type LedgerEntry struct {
+	ID          int       `gorm:"type:int;primaryKey;autoIncrement"`
+	IDJurnal    int       `gorm:"type:int;not null;index"`
+	Akun        string    `gorm:"type:varchar(32);not null;index:idx_ledger_entri_toko_akun,priority:2"`
+	IDToko      *int      `gorm:"type:int;index:idx_ledger_entri_toko_akun,priority:1"`
+	IDDetailTRX *int      `gorm:"type:int;index"`
+	Debit       int64     `gorm:"type:bigint;not null;default:0"`
+	Kredit      int64     `gorm:"type:bigint;not null;default:0"`
+	CreatedAt   time.Time `gorm:"type:timestamp;not null;default:current_timestamp"`
+
+	Jurnal LedgerJournal `gorm:"foreignKey:IDJurnal;references:ID"`
+}
+
+func (LedgerJournal) TableName() string {
+	return "ledger_jurnal"
+}
+
+func (LedgerEntry) TableName() string {
+	return "ledger_entri"
+}
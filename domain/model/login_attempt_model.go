@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// LoginThrottle counts recent failures for a throttle key, e.g. an account
+// email or a client IP, and holds any backoff or lockout currently in force.
+type LoginThrottle struct {
+	ID            int        `gorm:"type:int;primaryKey;autoIncrement"`
+	Key           string     `gorm:"column:throttle_key;type:varchar(320);not null;uniqueIndex"`
+	Failures      int        `gorm:"type:int;not null;default:0"`
+	LastFailureAt time.Time  `gorm:"type:timestamp;not null"`
+	LockedUntil   *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt     time.Time  `gorm:"type:timestamp"`
+	UpdatedAt     time.Time  `gorm:"type:timestamp"`
+}
+
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
+
+// FailedLogin is an audit record of a rejected login attempt.
+type FailedLogin struct {
+	ID        int       `gorm:"type:int;primaryKey;autoIncrement"`
+	Email     string    `gorm:"type:varchar(255);not null;index"`
+	IPAddress string    `gorm:"type:varchar(64);not null;index"`
+	UserAgent string    `gorm:"type:varchar(512)"`
+	IDUser    *int      `gorm:"type:int;null"`
+	Reason    string    `gorm:"type:varchar(32);not null"`
+	CreatedAt time.Time `gorm:"type:timestamp;index"`
+}
+
+func (FailedLogin) TableName() string {
+	return "failed_logins"
+}
+
+// AccountUnlockToken lets the owner of a locked account lift the lockout from
+// the link in the notification email. Only the hash of the token is stored.
+type AccountUnlockToken struct {
+	ID        int        `gorm:"type:int;primaryKey;autoIncrement"`
+	IDUser    int        `gorm:"type:int;not null;index"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"type:timestamp;not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt time.Time  `gorm:"type:timestamp"`
+}
+
+func (AccountUnlockToken) TableName() string {
+	return "account_unlock_tokens"
+}
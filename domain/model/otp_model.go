@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// PhoneOTP stores a one-time code sent to a phone number. Only the hash of
+// the code is persisted.
+type PhoneOTP struct {
+	ID         int        `gorm:"type:int;primaryKey;autoIncrement"`
+	NoTelp     string     `gorm:"column:notelp;type:varchar(32);not null;index:idx_otp_notelp_purpose"`
+	Purpose    string     `gorm:"type:varchar(32);not null;index:idx_otp_notelp_purpose"`
+	CodeHash   string     `gorm:"type:varchar(128);not null"`
+	Attempts   int        `gorm:"type:int;not null;default:0"`
+	ExpiresAt  time.Time  `gorm:"type:timestamp;not null"`
+	ConsumedAt *time.Time `gorm:"type:timestamp;null"`
+	IDUser     *int       `gorm:"type:int;null"`
+	CreatedAt  time.Time  `gorm:"type:timestamp"`
+	UpdatedAt  time.Time  `gorm:"type:timestamp"`
+}
+
+func (PhoneOTP) TableName() string {
+	return "phone_otps"
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// ShopBankAccount is where a shop's payouts are transferred to.
+type ShopBankAccount struct {
+	ID            int       `gorm:"type:int;primaryKey;autoIncrement"`
+	IDToko        int       `gorm:"type:int;not null;uniqueIndex"`
+	NamaBank      string    `gorm:"type:varchar(100);not null"`
+	NomorRekening string    `gorm:"type:varchar(32);not null"`
+	NamaPemilik   string    `gorm:"type:varchar(255);not null"`
+	CreatedAt     time.Time `gorm:"type:timestamp"`
+	UpdatedAt     time.Time `gorm:"type:timestamp"`
+}
+
+// Payout is a shop's request to withdraw part of its available balance.
+// The bank account is copied from the shop's at request time, so changing
+// the account later does not redirect a pending payout.
+type Payout struct {
+	ID            int        `gorm:"type:int;primaryKey;autoIncrement"`
+	IDToko        int        `gorm:"type:int;not null;index"`
+	IDUser        int        `gorm:"type:int;not null"`
+	Jumlah        int64      `gorm:"type:bigint;not null"`
+	NamaBank      string     `gorm:"type:varchar(100);not null"`
+	NomorRekening string     `gorm:"type:varchar(32);not null"`
+	NamaPemilik   string     `gorm:"type:varchar(255);not null"`
+	Status        string     `gorm:"type:varchar(32);not null;default:'pending';index"`
+	Catatan       string     `gorm:"type:text"`
+	ReviewedBy    *int       `gorm:"type:int"`
+	ReviewedAt    *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt     time.Time  `gorm:"type:timestamp"`
+	UpdatedAt     time.Time  `gorm:"type:timestamp"`
+
+	Toko Shop `gorm:"foreignKey:IDToko;references:ID"`
+}
+
+func (ShopBankAccount) TableName() string {
+	return "toko_rekening"
+}
+
+func (Payout) TableName() string {
+	return "toko_penarikan"
+}
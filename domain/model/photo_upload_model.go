@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// PhotoUpload is a product photo the client uploads straight to media
+// storage with a presigned URL. The object stays in a staging location until
+// the upload is confirmed and it becomes a PhotoProduct; pending uploads are
+// removed once they expire.
+type PhotoUpload struct {
+	ID          int        `gorm:"type:int;primaryKey;autoIncrement"`
+	IDProduk    int        `gorm:"type:int;not null;index"`
+	IDUser      int        `gorm:"type:int;not null"`
+	NamaFile    string     `gorm:"type:varchar(255);not null"`
+	ObjectName  string     `gorm:"type:varchar(255);not null"`
+	ContentType string     `gorm:"type:varchar(64);not null"`
+	Size        int64      `gorm:"type:bigint;not null"`
+	Status      string     `gorm:"type:varchar(32);not null;default:'pending';index:idx_photo_upload_status"`
+	ExpiresAt   time.Time  `gorm:"type:timestamp;not null;index:idx_photo_upload_status"`
+	ConfirmedAt *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt   time.Time  `gorm:"type:timestamp"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp"`
+}
+
+func (PhotoUpload) TableName() string {
+	return "photo_uploads"
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// PrivateMedia is a file that only its owner, and staff allowed to read
+// private media, can download, such as an invoice or a KYC document. The
+// object is stored under constants.PrivateMediaPrefix.
+type PrivateMedia struct {
+	ID          int       `gorm:"type:int;primaryKey;autoIncrement"`
+	IDUser      int       `gorm:"type:int;not null;index"`
+	Kategori    string    `gorm:"type:varchar(32);not null"`
+	NamaFile    string    `gorm:"type:varchar(255);not null"`
+	ObjectName  string    `gorm:"type:varchar(255);not null;uniqueIndex"`
+	ContentType string    `gorm:"type:varchar(64);not null"`
+	Size        int64     `gorm:"type:bigint;not null"`
+	CreatedAt   time.Time `gorm:"type:timestamp"`
+	UpdatedAt   time.Time `gorm:"type:timestamp"`
+}
+
+func (PrivateMedia) TableName() string {
+	return "private_media"
+}
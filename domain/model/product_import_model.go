@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// ProductImport is a bulk product import from a CSV or XLSX file. Rows are
+// processed in the background; rows that fail are kept as
+// ProductImportErrors. A dry run only validates the rows.
+type ProductImport struct {
+	ID         int        `gorm:"type:int;primaryKey;autoIncrement"`
+	IDToko     int        `gorm:"type:int;not null;index"`
+	IDUser     int        `gorm:"type:int;not null"`
+	NamaFile   string     `gorm:"type:varchar(255);not null"`
+	Format     string     `gorm:"type:varchar(8);not null"`
+	DryRun     bool       `gorm:"not null;default:false"`
+	Status     string     `gorm:"type:varchar(32);not null;default:'pending'"`
+	TotalRows  int        `gorm:"type:int;not null;default:0"`
+	Processed  int        `gorm:"type:int;not null;default:0"`
+	Berhasil   int        `gorm:"type:int;not null;default:0"`
+	Gagal      int        `gorm:"type:int;not null;default:0"`
+	Pesan      string     `gorm:"type:text"`
+	FinishedAt *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt  time.Time  `gorm:"type:timestamp"`
+	UpdatedAt  time.Time  `gorm:"type:timestamp"`
+}
+
+func (ProductImport) TableName() string {
+	return "product_imports"
+}
+
+// ProductImportError is why one row of an import was rejected. Baris is the
+// row number in the file, counting the header as row 1.
+type ProductImportError struct {
+	ID         int    `gorm:"type:int;primaryKey;autoIncrement"`
+	IDImport   int    `gorm:"type:int;not null;index"`
+	Baris      int    `gorm:"type:int;not null"`
+	Kolom      string `gorm:"type:varchar(64)"`
+	Pesan      string `gorm:"type:text;not null"`
+	NamaProduk string `gorm:"type:varchar(255)"`
+}
+
+func (ProductImportError) TableName() string {
+	return "product_import_errors"
+}
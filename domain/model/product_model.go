@@ -10,49 +10,85 @@ type Product struct {
 	ID             int            `gorm:"primaryKey;autoIncrement"`
 	NamaProduk     string         `gorm:"type:varchar(255);not null"`
 	Slug           string         `gorm:"type:varchar(255);not null"`
-	HargaReseller  string         `gorm:"type:varchar(255);not null"`
-	HargaKonsumen  string         `gorm:"type:varchar(255);not null"`
+	HargaReseller  int64          `gorm:"type:bigint;not null"`
+	HargaKonsumen  int64          `gorm:"type:bigint;not null"`
+	// MinKuantitasReseller is the quantity a reseller must order on one line
+	// to get HargaReseller
+	MinKuantitasReseller int      `gorm:"type:int;not null;default:1"`
 	Stok           int            `gorm:"type:int;not null;default:0"`
 	Deskripsi      string         `gorm:"type:text;not null"`
-	CreatedAt      time.Time      `gorm:"type:timestamp;not null;default:current_timestamp"`
+	CreatedAt      time.Time      `gorm:"type:timestamp;not null;default:current_timestamp;index:idx_produk_created_at"`
 	UpdatedAt      time.Time      `gorm:"type:timestamp"`
 	DeletedAt      gorm.DeletedAt `gorm:"index"`
-	IDToko         int            `gorm:"type:int;not null"`
-	IDCategory     int            `gorm:"type:int;not null"`
+	IDToko         int            `gorm:"type:int;not null;index:idx_produk_toko"`
+	IDCategory     int            `gorm:"type:int;not null;index:idx_produk_category"`
+
+	// Terjual is the quantity sold in paid transactions. It is only filled
+	// by ProductRepository.Search and is never written.
+	Terjual        int64          `gorm:"->;-:migration"`
 
 	Toko           Shop           `gorm:"foreignKey:IDToko;references:ID"`
 	Category       Category       `gorm:"foreignKey:IDCategory;references:ID"`
 	PhotosProduct  []PhotoProduct `gorm:"foreignKey:IDProduk;references:ID"`
+	Options        []ProductOption  `gorm:"foreignKey:IDProduk;references:ID"`
+	Variants       []ProductVariant `gorm:"foreignKey:IDProduk;references:ID"`
 }
 
+// LogProduct is a snapshot of a product as it was at some point in time. A
+// snapshot is written on every create and update, and each transaction line
+// points at the snapshot that was current when it was bought.
 type LogProduct struct {
 	ID             int       `gorm:"type:int;primaryKey;autoIncrement"`
-	IDProduk       int       `gorm:"type:int;not null"`
+	IDProduk       int       `gorm:"type:int;not null;index:idx_log_produk_produk"`
 	NamaProduk     string    `gorm:"type:varchar(255);not null"`
 	Slug           string    `gorm:"type:varchar(255);not null"`
-	HargaReseller  string    `gorm:"type:varchar(255);not null"`
-	HargaKonsumen  string    `gorm:"type:varchar(255);not null"`
+	HargaReseller  int64     `gorm:"type:bigint;not null"`
+	HargaKonsumen  int64     `gorm:"type:bigint;not null"`
+	MinKuantitasReseller int `gorm:"type:int;not null;default:1"`
 	Stock          int       `gorm:"type:int;not null;default:0"`
 	Deskripsi      string    `gorm:"type:text;not null"`
 	CreatedAt      time.Time `gorm:"type:timestamp;not null;default:current_timestamp"`
 	UpdatedAt      time.Time `gorm:"type:timestamp"`
 	IDToko         int       `gorm:"type:int;not null"`
 	IDCategory     int       `gorm:"type:int;not null"`
+	// IDUser is who made the change; nil when the snapshot was taken at checkout
+	IDUser         *int      `gorm:"type:int"`
 
 	Toko           Shop           `gorm:"foreignKey:IDToko;references:ID"`
 	Produk         Product        `gorm:"foreignKey:IDProduk;references:ID"`
 	Category       Category       `gorm:"foreignKey:IDCategory;references:ID"`
+	User           *User          `gorm:"foreignKey:IDUser;references:ID"`
 }
 
+// PhotoProduct is one photo of a product. Photos are shown primary first,
+// then by Posisi.
 type PhotoProduct struct {
-	ID        int       `gorm:"primaryKey;autoIncrement"`
-	IDProduk  int       `gorm:"type:int;not null"`
-	URL       string    `gorm:"type:varchar(255);not null"`
-	CreatedAt time.Time `gorm:"type:timestamp;not null;default:current_timestamp"`
-	UpdatedAt time.Time `gorm:"type:timestamp"`
+	ID         int       `gorm:"primaryKey;autoIncrement"`
+	IDProduk   int       `gorm:"type:int;not null"`
+	URL        string    `gorm:"type:varchar(255);not null"`
+	// ObjectName is the photo's key in media storage; empty for photos
+	// uploaded before it was recorded
+	ObjectName string    `gorm:"type:varchar(255)"`
+	Posisi     int       `gorm:"type:int;not null;default:0"`
+	IsPrimary  bool      `gorm:"not null;default:false"`
+	CreatedAt  time.Time `gorm:"type:timestamp;not null;default:current_timestamp"`
+	UpdatedAt  time.Time `gorm:"type:timestamp"`
+
+	Product    Product          `gorm:"foreignKey:IDProduk;references:ID"`
+	LogProduct LogProduct       `gorm:"foreignKey:IDProduk;references:IDProduk"`
+	Renditions []PhotoRendition `gorm:"foreignKey:IDFoto;references:ID"`
+}
 
-	Product    Product     `gorm:"foreignKey:IDProduk;references:ID"`
-	LogProduct LogProduct  `gorm:"foreignKey:IDProduk;references:IDProduk"`
+// PhotoRendition is a resized or converted version of a product photo,
+// stored next to the original in media storage.
+type PhotoRendition struct {
+	ID         int    `gorm:"primaryKey;autoIncrement"`
+	IDFoto     int    `gorm:"type:int;not null;uniqueIndex:idx_photo_rendition"`
+	Nama       string `gorm:"type:varchar(32);not null;uniqueIndex:idx_photo_rendition"`
+	URL        string `gorm:"type:varchar(255);not null"`
+	ObjectName string `gorm:"type:varchar(255);not null"`
+	Lebar      int    `gorm:"type:int;not null"`
+	Tinggi     int    `gorm:"type:int;not null"`
 }
 
 func (Product) TableName() string {
@@ -66,3 +102,7 @@ func (LogProduct) TableName() string {
 func (PhotoProduct) TableName() string {
 	return "foto_produk"
 }
+
+func (PhotoRendition) TableName() string {
+	return "foto_produk_renditions"
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductOption is an option type of a product, e.g. "Ukuran" or "Warna".
+type ProductOption struct {
+	ID        int       `gorm:"type:int;primaryKey;autoIncrement"`
+	IDProduk  int       `gorm:"type:int;not null;uniqueIndex:idx_product_option"`
+	Nama      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_product_option"`
+	Posisi    int       `gorm:"type:int;not null;default:0"`
+	CreatedAt time.Time `gorm:"type:timestamp"`
+	UpdatedAt time.Time `gorm:"type:timestamp"`
+
+	Values []ProductOptionValue `gorm:"foreignKey:IDOption;references:ID"`
+}
+
+func (ProductOption) TableName() string {
+	return "product_options"
+}
+
+// ProductOptionValue is one value of an option type, e.g. "XL" or "Merah".
+type ProductOptionValue struct {
+	ID        int       `gorm:"type:int;primaryKey;autoIncrement"`
+	IDOption  int       `gorm:"type:int;not null;uniqueIndex:idx_product_option_value"`
+	Nilai     string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_product_option_value"`
+	Posisi    int       `gorm:"type:int;not null;default:0"`
+	CreatedAt time.Time `gorm:"type:timestamp"`
+	UpdatedAt time.Time `gorm:"type:timestamp"`
+
+	Option ProductOption `gorm:"foreignKey:IDOption;references:ID"`
+}
+
+func (ProductOptionValue) TableName() string {
+	return "product_option_values"
+}
+
+// ProductVariant is a purchasable combination of option values with its own
+// SKU, prices and stock. When a product has variants, its own Stok and prices
+// are kept in sync as the variants' total stock and lowest prices.
+type ProductVariant struct {
+	ID            int            `gorm:"type:int;primaryKey;autoIncrement"`
+	IDProduk      int            `gorm:"type:int;not null;index"`
+	SKU           string         `gorm:"type:varchar(64);not null;index"`
+	HargaReseller int64          `gorm:"type:bigint;not null"`
+	HargaKonsumen int64          `gorm:"type:bigint;not null"`
+	Stok          int            `gorm:"type:int;not null;default:0"`
+	IDFoto        *int           `gorm:"type:int"`
+	CreatedAt     time.Time      `gorm:"type:timestamp;not null;default:current_timestamp"`
+	UpdatedAt     time.Time      `gorm:"type:timestamp"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+
+	Foto         *PhotoProduct        `gorm:"foreignKey:IDFoto;references:ID"`
+	OptionValues []ProductOptionValue `gorm:"many2many:product_variant_values;joinForeignKey:IDVariant;joinReferences:IDOptionValue"`
+}
+
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// ResellerApplication is a user's request to join the reseller program.
+// Approving it grants the reseller role, which unlocks reseller prices.
+type ResellerApplication struct {
+	ID         int        `gorm:"type:int;primaryKey;autoIncrement"`
+	IDUser     int        `gorm:"type:int;not null;index"`
+	NamaUsaha  string     `gorm:"type:varchar(255);not null"`
+	Alasan     string     `gorm:"type:text;not null"`
+	Status     string     `gorm:"type:varchar(32);not null;default:'pending';index"`
+	Catatan    string     `gorm:"type:text"`
+	ReviewedBy *int       `gorm:"type:int"`
+	ReviewedAt *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt  time.Time  `gorm:"type:timestamp"`
+	UpdatedAt  time.Time  `gorm:"type:timestamp"`
+
+	User User `gorm:"foreignKey:IDUser;references:ID"`
+}
+
+func (ResellerApplication) TableName() string {
+	return "reseller_applications"
+}
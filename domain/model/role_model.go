@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Role groups permissions. Roles flagged RequiresTwoFactor only grant their
+// permissions to sessions that completed two-factor authentication.
+type Role struct {
+	ID                int       `gorm:"type:int;primaryKey;autoIncrement"`
+	Name              string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Description       string    `gorm:"type:varchar(255)"`
+	RequiresTwoFactor bool      `gorm:"default:false"`
+	CreatedAt         time.Time `gorm:"type:timestamp"`
+	UpdatedAt         time.Time `gorm:"type:timestamp"`
+
+	Permissions []Permission `gorm:"many2many:role_permissions;joinForeignKey:IDRole;joinReferences:IDPermission"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+type Permission struct {
+	ID          int       `gorm:"type:int;primaryKey;autoIncrement"`
+	Name        string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Description string    `gorm:"type:varchar(255)"`
+	CreatedAt   time.Time `gorm:"type:timestamp"`
+	UpdatedAt   time.Time `gorm:"type:timestamp"`
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+type UserRole struct {
+	IDUser    int       `gorm:"type:int;primaryKey"`
+	IDRole    int       `gorm:"type:int;primaryKey"`
+	CreatedAt time.Time `gorm:"type:timestamp"`
+
+	Role Role `gorm:"foreignKey:IDRole;references:ID"`
+}
+
+func (UserRole) TableName() string {
+	return "user_roles"
+}
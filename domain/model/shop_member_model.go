@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// ShopMember gives a user delegated access to a shop. The owner is the
+// shop's IDUser and has no member row.
+type ShopMember struct {
+	ID        int       `gorm:"type:int;primaryKey;autoIncrement"`
+	IDToko    int       `gorm:"type:int;not null;uniqueIndex:idx_shop_member"`
+	IDUser    int       `gorm:"type:int;not null;uniqueIndex:idx_shop_member;index"`
+	Role      string    `gorm:"type:varchar(32);not null"`
+	CreatedAt time.Time `gorm:"type:timestamp"`
+	UpdatedAt time.Time `gorm:"type:timestamp"`
+
+	Toko Shop `gorm:"foreignKey:IDToko;references:ID"`
+	User User `gorm:"foreignKey:IDUser;references:ID"`
+}
+
+func (ShopMember) TableName() string {
+	return "shop_members"
+}
+
+// ShopInvitation is an emailed invitation to join a shop. Only the hash of the
+// invitation token is stored.
+type ShopInvitation struct {
+	ID          int        `gorm:"type:int;primaryKey;autoIncrement"`
+	IDToko      int        `gorm:"type:int;not null;index"`
+	Email       string     `gorm:"type:varchar(255);not null;index"`
+	Role        string     `gorm:"type:varchar(32);not null"`
+	TokenHash   string     `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Status      string     `gorm:"type:varchar(32);not null;default:'pending'"`
+	IDInviter   int        `gorm:"type:int;not null"`
+	ExpiresAt   time.Time  `gorm:"type:timestamp;not null"`
+	RespondedAt *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt   time.Time  `gorm:"type:timestamp"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp"`
+
+	Toko Shop `gorm:"foreignKey:IDToko;references:ID"`
+}
+
+func (ShopInvitation) TableName() string {
+	return "shop_invitations"
+}
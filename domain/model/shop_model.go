@@ -7,18 +7,53 @@ import (
 )
 
 type Shop struct {
-	ID          int            `gorm:"type:int;primaryKey;autoIncrement"`
-	NamaToko    string         `gorm:"type:varchar(255);not null"`
-	URLToko     string         `gorm:"type:varchar(255);not null"`
-	CreatedAt   time.Time      `gorm:"type:timestamp;not null;default:current_timestamp"`
-	UpdatedAt   time.Time      `gorm:"type:timestamp"`
-	DeletedAt   gorm.DeletedAt `gorm:"index"`
-	IDUser      int            `gorm:"type:int;not null"`
+	ID       int    `gorm:"type:int;primaryKey;autoIncrement"`
+	NamaToko string `gorm:"type:varchar(255);not null"`
+	// URLToko is the shop's handle, the slug of its storefront URL
+	URLToko          string `gorm:"type:varchar(255);not null;uniqueIndex:idx_toko_url_toko"`
+	Deskripsi        string `gorm:"type:text"`
+	LogoURL          string `gorm:"type:varchar(255)"`
+	LogoObjectName   string `gorm:"type:varchar(255)"`
+	BannerURL        string `gorm:"type:varchar(255)"`
+	BannerObjectName string `gorm:"type:varchar(255)"`
+	// Origin address that orders are shipped from
+	DetailAlamat string `gorm:"type:text"`
+	IDProvinsi   string `gorm:"type:varchar(255)"`
+	IDKota       string `gorm:"type:varchar(255)"`
+	KodePos      string `gorm:"type:varchar(10)"`
+	// While on vacation the shop's products stay visible but cannot be
+	// ordered, until BukaKembali when set
+	Libur       bool       `gorm:"not null;default:false"`
+	PesanLibur  string     `gorm:"type:varchar(255)"`
+	BukaKembali *time.Time `gorm:"type:timestamp;null"`
+	// StatusVerifikasi follows the shop's latest verification request
+	StatusVerifikasi string         `gorm:"type:varchar(32);not null;default:'unverified'"`
+	CreatedAt        time.Time      `gorm:"type:timestamp;not null;default:current_timestamp"`
+	UpdatedAt        time.Time      `gorm:"type:timestamp"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+	// A user owns at most one shop, deleted shops included
+	IDUser int `gorm:"type:int;not null;uniqueIndex:idx_toko_id_user"`
 
-	User        User           `gorm:"foreignKey:IDUser;references:ID"`
-	Products    []Product      `gorm:"foreignKey:IDToko;references:ID"`
+	User           User              `gorm:"foreignKey:IDUser;references:ID"`
+	Products       []Product         `gorm:"foreignKey:IDToko;references:ID"`
+	JamOperasional []ShopOpeningHour `gorm:"foreignKey:IDToko;references:ID"`
+}
+
+// ShopOpeningHour is when a shop is open on one day of the week. Days
+// without a row are closed.
+type ShopOpeningHour struct {
+	ID     int `gorm:"type:int;primaryKey;autoIncrement"`
+	IDToko int `gorm:"type:int;not null;uniqueIndex:idx_toko_jam_hari"`
+	// Hari is the day of the week, 0 for Sunday to 6 for Saturday
+	Hari     int    `gorm:"type:int;not null;uniqueIndex:idx_toko_jam_hari"`
+	JamBuka  string `gorm:"type:varchar(5);not null"`
+	JamTutup string `gorm:"type:varchar(5);not null"`
 }
 
 func (Shop) TableName() string {
 	return "toko"
 }
+
+func (ShopOpeningHour) TableName() string {
+	return "toko_jam_operasional"
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// ShopVerification is a shop owner's request to have the shop verified,
+// with the identity and business documents to review. Approving it marks
+// the shop verified, which lifts the limits on unverified shops.
+type ShopVerification struct {
+	ID          int        `gorm:"type:int;primaryKey;autoIncrement"`
+	IDToko      int        `gorm:"type:int;not null;index"`
+	IDUser      int        `gorm:"type:int;not null"`
+	NamaPemilik string     `gorm:"type:varchar(255);not null"`
+	NIK         string     `gorm:"type:varchar(16);not null"`
+	NPWP        string     `gorm:"type:varchar(16)"`
+	Status      string     `gorm:"type:varchar(32);not null;default:'pending';index"`
+	Catatan     string     `gorm:"type:text"`
+	ReviewedBy  *int       `gorm:"type:int"`
+	ReviewedAt  *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt   time.Time  `gorm:"type:timestamp"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp"`
+
+	Toko    Shop                       `gorm:"foreignKey:IDToko;references:ID"`
+	User    User                       `gorm:"foreignKey:IDUser;references:ID"`
+	Dokumen []ShopVerificationDocument `gorm:"foreignKey:IDVerifikasi;references:ID"`
+}
+
+// ShopVerificationDocument attaches one of the owner's private files to a
+// verification request.
+type ShopVerificationDocument struct {
+	ID           int    `gorm:"type:int;primaryKey;autoIncrement"`
+	IDVerifikasi int    `gorm:"type:int;not null;index"`
+	Jenis        string `gorm:"type:varchar(32);not null"`
+	IDMedia      int    `gorm:"type:int;not null;index"`
+
+	Media PrivateMedia `gorm:"foreignKey:IDMedia;references:ID"`
+}
+
+func (ShopVerification) TableName() string {
+	return "toko_verifikasi"
+}
+
+func (ShopVerificationDocument) TableName() string {
+	return "toko_verifikasi_dokumen"
+}
@@ -8,7 +8,7 @@ import (
 
 type TRX struct {
 	ID               int            `gorm:"type:int;primaryKey;autoIncrement"`
-	HargaTotal       int            `gorm:"type:int;not null"`
+	HargaTotal       int64          `gorm:"type:bigint;not null"`
 	KodeInvoice      string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_kode_invoice"`
 	MethodBayar      string         `gorm:"type:varchar(255);not null"`
 	PaymentStatus    string         `gorm:"type:varchar(50);default:'pending_payment'"`
@@ -25,24 +25,46 @@ type TRX struct {
 	IDUser           int            `gorm:"type:int;not null"`
 	IDAlamat         int            `gorm:"type:int;not null"`
 
+	// DibayarAt is when the payment was confirmed, and SelesaiAt when the
+	// order was completed, releasing its funds to the shops
+	DibayarAt *time.Time `gorm:"type:timestamp;null;index"`
+	SelesaiAt *time.Time `gorm:"type:timestamp;null"`
+
 	User      User        `gorm:"foreignKey:IDUser;references:ID"`
 	Address   Address     `gorm:"foreignKey:IDAlamat;references:ID"`
 	DetailTRX []DetailTRX `gorm:"foreignKey:IDTRX;references:ID"`
 }
 
 type DetailTRX struct {
-	ID         int       `gorm:"type:int;primaryKey;autoIncrement"`
-	IDTRX      int       `gorm:"type:int;not null"`
-	IDProduk   int       `gorm:"type:int;not null"`
-	IDToko     int       `gorm:"type:int;not null"`
-	Kuantitas  int       `gorm:"type:int;not null"`
-	HargaTotal int       `gorm:"type:int;not null"`
-	CreatedAt  time.Time `gorm:"type:timestamp;not null;default:current_timestamp"`
-	UpdatedAt  time.Time `gorm:"type:timestamp"`
-
-	TRX     TRX     `gorm:"foreignKey:IDTRX;references:ID"`
-	Product Product `gorm:"foreignKey:IDProduk;references:ID"`
-	Shop    Shop    `gorm:"foreignKey:IDToko;references:ID"`
+	ID        int  `gorm:"type:int;primaryKey;autoIncrement"`
+	IDTRX     int  `gorm:"type:int;not null"`
+	IDProduk  int  `gorm:"type:int;not null;index:idx_detail_trx_produk"`
+	IDVariant *int `gorm:"type:int"`
+	// IDLogProduk is the product snapshot bought; nil for lines created
+	// before snapshots were recorded
+	IDLogProduk *int `gorm:"type:int"`
+	IDToko      int  `gorm:"type:int;not null"`
+	Kuantitas   int  `gorm:"type:int;not null"`
+	// HargaSatuan is the unit price charged, from the TierHarga price tier
+	HargaSatuan int64     `gorm:"type:bigint;not null;default:0"`
+	TierHarga   string    `gorm:"type:varchar(20);not null;default:'konsumen'"`
+	HargaTotal  int64     `gorm:"type:bigint;not null"`
+	CreatedAt   time.Time `gorm:"type:timestamp;not null;default:current_timestamp"`
+	UpdatedAt   time.Time `gorm:"type:timestamp"`
+
+	// The variant as it was when bought, since log_produk only snapshots the
+	// product. VariantAtribut is a JSON object of option names to values.
+	// Empty for lines without a variant or created before these were recorded.
+	VariantSKU           string `gorm:"type:varchar(64)"`
+	VariantAtribut       string `gorm:"type:text;null"`
+	VariantHargaReseller int64  `gorm:"type:bigint;not null;default:0"`
+	VariantHargaKonsumen int64  `gorm:"type:bigint;not null;default:0"`
+
+	TRX       TRX             `gorm:"foreignKey:IDTRX;references:ID"`
+	Product   Product         `gorm:"foreignKey:IDProduk;references:ID"`
+	Variant   *ProductVariant `gorm:"foreignKey:IDVariant;references:ID"`
+	LogProduk *LogProduct     `gorm:"foreignKey:IDLogProduk;references:ID"`
+	Shop      Shop            `gorm:"foreignKey:IDToko;references:ID"`
 }
 
 func (TRX) TableName() string {
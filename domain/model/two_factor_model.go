@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// UserTwoFactor holds a user's TOTP enrollment. The secret is encrypted at rest.
+// EnabledAt stays nil until the user confirms enrollment with a valid code.
+type UserTwoFactor struct {
+	ID              int        `gorm:"type:int;primaryKey;autoIncrement"`
+	IDUser          int        `gorm:"type:int;not null;uniqueIndex:idx_two_factor_user"`
+	SecretEncrypted string     `gorm:"type:varchar(255);not null"`
+	LastUsedStep    int64      `gorm:"type:bigint;not null;default:0"`
+	EnabledAt       *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt       time.Time  `gorm:"type:timestamp"`
+	UpdatedAt       time.Time  `gorm:"type:timestamp"`
+
+	User User `gorm:"foreignKey:IDUser;references:ID"`
+}
+
+func (UserTwoFactor) TableName() string {
+	return "user_two_factor"
+}
+
+// RecoveryCode is a single-use backup code for a 2FA-enabled account.
+type RecoveryCode struct {
+	ID        int        `gorm:"type:int;primaryKey;autoIncrement"`
+	IDUser    int        `gorm:"type:int;not null;index"`
+	CodeHash  string     `gorm:"type:varchar(128);not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp;null"`
+	CreatedAt time.Time  `gorm:"type:timestamp"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
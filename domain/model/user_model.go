@@ -18,6 +18,11 @@ type User struct {
 	IsAdmin      bool      `gorm:"column:isAdmin;default:false"`
 	CreatedAt    time.Time `gorm:"type:timestamp"`
 	UpdatedAt    time.Time `gorm:"type:timestamp"`
+
+	NoTelpVerifiedAt *time.Time `gorm:"column:notelp_verified_at;type:timestamp;null"`
+	// EmailVerifiedAt is set once the user proved they own the email, by
+	// signing in with a provider that verified it or by resetting the password
+	EmailVerifiedAt *time.Time `gorm:"type:timestamp;null"`
 }
 
 func (User) TableName() string {
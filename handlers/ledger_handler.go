@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/services"
+)
+
+type LedgerHandler struct {
+	ledgerService services.LedgerService
+	validator     *validator.Validate
+}
+
+func NewLedgerHandler(ledgerService services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+		validator:     validator.New(),
+	}
+}
+
+func (h *LedgerHandler) GetShopBalance(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	balance, err := h.ledgerService.GetShopBalance(userID, shopID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, balance))
+}
+
+func (h *LedgerHandler) GetShopLedger(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	var query request.ShopLedgerQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid query parameters", err.Error()))
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	ledger, err := h.ledgerService.GetShopLedger(userID, shopID, &query)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, ledger))
+}
+
+func (h *LedgerHandler) GetReconciliation(c *fiber.Ctx) error {
+	report, err := h.ledgerService.GetReconciliation()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, report))
+}
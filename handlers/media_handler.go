@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/media"
+	"github.com/rdsarjito/marketplace-backend/services"
+	"github.com/rdsarjito/marketplace-backend/storage"
+)
+
+type MediaHandler struct {
+	mediaService services.MediaService
+	storage      storage.MediaStorage
+}
+
+func NewMediaHandler(mediaService services.MediaService, mediaStorage storage.MediaStorage) *MediaHandler {
+	return &MediaHandler{
+		mediaService: mediaService,
+		storage:      mediaStorage,
+	}
+}
+
+func (h *MediaHandler) UploadPrivateMedia(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("File not found", nil))
+	}
+	if file.Size > constants.MaxPrivateMediaFileSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(response.ErrorResponse(constants.ErrPrivateMediaTooLarge, nil))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse("Unable to read file", err.Error()))
+	}
+	defer src.Close()
+
+	privateMedia, err := h.mediaService.UploadPrivate(c.UserContext(), userID, c.FormValue("kategori"), file.Filename, src)
+	if err != nil {
+		return c.Status(mediaErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse(constants.MsgPrivateMediaUploaded, privateMedia))
+}
+
+func (h *MediaHandler) GetPrivateMedia(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid file ID", nil))
+	}
+
+	privateMedia, err := h.mediaService.GetPrivate(userID, id)
+	if err != nil {
+		return c.Status(mediaErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, privateMedia))
+}
+
+func (h *MediaHandler) GetPrivateMediaForStaff(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid file ID", nil))
+	}
+
+	privateMedia, err := h.mediaService.GetPrivateForStaff(id)
+	if err != nil {
+		return c.Status(mediaErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, privateMedia))
+}
+
+func (h *MediaHandler) DeletePrivateMedia(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid file ID", nil))
+	}
+
+	if err := h.mediaService.DeletePrivate(c.UserContext(), userID, id); err != nil {
+		return c.Status(mediaErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgPrivateMediaDeleted, nil))
+}
+
+// ServeMedia serves media files from the configured media storage
+// This is a public endpoint to serve product images. It answers GET and HEAD
+// requests, revalidates with ETag and Last-Modified, and serves single byte
+// ranges. Images can be resized or converted with a preset or signed
+// transformation parameters. Private files need a signed URL that has not
+// expired.
+func (h *MediaHandler) ServeMedia(c *fiber.Ctx) error {
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		c.Set(fiber.HeaderAllow, "GET, HEAD")
+		return c.Status(fiber.StatusMethodNotAllowed).JSON(response.ErrorResponse("Method not allowed", nil))
+	}
+
+	// Get object name from path (middleware removes /media prefix)
+	path := c.Path()
+	objectName := strings.TrimPrefix(path, "/media")
+	objectName = strings.TrimPrefix(objectName, "/")
+
+	if objectName == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Object name required", nil))
+	}
+	// Storage cleans names, so "//private/..." or "a/../private/..." would
+	// reach private files without passing the checks below
+	if !storage.IsCleanObjectName(objectName) {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid object name", nil))
+	}
+
+	private := strings.HasPrefix(objectName, constants.PrivateMediaPrefix)
+	var expiresAt time.Time
+	if private {
+		// Checked before looking the object up so that unsigned requests
+		// cannot tell which files exist
+		var err error
+		expiresAt, err = h.mediaService.CheckPrivateAccess(objectName, c.Queries())
+		if err != nil {
+			return c.Status(mediaErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+		}
+	} else if wantsTransform(c) {
+		transformed, err := h.mediaService.TransformedObject(c.UserContext(), objectName, c.Queries())
+		if err != nil {
+			return c.Status(mediaErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+		}
+		objectName = transformed
+	}
+
+	// Get object info to check if file exists and get content type
+	objInfo, err := h.storage.Stat(c.UserContext(), objectName)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(response.ErrorResponse(
+			fmt.Sprintf("File not found: %s", objectName), nil))
+	}
+	if err != nil {
+		log.Printf("[ServeMedia] Error reading object info: %v (objectName: %s)", err, objectName)
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse("Error retrieving file", nil))
+	}
+
+	contentType := "application/octet-stream"
+	if objInfo.ContentType != "" {
+		contentType = objInfo.ContentType
+	}
+	etag := ""
+	if objInfo.ETag != "" {
+		etag = `"` + objInfo.ETag + `"`
+		c.Set(fiber.HeaderETag, etag)
+	}
+	// HTTP dates have no fractional seconds
+	lastModified := objInfo.LastModified.UTC().Truncate(time.Second)
+	if !objInfo.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	}
+
+	c.Set("Content-Type", contentType)
+	if private {
+		// Only the browser that was given the link may keep it, and only
+		// until the link expires
+		c.Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(time.Until(expiresAt).Seconds())))
+	} else {
+		c.Set("Cache-Control", "public, max-age=31536000") // Cache for 1 year
+	}
+	c.Set("Accept-Ranges", "bytes")
+	c.Set("X-Accel-Buffering", "no") // Disable nginx buffering for streaming
+
+	if notModified(c, etag, lastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	offset, length := int64(0), objInfo.Size
+	status := fiber.StatusOK
+	if header := c.Get(fiber.HeaderRange); header != "" && ifRangeMatches(c.Get(fiber.HeaderIfRange), etag, lastModified) {
+		byteRange, err := parseByteRange(header, objInfo.Size)
+		if err != nil {
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", objInfo.Size))
+			return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(response.ErrorResponse("Requested range not satisfiable", nil))
+		}
+		if byteRange != nil {
+			offset, length = byteRange.start, byteRange.length
+			status = fiber.StatusPartialContent
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, objInfo.Size))
+		}
+	}
+
+	c.Status(status)
+	if c.Method() == fiber.MethodHead {
+		c.Response().Header.SetContentLength(int(length))
+		c.Response().SkipBody = true
+		return nil
+	}
+
+	var obj io.ReadCloser
+	if status == fiber.StatusPartialContent {
+		obj, err = h.storage.GetObjectRange(c.UserContext(), objectName, offset, length)
+	} else {
+		obj, err = h.storage.GetObject(c.UserContext(), objectName)
+	}
+	if err != nil {
+		log.Printf("[ServeMedia] Error opening object: %v (objectName: %s)", err, objectName)
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse("Error retrieving file", nil))
+	}
+
+	// Stream the object with its length known up front; fasthttp closes it
+	// once the response has been written
+	c.Context().SetBodyStream(obj, int(length))
+	return nil
+}
+
+// wantsTransform reports whether the request asks for a transformed image.
+// Other query parameters, such as cache busters, are ignored.
+func wantsTransform(c *fiber.Ctx) bool {
+	if c.Query("preset") != "" {
+		return true
+	}
+	for param := range c.Queries() {
+		if media.IsTransformParam(param) {
+			return true
+		}
+	}
+	return false
+}
+
+func mediaErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrInvalidTransform:
+		return fiber.StatusBadRequest
+	case constants.ErrInvalidMediaSignature, constants.ErrMediaURLExpired:
+		return fiber.StatusForbidden
+	case constants.ErrMediaNotFound:
+		return fiber.StatusNotFound
+	case constants.ErrUnsupportedImage, constants.ErrUnsupportedPrivateMedia:
+		return fiber.StatusUnsupportedMediaType
+	case constants.ErrImageTooLarge:
+		return fiber.StatusUnprocessableEntity
+	case constants.ErrPrivateMediaTooLarge:
+		return fiber.StatusRequestEntityTooLarge
+	case constants.ErrInvalidMediaCategory:
+		return fiber.StatusBadRequest
+	case constants.ErrPrivateMediaInUse:
+		return fiber.StatusConflict
+	default:
+		log.Printf("[Media] %v", err)
+		return fiber.StatusInternalServerError
+	}
+}
+
+// notModified reports whether the client's cached copy, identified by the
+// conditional request headers, is still current. If-None-Match takes
+// precedence over If-Modified-Since.
+func notModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		if etag == "" {
+			return false
+		}
+		for _, tag := range strings.Split(noneMatch, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if modifiedSince := c.Get(fiber.HeaderIfModifiedSince); modifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(modifiedSince)
+		return err == nil && !lastModified.After(since)
+	}
+	return false
+}
+
+// ifRangeMatches reports whether a Range request should be honoured given its
+// If-Range header, which holds the ETag or date of the client's partial copy.
+func ifRangeMatches(ifRange, etag string, lastModified time.Time) bool {
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return etag != "" && ifRange == etag
+	}
+	date, err := http.ParseTime(ifRange)
+	return err == nil && !lastModified.IsZero() && lastModified.Equal(date)
+}
+
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// parseByteRange parses a Range header for an object of size bytes. Headers
+// it does not handle, such as malformed ones or several ranges, return nil so
+// that the whole object is sent instead.
+func parseByteRange(header string, size int64) (*byteRange, error) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return nil, nil
+	}
+	startStr, endStr, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return nil, nil
+	}
+	startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+
+	// bytes=-n asks for the last n bytes
+	if startStr == "" {
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffix < 0 {
+			return nil, nil
+		}
+		if suffix == 0 || size == 0 {
+			return nil, errRangeNotSatisfiable
+		}
+		suffix = min(suffix, size)
+		return &byteRange{start: size - suffix, length: suffix}, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return nil, nil
+	}
+	if start >= size {
+		return nil, errRangeNotSatisfiable
+	}
+
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return nil, nil
+		}
+		end = min(end, size-1)
+	}
+	return &byteRange{start: start, length: end - start + 1}, nil
+}
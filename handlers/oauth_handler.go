@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/oauth"
+	"github.com/rdsarjito/marketplace-backend/services"
+)
+
+const oauthStateCookie = "oauth_state"
+
+type OAuthHandler struct {
+	oauthService services.OAuthService
+	frontendURL  string
+	validator    *validator.Validate
+}
+
+func NewOAuthHandler(oauthService services.OAuthService, frontendURL string) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+		frontendURL:  strings.TrimRight(frontendURL, "/"),
+		validator:    validator.New(),
+	}
+}
+
+// Start redirects the browser to the provider's consent screen. The state,
+// PKCE verifier and nonce are kept in an encrypted HttpOnly cookie.
+func (h *OAuthHandler) Start(c *fiber.Ctx) error {
+	authURL, sealedState, err := h.oauthService.Begin(c.Params("provider"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthStateCookie,
+		Value:    sealedState,
+		Path:     "/api/v1/auth",
+		MaxAge:   int(oauth.StateTTL / time.Second),
+		Secure:   h.secureCookies(c),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect(authURL, fiber.StatusTemporaryRedirect)
+}
+
+// Callback handles the provider redirect. On success the browser is sent to
+// the frontend with a one-time exchange code, never with the JWT itself.
+func (h *OAuthHandler) Callback(c *fiber.Ctx) error {
+	provider := c.Params("provider")
+	sealedState := c.Cookies(oauthStateCookie)
+
+	// The state cookie is single-use
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthStateCookie,
+		Value:    "",
+		Path:     "/api/v1/auth",
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+		Secure:   h.secureCookies(c),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		log.Printf("[OAuth] %s returned error: %s", provider, providerErr)
+		return h.redirectError(c)
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		return h.redirectError(c)
+	}
+
+	result, err := h.oauthService.Callback(c.UserContext(), provider, code, c.Query("state"), sealedState)
+	if err != nil {
+		log.Printf("[OAuth] %s callback failed: %v", provider, err)
+		if err.Error() == constants.ErrIdentityAlreadyLinked {
+			return c.Redirect(fmt.Sprintf("%s/account/connections?error=already_linked", h.frontendURL), fiber.StatusTemporaryRedirect)
+		}
+		if err.Error() == constants.ErrOAuthAccountExists {
+			return c.Redirect(fmt.Sprintf("%s/login?error=account_exists", h.frontendURL), fiber.StatusTemporaryRedirect)
+		}
+		return h.redirectError(c)
+	}
+
+	if result.LinkCode != "" {
+		return c.Redirect(fmt.Sprintf("%s/account/connections?provider=%s&link_code=%s", h.frontendURL, url.QueryEscape(provider), url.QueryEscape(result.LinkCode)), fiber.StatusTemporaryRedirect)
+	}
+
+	return c.Redirect(fmt.Sprintf("%s/login/callback?code=%s", h.frontendURL, url.QueryEscape(result.ExchangeCode)), fiber.StatusTemporaryRedirect)
+}
+
+// Exchange trades the one-time code from the callback redirect for a JWT.
+func (h *OAuthHandler) Exchange(c *fiber.Ctx) error {
+	var req request.OAuthExchangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	authResponse, err := h.oauthService.Exchange(&req)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	if authResponse.TwoFactorRequired {
+		return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgTwoFactorRequired, authResponse))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgUserLoggedIn, authResponse))
+}
+
+func (h *OAuthHandler) ListIdentities(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	identities, err := h.oauthService.ListIdentities(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, identities))
+}
+
+// StartLink returns the URL the browser should open to link a provider
+// account to the signed-in user.
+func (h *OAuthHandler) StartLink(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	link, err := h.oauthService.BeginLink(userID, c.Params("provider"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgSuccess, link))
+}
+
+// ConfirmLink links the provider account from the link_code the callback
+// redirected to the frontend with.
+func (h *OAuthHandler) ConfirmLink(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	var req request.ConfirmOAuthLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	identity, err := h.oauthService.ConfirmLink(userID, c.Params("provider"), &req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if err.Error() == constants.ErrIdentityAlreadyLinked {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgIdentityLinked, identity))
+}
+
+func (h *OAuthHandler) Unlink(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	if err := h.oauthService.Unlink(userID, c.Params("provider")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgIdentityUnlinked, nil))
+}
+
+func (h *OAuthHandler) redirectError(c *fiber.Ctx) error {
+	return c.Redirect(fmt.Sprintf("%s/login?error=%s", h.frontendURL, constants.ErrOAuthFailed), fiber.StatusTemporaryRedirect)
+}
+
+func (h *OAuthHandler) secureCookies(c *fiber.Ctx) bool {
+	return c.Protocol() == "https" || strings.HasPrefix(h.frontendURL, "https://")
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/services"
+)
+
+type OTPHandler struct {
+	otpService services.OTPService
+	validator  *validator.Validate
+}
+
+func NewOTPHandler(otpService services.OTPService) *OTPHandler {
+	return &OTPHandler{
+		otpService: otpService,
+		validator:  validator.New(),
+	}
+}
+
+func (h *OTPHandler) RequestPhoneVerification(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	if err := h.otpService.RequestPhoneVerification(userID); err != nil {
+		return c.Status(otpErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgOTPSent, nil))
+}
+
+func (h *OTPHandler) VerifyPhone(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	var req request.VerifyPhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	userProfile, err := h.otpService.VerifyPhone(userID, &req)
+	if err != nil {
+		return c.Status(otpErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgPhoneVerified, userProfile))
+}
+
+func (h *OTPHandler) RequestLoginOTP(c *fiber.Ctx) error {
+	var req request.RequestLoginOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	if err := h.otpService.RequestLoginOTP(&req); err != nil {
+		return c.Status(otpErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgLoginOTPSent, nil))
+}
+
+func (h *OTPHandler) LoginWithOTP(c *fiber.Ctx) error {
+	var req request.LoginOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	authResponse, err := h.otpService.LoginWithOTP(&req)
+	if err != nil {
+		return c.Status(otpErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	if authResponse.TwoFactorRequired {
+		return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgTwoFactorRequired, authResponse))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgUserLoggedIn, authResponse))
+}
+
+func otpErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrOTPResendCooldown, constants.ErrOTPTooManyAttempts:
+		return fiber.StatusTooManyRequests
+	case constants.ErrInvalidOTP, constants.ErrOTPExpired:
+		return fiber.StatusUnauthorized
+	default:
+		return fiber.StatusBadRequest
+	}
+}
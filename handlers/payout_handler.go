@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/services"
+)
+
+type PayoutHandler struct {
+	payoutService services.PayoutService
+	validator     *validator.Validate
+}
+
+func NewPayoutHandler(payoutService services.PayoutService) *PayoutHandler {
+	return &PayoutHandler{
+		payoutService: payoutService,
+		validator:     validator.New(),
+	}
+}
+
+func (h *PayoutHandler) GetBankAccount(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	account, err := h.payoutService.GetBankAccount(userID, shopID)
+	if err != nil {
+		status := payoutErrorStatus(err)
+		// Asking for an account that was never registered is a lookup miss
+		if err.Error() == constants.ErrBankAccountRequired {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, account))
+}
+
+func (h *PayoutHandler) SaveBankAccount(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	var req request.SaveBankAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	account, err := h.payoutService.SaveBankAccount(userID, shopID, &req)
+	if err != nil {
+		return c.Status(payoutErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgBankAccountSaved, account))
+}
+
+func (h *PayoutHandler) RequestPayout(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	var req request.RequestPayoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	payout, err := h.payoutService.RequestPayout(userID, shopID, &req)
+	if err != nil {
+		return c.Status(payoutErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse(constants.MsgPayoutRequested, payout))
+}
+
+func (h *PayoutHandler) GetShopPayouts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	payouts, err := h.payoutService.GetShopPayouts(userID, shopID)
+	if err != nil {
+		return c.Status(payoutErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, payouts))
+}
+
+func (h *PayoutHandler) GetPayouts(c *fiber.Ctx) error {
+	var query request.PayoutQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid query parameters", err.Error()))
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	payouts, err := h.payoutService.GetPayouts(&query)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, payouts))
+}
+
+func (h *PayoutHandler) Approve(c *fiber.Ctx) error {
+	return h.review(c, h.payoutService.Approve, constants.MsgPayoutApproved)
+}
+
+func (h *PayoutHandler) Reject(c *fiber.Ctx) error {
+	return h.review(c, h.payoutService.Reject, constants.MsgPayoutRejected)
+}
+
+func (h *PayoutHandler) review(c *fiber.Ctx, decide func(actorID, id int, req *request.ReviewPayoutRequest) (*response.PayoutResponse, error), message string) error {
+	actorID := c.Locals("userID").(int)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid payout ID", nil))
+	}
+
+	// The review note is optional, so an empty body is fine
+	var req request.ReviewPayoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+		}
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	payout, err := decide(actorID, id, &req)
+	if err != nil {
+		return c.Status(payoutErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(message, payout))
+}
+
+func payoutErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrShopNotVerified:
+		return fiber.StatusForbidden
+	case constants.ErrPayoutNotFound:
+		return fiber.StatusNotFound
+	case constants.ErrInsufficientBalance, constants.ErrPayoutNotPending:
+		return fiber.StatusConflict
+	default:
+		return shopAccessErrorStatus(err)
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/services"
+)
+
+type PhotoUploadHandler struct {
+	photoUploadService services.PhotoUploadService
+	validator          *validator.Validate
+}
+
+func NewPhotoUploadHandler(photoUploadService services.PhotoUploadService) *PhotoUploadHandler {
+	return &PhotoUploadHandler{
+		photoUploadService: photoUploadService,
+		validator:          validator.New(),
+	}
+}
+
+func (h *PhotoUploadHandler) CreateUpload(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid product ID", nil))
+	}
+
+	var req request.CreatePhotoUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	upload, err := h.photoUploadService.CreateUpload(c.UserContext(), userID, productID, &req)
+	if err != nil {
+		return c.Status(photoUploadErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse(constants.MsgPhotoUploadCreated, upload))
+}
+
+func (h *PhotoUploadHandler) ConfirmUpload(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid product ID", nil))
+	}
+
+	uploadID, err := strconv.Atoi(c.Params("id_upload"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid upload ID", nil))
+	}
+
+	prod, err := h.photoUploadService.ConfirmUpload(c.UserContext(), userID, productID, uploadID)
+	if err != nil {
+		return c.Status(photoUploadErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgPhotoUploaded, prod))
+}
+
+func photoUploadErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrPhotoUploadFailed:
+		return fiber.StatusInternalServerError
+	case constants.ErrPhotoTooLarge:
+		return fiber.StatusRequestEntityTooLarge
+	case constants.ErrPhotoUploadIncomplete:
+		return fiber.StatusConflict
+	case constants.ErrDirectUploadUnsupported:
+		return fiber.StatusNotImplemented
+	}
+	return shopAccessErrorStatus(err)
+}
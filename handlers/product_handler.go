@@ -1,41 +1,44 @@
 package handlers
 
 import (
-	"bufio"
-	"fmt"
-	"io"
-	"log"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/minio/minio-go/v7"
 	"github.com/rdsarjito/marketplace-backend/constants"
 	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
 	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
 	"github.com/rdsarjito/marketplace-backend/services"
-	"github.com/rdsarjito/marketplace-backend/storage"
 )
 
 type ProductHandler struct {
 	productService services.ProductService
-	storage        storage.MediaStorage
 	validator      *validator.Validate
 }
 
-func NewProductHandler(productService services.ProductService, mediaStorage storage.MediaStorage) *ProductHandler {
+func NewProductHandler(productService services.ProductService) *ProductHandler {
 	return &ProductHandler{
 		productService: productService,
-		storage:        mediaStorage,
 		validator:      validator.New(),
 	}
 }
 
 func (h *ProductHandler) GetListProduct(c *fiber.Ctx) error {
-	products, err := h.productService.GetListProduct()
+	var query request.ProductListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid query parameters", err.Error()))
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	products, err := h.productService.GetListProduct(&query)
 	if err != nil {
+		switch err.Error() {
+		case constants.ErrInvalidCursor, constants.ErrInvalidPriceRange:
+			return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse(err.Error(), nil))
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
 	}
 
@@ -117,6 +120,98 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgProductDeleted, nil))
 }
 
+func (h *ProductHandler) CreateVariant(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid product ID", nil))
+	}
+
+	var req request.ProductVariantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	product, err := h.productService.CreateVariant(userID, productID, &req)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse(constants.MsgVariantCreated, product))
+}
+
+func (h *ProductHandler) UpdateVariant(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid product ID", nil))
+	}
+
+	variantID, err := strconv.Atoi(c.Params("id_variant"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid variant ID", nil))
+	}
+
+	var req request.ProductVariantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	product, err := h.productService.UpdateVariant(userID, productID, variantID, &req)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgVariantUpdated, product))
+}
+
+func (h *ProductHandler) DeleteVariant(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid product ID", nil))
+	}
+
+	variantID, err := strconv.Atoi(c.Params("id_variant"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid variant ID", nil))
+	}
+
+	product, err := h.productService.DeleteVariant(userID, productID, variantID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgVariantDeleted, product))
+}
+
+func (h *ProductHandler) GetHistory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid product ID", nil))
+	}
+
+	history, err := h.productService.GetHistory(userID, productID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgProductHistoryRetrieved, history))
+}
+
 func (h *ProductHandler) UploadProductPhoto(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(int)
 	id, err := strconv.Atoi(c.Params("id"))
@@ -128,6 +223,9 @@ func (h *ProductHandler) UploadProductPhoto(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("File not found", nil))
 	}
+	if file.Size > constants.MaxPhotoFileSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(response.ErrorResponse(constants.ErrPhotoTooLarge, nil))
+	}
 
 	src, err := file.Open()
 	if err != nil {
@@ -135,102 +233,80 @@ func (h *ProductHandler) UploadProductPhoto(c *fiber.Ctx) error {
 	}
 	defer src.Close()
 
-	objectName := fmt.Sprintf("products/%d/%d_%s", id, time.Now().UnixNano(), sanitizeFilename(file.Filename))
-	url, err := h.storage.Upload(c.UserContext(), objectName, src, file.Size, file.Header.Get("Content-Type"))
+	prod, err := h.productService.AddProductPhoto(c.UserContext(), userID, id, file.Filename, src)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse("Upload failed", err.Error()))
+		if err.Error() == constants.ErrPhotoUploadFailed {
+			return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+		}
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
 	}
 
-	prod, err := h.productService.AddProductPhoto(userID, id, url)
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgPhotoUploaded, prod))
+}
+
+func (h *ProductHandler) ReorderPhotos(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	productID, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse(err.Error(), nil))
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid product ID", nil))
 	}
 
-	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse("Photo uploaded", prod))
-}
-
-// ServeMedia serves media files from MinIO storage
-// This is a public endpoint to serve product images
-func (h *ProductHandler) ServeMedia(c *fiber.Ctx) error {
-	// Get object name from path (middleware removes /media prefix)
-	path := c.Path()
-	objectName := strings.TrimPrefix(path, "/media")
-	objectName = strings.TrimPrefix(objectName, "/")
+	var req request.ReorderPhotosRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
 
-	if objectName == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Object name required", nil))
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
 	}
 
-	// Get object info to check if file exists and get content type
-	objInfo, err := h.storage.GetObjectInfo(c.UserContext(), objectName)
+	product, err := h.productService.ReorderPhotos(userID, productID, &req)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(response.ErrorResponse(
-			fmt.Sprintf("File not found: %s", objectName), nil))
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
 	}
 
-	// Extract content type and size from object info
-	contentType := "application/octet-stream"
-	var contentLength int64 = -1
-	if objInfo != nil {
-		if minioInfo, ok := objInfo.(*minio.ObjectInfo); ok {
-			if minioInfo.ContentType != "" {
-				contentType = minioInfo.ContentType
-			}
-			contentLength = minioInfo.Size
-		}
-	}
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgPhotosReordered, product))
+}
 
-	c.Set("Content-Type", contentType)
-	c.Set("Cache-Control", "public, max-age=31536000") // Cache for 1 year
-	c.Set("X-Accel-Buffering", "no")                   // Disable nginx buffering for streaming
+func (h *ProductHandler) SetPrimaryPhoto(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
 
-	// Set Content-Length if available
-	if contentLength > 0 {
-		c.Set("Content-Length", fmt.Sprintf("%d", contentLength))
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid product ID", nil))
 	}
 
-	// Get context and object before SetBodyStreamWriter
-	// Context is not valid inside goroutine, so we must get it here
-	ctx := c.UserContext()
-	obj, err := h.storage.GetObject(ctx, objectName)
+	photoID, err := strconv.Atoi(c.Params("id_foto"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse("Error retrieving file", nil))
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid photo ID", nil))
 	}
 
-	// Use SetBodyStreamWriter for reliable streaming through Nginx proxy
-	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
-		defer func() {
-			if closer, ok := obj.(io.Closer); ok {
-				closer.Close()
-			}
-		}()
+	product, err := h.productService.SetPrimaryPhoto(userID, productID, photoID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
 
-		// Copy data from MinIO object to response writer
-		if _, err := io.Copy(w, obj); err != nil {
-			log.Printf("[ServeMedia] Error streaming: %v (objectName: %s)", err, objectName)
-			return
-		}
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgPrimaryPhotoSet, product))
+}
 
-		// Flush any remaining data
-		if err := w.Flush(); err != nil {
-			log.Printf("[ServeMedia] Error flushing: %v (objectName: %s)", err, objectName)
-			return
-		}
-	})
+func (h *ProductHandler) DeleteProductPhoto(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
 
-	return nil
-}
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid product ID", nil))
+	}
 
-func sanitizeFilename(name string) string {
-	name = strings.ToLower(name)
-	name = strings.ReplaceAll(name, " ", "_")
+	photoID, err := strconv.Atoi(c.Params("id_foto"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid photo ID", nil))
+	}
 
-	return strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') ||
-			(r >= '0' && r <= '9') ||
-			r == '.' || r == '_' || r == '-' {
-			return r
-		}
-		return '-'
-	}, name)
+	product, err := h.productService.DeleteProductPhoto(c.UserContext(), userID, productID, photoID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgPhotoDeleted, product))
 }
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/services"
+	"github.com/rdsarjito/marketplace-backend/utils"
+)
+
+type ProductImportHandler struct {
+	productImportService services.ProductImportService
+	validator            *validator.Validate
+}
+
+func NewProductImportHandler(productImportService services.ProductImportService) *ProductImportHandler {
+	return &ProductImportHandler{
+		productImportService: productImportService,
+		validator:            validator.New(),
+	}
+}
+
+func (h *ProductImportHandler) StartImport(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	dryRun := false
+	if value := c.FormValue("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid dry_run value", nil))
+		}
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("File not found", nil))
+	}
+	if file.Size > constants.ProductImportMaxFileSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(response.ErrorResponse(constants.ErrSpreadsheetTooLarge, nil))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse("Unable to read file", err.Error()))
+	}
+	defer src.Close()
+
+	productImport, err := h.productImportService.StartImport(userID, shopID, file.Filename, src, dryRun)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(response.SuccessResponse(constants.MsgProductImportStarted, productImport))
+}
+
+func (h *ProductImportHandler) GetImports(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	productImports, err := h.productImportService.GetImports(userID, shopID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, productImports))
+}
+
+func (h *ProductImportHandler) GetImport(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	importID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid import ID", nil))
+	}
+
+	productImport, err := h.productImportService.GetImport(userID, shopID, importID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, productImport))
+}
+
+func (h *ProductImportHandler) GetErrorReport(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	importID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid import ID", nil))
+	}
+
+	report, err := h.productImportService.GetErrorReport(userID, shopID, importID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	fileName := fmt.Sprintf("import-%d-errors.csv", importID)
+	return sendSpreadsheet(c, constants.SpreadsheetCSV, fileName, report)
+}
+
+func (h *ProductImportHandler) ExportProducts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	var query request.ProductExportQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid query parameters", err.Error()))
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+	if query.Format == "" {
+		query.Format = constants.SpreadsheetCSV
+	}
+
+	export, err := h.productImportService.ExportProducts(userID, shopID, query.Format)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	fileName := fmt.Sprintf("produk-toko-%d-%s.%s", shopID, time.Now().Format("20060102"), query.Format)
+	return sendSpreadsheet(c, query.Format, fileName, export)
+}
+
+func sendSpreadsheet(c *fiber.Ctx, format, fileName string, data []byte) error {
+	c.Attachment(fileName)
+	c.Set(fiber.HeaderContentType, utils.SpreadsheetContentType(format))
+	return c.Status(fiber.StatusOK).Send(data)
+}
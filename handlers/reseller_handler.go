@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/services"
+)
+
+type ResellerHandler struct {
+	resellerService services.ResellerService
+	validator       *validator.Validate
+}
+
+func NewResellerHandler(resellerService services.ResellerService) *ResellerHandler {
+	return &ResellerHandler{
+		resellerService: resellerService,
+		validator:       validator.New(),
+	}
+}
+
+func (h *ResellerHandler) Apply(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	var req request.ApplyResellerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	application, err := h.resellerService.Apply(userID, &req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if err.Error() == constants.ErrResellerApplicationExists {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse(constants.MsgResellerApplied, application))
+}
+
+func (h *ResellerHandler) GetMyApplication(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	application, err := h.resellerService.GetMyApplication(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, application))
+}
+
+func (h *ResellerHandler) GetApplications(c *fiber.Ctx) error {
+	var query request.ResellerApplicationQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid query parameters", err.Error()))
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	applications, err := h.resellerService.GetApplications(&query)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, applications))
+}
+
+func (h *ResellerHandler) Approve(c *fiber.Ctx) error {
+	return h.review(c, h.resellerService.Approve, constants.MsgResellerApproved)
+}
+
+func (h *ResellerHandler) Reject(c *fiber.Ctx) error {
+	return h.review(c, h.resellerService.Reject, constants.MsgResellerRejected)
+}
+
+func (h *ResellerHandler) Revoke(c *fiber.Ctx) error {
+	return h.review(c, h.resellerService.Revoke, constants.MsgResellerRevoked)
+}
+
+func (h *ResellerHandler) review(c *fiber.Ctx, decide func(actorID, id int, req *request.ReviewResellerRequest) (*response.ResellerApplicationResponse, error), message string) error {
+	actorID := c.Locals("userID").(int)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid application ID", nil))
+	}
+
+	// The review note is optional, so an empty body is fine
+	var req request.ReviewResellerRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+		}
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	application, err := decide(actorID, id, &req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if err.Error() == constants.ErrResellerApplicationNotFound {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(message, application))
+}
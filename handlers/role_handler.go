@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/services"
+)
+
+type RoleHandler struct {
+	roleService services.RoleService
+	validator   *validator.Validate
+}
+
+func NewRoleHandler(roleService services.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		validator:   validator.New(),
+	}
+}
+
+// GetMyAccess returns the roles and permissions resolved for the current session.
+func (h *RoleHandler) GetMyAccess(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	access, err := h.roleService.ResolveAccess(userID, c.Locals("twoFactor").(bool))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, access))
+}
+
+func (h *RoleHandler) GetListRole(c *fiber.Ctx) error {
+	roles, err := h.roleService.GetListRole()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, roles))
+}
+
+func (h *RoleHandler) GetUserRoles(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid user ID", nil))
+	}
+
+	access, err := h.roleService.GetUserRoles(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, access))
+}
+
+func (h *RoleHandler) SetUserRoles(c *fiber.Ctx) error {
+	actorID := c.Locals("userID").(int)
+
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid user ID", nil))
+	}
+
+	var req request.SetUserRolesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	access, err := h.roleService.SetUserRoles(actorID, userID, &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgRolesUpdated, access))
+}
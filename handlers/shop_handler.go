@@ -23,6 +23,26 @@ func NewShopHandler(shopService services.ShopService) *ShopHandler {
 	}
 }
 
+func (h *ShopHandler) CreateShop(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	var req request.CreateShopRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	shop, err := h.shopService.CreateShop(userID, &req)
+	if err != nil {
+		return c.Status(shopErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse(constants.MsgShopCreated, shop))
+}
+
 func (h *ShopHandler) MyShop(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(int)
 
@@ -57,6 +77,30 @@ func (h *ShopHandler) GetDetailShop(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, shop))
 }
 
+func (h *ShopHandler) GetStorefront(c *fiber.Ctx) error {
+	var query request.ProductListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid query parameters", err.Error()))
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	storefront, err := h.shopService.GetStorefront(c.Params("handle"), &query)
+	if err != nil {
+		switch err.Error() {
+		case constants.ErrShopNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(response.ErrorResponse(err.Error(), nil))
+		case constants.ErrInvalidCursor, constants.ErrInvalidPriceRange:
+			return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse(err.Error(), nil))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, storefront))
+}
+
 func (h *ShopHandler) UpdateProfileShop(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(int)
 
@@ -76,8 +120,158 @@ func (h *ShopHandler) UpdateProfileShop(c *fiber.Ctx) error {
 
 	shop, err := h.shopService.UpdateProfileShop(userID, shopID, &req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse(err.Error(), nil))
+		return c.Status(shopErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopUpdated, shop))
+}
+
+func (h *ShopHandler) UpdateShopAddress(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	var req request.ShopAddressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	shop, err := h.shopService.UpdateAddress(userID, shopID, &req)
+	if err != nil {
+		return c.Status(shopErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopUpdated, shop))
 }
+
+func (h *ShopHandler) UpdateOpeningHours(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	var req request.ShopOpeningHoursRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	shop, err := h.shopService.UpdateOpeningHours(userID, shopID, &req)
+	if err != nil {
+		return c.Status(shopErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopUpdated, shop))
+}
+
+func (h *ShopHandler) SetVacation(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	var req request.ShopVacationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	shop, err := h.shopService.SetVacation(userID, shopID, &req)
+	if err != nil {
+		return c.Status(shopErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopUpdated, shop))
+}
+
+func (h *ShopHandler) UploadLogo(c *fiber.Ctx) error {
+	return h.uploadImage(c, constants.ShopImageLogo)
+}
+
+func (h *ShopHandler) UploadBanner(c *fiber.Ctx) error {
+	return h.uploadImage(c, constants.ShopImageBanner)
+}
+
+func (h *ShopHandler) DeleteLogo(c *fiber.Ctx) error {
+	return h.deleteImage(c, constants.ShopImageLogo)
+}
+
+func (h *ShopHandler) DeleteBanner(c *fiber.Ctx) error {
+	return h.deleteImage(c, constants.ShopImageBanner)
+}
+
+func (h *ShopHandler) uploadImage(c *fiber.Ctx, kind string) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("File not found", nil))
+	}
+	if file.Size > constants.MaxPhotoFileSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(response.ErrorResponse(constants.ErrPhotoTooLarge, nil))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse("Unable to read file", err.Error()))
+	}
+	defer src.Close()
+
+	shop, err := h.shopService.UploadImage(c.UserContext(), userID, shopID, kind, src)
+	if err != nil {
+		if err.Error() == constants.ErrPhotoUploadFailed {
+			return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+		}
+		return c.Status(shopErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopImageUploaded, shop))
+}
+
+func (h *ShopHandler) deleteImage(c *fiber.Ctx, kind string) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	shop, err := h.shopService.DeleteImage(c.UserContext(), userID, shopID, kind)
+	if err != nil {
+		return c.Status(shopErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopImageDeleted, shop))
+}
+
+// shopErrorStatus maps shop creation and profile errors to HTTP statuses.
+func shopErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrShopAlreadyExists, constants.ErrShopHandleTaken:
+		return fiber.StatusConflict
+	default:
+		return shopAccessErrorStatus(err)
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/services"
+)
+
+type ShopMemberHandler struct {
+	shopMemberService services.ShopMemberService
+	validator         *validator.Validate
+}
+
+func NewShopMemberHandler(shopMemberService services.ShopMemberService) *ShopMemberHandler {
+	return &ShopMemberHandler{
+		shopMemberService: shopMemberService,
+		validator:         validator.New(),
+	}
+}
+
+func (h *ShopMemberHandler) GetMyShops(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shops, err := h.shopMemberService.GetMyShops(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, shops))
+}
+
+func (h *ShopMemberHandler) GetMembers(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	members, err := h.shopMemberService.GetMembers(userID, shopID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, members))
+}
+
+func (h *ShopMemberHandler) UpdateMember(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	memberID, err := strconv.Atoi(c.Params("id_user"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid user ID", nil))
+	}
+
+	var req request.UpdateShopMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	member, err := h.shopMemberService.UpdateMember(userID, shopID, memberID, &req)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopMemberUpdated, member))
+}
+
+func (h *ShopMemberHandler) RemoveMember(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	memberID, err := strconv.Atoi(c.Params("id_user"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid user ID", nil))
+	}
+
+	if err := h.shopMemberService.RemoveMember(userID, shopID, memberID); err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopMemberRemoved, nil))
+}
+
+func (h *ShopMemberHandler) Invite(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	var req request.InviteShopMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	invitation, err := h.shopMemberService.Invite(userID, shopID, &req)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse(constants.MsgShopInvitationSent, invitation))
+}
+
+func (h *ShopMemberHandler) GetInvitations(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	invitations, err := h.shopMemberService.GetInvitations(userID, shopID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, invitations))
+}
+
+func (h *ShopMemberHandler) RevokeInvitation(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	invitationID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid invitation ID", nil))
+	}
+
+	if err := h.shopMemberService.RevokeInvitation(userID, shopID, invitationID); err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopInvitationRevoked, nil))
+}
+
+func (h *ShopMemberHandler) AcceptInvitation(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	var req request.RespondShopInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	membership, err := h.shopMemberService.AcceptInvitation(userID, &req)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopInvitationAccepted, membership))
+}
+
+func (h *ShopMemberHandler) DeclineInvitation(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	var req request.RespondShopInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	if err := h.shopMemberService.DeclineInvitation(userID, &req); err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgShopInvitationDeclined, nil))
+}
+
+func shopAccessErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrForbidden, constants.ErrInvitationEmailMismatch:
+		return fiber.StatusForbidden
+	case constants.ErrShopNotFound, constants.ErrShopMemberNotFound, constants.ErrShopInvitationNotFound,
+		constants.ErrProductNotFound, constants.ErrVariantNotFound, constants.ErrProductImportNotFound,
+		constants.ErrPhotoNotFound, constants.ErrPhotoUploadNotFound:
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusBadRequest
+	}
+}
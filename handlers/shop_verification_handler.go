@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/services"
+)
+
+type ShopVerificationHandler struct {
+	shopVerificationService services.ShopVerificationService
+	validator               *validator.Validate
+}
+
+func NewShopVerificationHandler(shopVerificationService services.ShopVerificationService) *ShopVerificationHandler {
+	return &ShopVerificationHandler{
+		shopVerificationService: shopVerificationService,
+		validator:               validator.New(),
+	}
+}
+
+func (h *ShopVerificationHandler) Submit(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	var req request.SubmitShopVerificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	verification, err := h.shopVerificationService.Submit(userID, shopID, &req)
+	if err != nil {
+		return c.Status(shopVerificationErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse(constants.MsgShopVerificationSubmitted, verification))
+}
+
+func (h *ShopVerificationHandler) GetShopVerification(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	verification, err := h.shopVerificationService.GetShopVerification(userID, shopID)
+	if err != nil {
+		return c.Status(shopVerificationErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, verification))
+}
+
+func (h *ShopVerificationHandler) GetVerifications(c *fiber.Ctx) error {
+	var query request.ShopVerificationQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid query parameters", err.Error()))
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	verifications, err := h.shopVerificationService.GetVerifications(&query)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, verifications))
+}
+
+func (h *ShopVerificationHandler) GetVerification(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid verification ID", nil))
+	}
+
+	verification, err := h.shopVerificationService.GetVerification(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, verification))
+}
+
+func (h *ShopVerificationHandler) Approve(c *fiber.Ctx) error {
+	return h.review(c, h.shopVerificationService.Approve, constants.MsgShopVerified)
+}
+
+func (h *ShopVerificationHandler) Reject(c *fiber.Ctx) error {
+	return h.review(c, h.shopVerificationService.Reject, constants.MsgShopVerificationRejected)
+}
+
+func (h *ShopVerificationHandler) review(c *fiber.Ctx, decide func(actorID, id int, req *request.ReviewShopVerificationRequest) (*response.ShopVerificationResponse, error), message string) error {
+	actorID := c.Locals("userID").(int)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid verification ID", nil))
+	}
+
+	// The review note is optional, so an empty body is fine
+	var req request.ReviewShopVerificationRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid request body", err.Error()))
+		}
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Validation failed", err.Error()))
+	}
+
+	verification, err := decide(actorID, id, &req)
+	if err != nil {
+		return c.Status(shopVerificationErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(message, verification))
+}
+
+func shopVerificationErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrShopVerificationExists:
+		return fiber.StatusConflict
+	case constants.ErrShopVerificationNotFound:
+		return fiber.StatusNotFound
+	default:
+		return shopAccessErrorStatus(err)
+	}
+}
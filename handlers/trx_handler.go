@@ -50,6 +50,22 @@ func (h *TRXHandler) GetDetailTRX(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, trx))
 }
 
+func (h *TRXHandler) GetShopTRX(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	shopID, err := strconv.Atoi(c.Params("id_toko"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid shop ID", nil))
+	}
+
+	trxs, err := h.trxService.GetShopTRX(userID, shopID)
+	if err != nil {
+		return c.Status(shopAccessErrorStatus(err)).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgDataRetrieved, trxs))
+}
+
 func (h *TRXHandler) CreateTRX(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(int)
 
@@ -86,3 +102,30 @@ func (h *TRXHandler) CheckPayment(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse("Payment status checked successfully", trx))
 }
+
+// CompleteTRX lets the buyer confirm a paid order arrived, releasing the
+// shops' held funds
+func (h *TRXHandler) CompleteTRX(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(int)
+
+	trxID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorResponse("Invalid transaction ID", nil))
+	}
+
+	trx, err := h.trxService.CompleteTRX(userID, trxID)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch err.Error() {
+		case constants.ErrTRXNotFound:
+			status = fiber.StatusNotFound
+		case constants.ErrForbidden:
+			status = fiber.StatusForbidden
+		case constants.ErrTRXAlreadyCompleted:
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(response.ErrorResponse(err.Error(), nil))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessResponse(constants.MsgTRXCompleted, trx))
+}
@@ -1,17 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/rdsarjito/marketplace-backend/config"
+	"github.com/rdsarjito/marketplace-backend/constants"
 	"github.com/rdsarjito/marketplace-backend/handlers"
 	"github.com/rdsarjito/marketplace-backend/middleware"
+	"github.com/rdsarjito/marketplace-backend/oauth"
 	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/search"
 	"github.com/rdsarjito/marketplace-backend/services"
 	"github.com/rdsarjito/marketplace-backend/storage"
 )
@@ -23,6 +28,8 @@ func main() {
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
+		// Leave room for product import files plus multipart overhead
+		BodyLimit: constants.ProductImportMaxFileSize + 1<<20,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
@@ -52,38 +59,113 @@ func main() {
 	categoryRepository := repositories.NewCategoryRepository(db)
 	addressRepository := repositories.NewAddressRepository(db)
 	productRepository := repositories.NewProductRepository(db)
+	productVariantRepository := repositories.NewProductVariantRepository(db)
+	logProductRepository := repositories.NewLogProductRepository(db)
+	productImportRepository := repositories.NewProductImportRepository(db)
+	photoUploadRepository := repositories.NewPhotoUploadRepository(db)
+	privateMediaRepository := repositories.NewPrivateMediaRepository(db)
 	trxRepository := repositories.NewTRXRepository(db)
+	otpRepository := repositories.NewOTPRepository(db)
+	twoFactorRepository := repositories.NewTwoFactorRepository(db)
+	identityRepository := repositories.NewIdentityRepository(db)
+	loginAttemptRepository := repositories.NewLoginAttemptRepository(db)
+	roleRepository := repositories.NewRoleRepository(db)
+	shopMemberRepository := repositories.NewShopMemberRepository(db)
+	resellerRepository := repositories.NewResellerRepository(db)
+	shopVerificationRepository := repositories.NewShopVerificationRepository(db)
+	ledgerRepository := repositories.NewLedgerRepository(db)
+	payoutRepository := repositories.NewPayoutRepository(db)
+
+	// Initialize OAuth providers (optional)
+	var oauthProviders []oauth.Provider
+	if googleProvider, err := oauth.NewGoogleProviderFromEnv(); err != nil {
+		log.Printf("Google login disabled: %v", err)
+	} else {
+		oauthProviders = append(oauthProviders, googleProvider)
+	}
+	oauthRegistry := oauth.NewRegistry(oauthProviders...)
 
 	// Initialize shared services
 	emailService := services.NewEmailService()
+	smsSender := services.NewSMSSenderFromEnv()
 	midtransService := services.NewMidtransService(cfg.MidtransServerKey, cfg.MidtransClientKey, cfg.MidtransIsProduction)
-	authService := services.NewAuthService(userRepository, shopRepository, provinceCityRepository, emailService)
+	roleService := services.NewRoleService(roleRepository, userRepository)
+	if err := roleService.SeedDefaults(); err != nil {
+		log.Fatal("Error seeding roles: ", err)
+	}
+	loginThrottleService := services.NewLoginThrottleService(loginAttemptRepository)
+	twoFactorService := services.NewTwoFactorService(twoFactorRepository, userRepository, roleRepository, loginThrottleService)
+	authService := services.NewAuthService(userRepository, provinceCityRepository, emailService, twoFactorService, loginThrottleService)
 	userService := services.NewUserService(userRepository, addressRepository)
+	otpService := services.NewOTPService(otpRepository, userRepository, smsSender, twoFactorService)
 	categoryService := services.NewCategoryService(categoryRepository)
-	shopService := services.NewShopService(shopRepository)
-	productService := services.NewProductService(productRepository, shopRepository, categoryRepository)
-	trxService := services.NewTRXService(trxRepository, productRepository, addressRepository, shopRepository, categoryRepository, userRepository, midtransService, emailService, cfg.FrontendURL)
-	mediaStorage, err := storage.NewMinioStorageFromEnv()
+	shopMemberService := services.NewShopMemberService(shopMemberRepository, shopRepository, userRepository, emailService)
+	resellerService := services.NewResellerService(resellerRepository, roleRepository, emailService)
+	searchIndex, err := search.NewSearchIndexFromEnv(db)
 	if err != nil {
-		log.Fatal(err)
+		log.Fatal("Error opening search index: ", err)
+	}
+	defer searchIndex.Close()
+	mediaStorage, err := storage.NewMediaStorageFromEnv()
+	if err != nil {
+		log.Fatal("Error opening media storage: ", err)
+	}
+	mediaService := services.NewMediaService(mediaStorage, privateMediaRepository)
+	shopVerificationService := services.NewShopVerificationService(shopVerificationRepository, privateMediaRepository, productRepository, shopMemberService, mediaService, emailService, services.UnverifiedShopLimits{
+		MaxProducts:  cfg.UnverifiedShopMaxProducts,
+		AllowPayouts: cfg.UnverifiedShopPayouts,
+	})
+	productService := services.NewProductService(productRepository, productVariantRepository, logProductRepository, shopRepository, categoryRepository, shopMemberService, shopVerificationService, searchIndex, mediaStorage)
+	shopService := services.NewShopService(shopRepository, provinceCityRepository, shopMemberService, productService, mediaStorage)
+	photoUploadService := services.NewPhotoUploadService(photoUploadRepository, productRepository, shopMemberService, productService, mediaStorage)
+	go photoUploadService.RunCleanup(context.Background(), constants.PhotoUploadCleanupInterval)
+	productImportService := services.NewProductImportService(productImportRepository, productRepository, categoryRepository, productService, shopMemberService)
+	if err := productImportService.FailInterrupted(); err != nil {
+		log.Fatal("Error cleaning up product imports: ", err)
 	}
+	oauthService := services.NewOAuthService(oauthRegistry, identityRepository, userRepository, twoFactorService)
+	ledgerService := services.NewLedgerService(ledgerRepository, trxRepository, shopMemberService, shopVerificationService, services.LedgerOptions{
+		CommissionBps: cfg.PlatformCommissionBps,
+		HoldPeriod:    time.Duration(cfg.SettlementHoldDays) * 24 * time.Hour,
+	})
+	go ledgerService.RunSettlement(context.Background(), constants.SettlementReleaseInterval)
+	payoutService := services.NewPayoutService(payoutRepository, shopMemberService, shopVerificationService)
+	trxService := services.NewTRXService(trxRepository, productRepository, logProductRepository, addressRepository, shopRepository, categoryRepository, userRepository, midtransService, emailService, shopMemberService, roleService, ledgerService, cfg.FrontendURL)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	userHandler := handlers.NewUserHandler(userService)
+	otpHandler := handlers.NewOTPHandler(otpService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	oauthHandler := handlers.NewOAuthHandler(oauthService, cfg.FrontendURL)
+	roleHandler := handlers.NewRoleHandler(roleService)
 	provinceCityHandler := handlers.NewProvinceCityHandler(provinceCityRepository)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	shopHandler := handlers.NewShopHandler(shopService)
-	productHandler := handlers.NewProductHandler(productService, mediaStorage)
+	shopMemberHandler := handlers.NewShopMemberHandler(shopMemberService)
+	resellerHandler := handlers.NewResellerHandler(resellerService)
+	shopVerificationHandler := handlers.NewShopVerificationHandler(shopVerificationService)
+	productHandler := handlers.NewProductHandler(productService)
+	productImportHandler := handlers.NewProductImportHandler(productImportService)
+	photoUploadHandler := handlers.NewPhotoUploadHandler(photoUploadService)
+	mediaHandler := handlers.NewMediaHandler(mediaService, mediaStorage)
 	trxHandler := handlers.NewTRXHandler(trxService)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerService)
+	payoutHandler := handlers.NewPayoutHandler(payoutService)
 	paymentHandler := handlers.NewPaymentHandler(trxService, userService)
 
 	// Initialize middleware
-	authMiddleware := middleware.AuthMiddleware(userService)
+	authMiddleware := middleware.AuthMiddleware(userService, roleService)
+	canManageCategories := middleware.RequirePermission(constants.PermCategoryManage)
+	canManageRoles := middleware.RequirePermission(constants.PermRoleManage)
+	canManageResellers := middleware.RequirePermission(constants.PermResellerManage)
+	canReadPrivateMedia := middleware.RequirePermission(constants.PermMediaPrivate)
+	canVerifyShops := middleware.RequirePermission(constants.PermShopVerify)
+	canManagePayouts := middleware.RequirePermission(constants.PermPayoutManage)
 
 	// Media serving route - handle all requests to /media
 	// This route serves product images from MinIO storage
-	app.Use("/media", productHandler.ServeMedia)
+	app.Use("/media", mediaHandler.ServeMedia)
 
 	// API routes
 	api := app.Group("/api/v1")
@@ -93,8 +175,13 @@ func main() {
 	api.Post("/auth/login", authHandler.LoginUser)
 	api.Post("/auth/forgot-password", authHandler.ForgotPassword)
 	api.Post("/auth/reset-password", authHandler.ResetPassword)
-	api.Get("/auth/google", authHandler.GoogleLogin)
-	api.Get("/auth/google/callback", authHandler.GoogleCallback)
+	api.Post("/auth/unlock", authHandler.UnlockAccount)
+	api.Post("/auth/oauth/exchange", oauthHandler.Exchange)
+	api.Get("/auth/:provider", oauthHandler.Start)
+	api.Get("/auth/:provider/callback", oauthHandler.Callback)
+	api.Post("/auth/otp/request", otpHandler.RequestLoginOTP)
+	api.Post("/auth/otp/login", otpHandler.LoginWithOTP)
+	api.Post("/auth/2fa/verify", twoFactorHandler.VerifyLogin)
 
 	// Province & City routes (public)
 	api.Get("/provcity/listprovincies", provinceCityHandler.GetListProvince)
@@ -102,6 +189,9 @@ func main() {
 	api.Get("/provcity/listcities/:prov_id", provinceCityHandler.GetListCity)
 	api.Get("/provcity/detailcity/:city_id", provinceCityHandler.GetDetailCity)
 
+	// Shop storefront (public)
+	api.Get("/toko/by-handle/:handle", shopHandler.GetStorefront)
+
 	// Payment webhook (public - Midtrans will POST to this endpoint)
 	api.Post("/payment/webhook", paymentHandler.HandleWebhook)
 
@@ -114,6 +204,23 @@ func main() {
 	// User routes
 	api.Get("/user", userHandler.GetMyProfile)
 	api.Put("/user", userHandler.UpdateProfile)
+	api.Post("/user/phone/otp", otpHandler.RequestPhoneVerification)
+	api.Post("/user/phone/verify", otpHandler.VerifyPhone)
+	api.Get("/user/2fa", twoFactorHandler.GetStatus)
+	api.Post("/user/2fa/setup", twoFactorHandler.Setup)
+	api.Post("/user/2fa/enable", twoFactorHandler.Enable)
+	api.Post("/user/2fa/disable", twoFactorHandler.Disable)
+	api.Post("/user/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+	api.Get("/user/permissions", roleHandler.GetMyAccess)
+	api.Get("/user/identities", oauthHandler.ListIdentities)
+	api.Post("/user/identities/:provider/link", oauthHandler.StartLink)
+	api.Post("/user/identities/:provider/link/confirm", oauthHandler.ConfirmLink)
+	api.Delete("/user/identities/:provider", oauthHandler.Unlink)
+	api.Get("/user/shops", shopMemberHandler.GetMyShops)
+	api.Post("/user/shop-invitations/accept", shopMemberHandler.AcceptInvitation)
+	api.Post("/user/shop-invitations/decline", shopMemberHandler.DeclineInvitation)
+	api.Get("/user/reseller", resellerHandler.GetMyApplication)
+	api.Post("/user/reseller", resellerHandler.Apply)
 	api.Get("/user/alamat", userHandler.GetMyAddress)
 	api.Get("/user/alamat/:id", userHandler.GetDetailAddress)
 	api.Post("/user/alamat", userHandler.CreateAddressUser)
@@ -123,15 +230,65 @@ func main() {
 	// Category routes
 	api.Get("/category", categoryHandler.GetListCategory)
 	api.Get("/category/:id", categoryHandler.GetDetailCategory)
-	api.Post("/category", categoryHandler.CreateCategory)
-	api.Put("/category/:id", categoryHandler.UpdateCategory)
-	api.Delete("/category/:id", categoryHandler.DeleteCategory)
+	api.Post("/category", canManageCategories, categoryHandler.CreateCategory)
+	api.Put("/category/:id", canManageCategories, categoryHandler.UpdateCategory)
+	api.Delete("/category/:id", canManageCategories, categoryHandler.DeleteCategory)
+
+	// Role management routes
+	api.Get("/admin/roles", canManageRoles, roleHandler.GetListRole)
+	api.Get("/admin/users/:id/roles", canManageRoles, roleHandler.GetUserRoles)
+	api.Put("/admin/users/:id/roles", canManageRoles, roleHandler.SetUserRoles)
+
+	// Reseller program routes
+	api.Get("/admin/reseller-applications", canManageResellers, resellerHandler.GetApplications)
+	api.Post("/admin/reseller-applications/:id/approve", canManageResellers, resellerHandler.Approve)
+	api.Post("/admin/reseller-applications/:id/reject", canManageResellers, resellerHandler.Reject)
+	api.Post("/admin/reseller-applications/:id/revoke", canManageResellers, resellerHandler.Revoke)
+	api.Get("/admin/media/private/:id", canReadPrivateMedia, mediaHandler.GetPrivateMediaForStaff)
+
+	// Shop verification review routes
+	api.Get("/admin/shop-verifications", canVerifyShops, shopVerificationHandler.GetVerifications)
+	api.Get("/admin/shop-verifications/:id", canVerifyShops, shopVerificationHandler.GetVerification)
+	api.Post("/admin/shop-verifications/:id/approve", canVerifyShops, shopVerificationHandler.Approve)
+	api.Post("/admin/shop-verifications/:id/reject", canVerifyShops, shopVerificationHandler.Reject)
+	api.Get("/admin/payouts", canManagePayouts, payoutHandler.GetPayouts)
+	api.Post("/admin/payouts/:id/approve", canManagePayouts, payoutHandler.Approve)
+	api.Post("/admin/payouts/:id/reject", canManagePayouts, payoutHandler.Reject)
+	api.Get("/admin/ledger/reconciliation", canManagePayouts, ledgerHandler.GetReconciliation)
 
 	// Shop routes
+	api.Post("/toko", shopHandler.CreateShop)
 	api.Get("/toko/my", shopHandler.MyShop)
 	api.Get("/toko", shopHandler.GetListShop)
 	api.Get("/toko/:id_toko", shopHandler.GetDetailShop)
 	api.Put("/toko/:id_toko", shopHandler.UpdateProfileShop)
+	api.Put("/toko/:id_toko/alamat", shopHandler.UpdateShopAddress)
+	api.Put("/toko/:id_toko/jam-operasional", shopHandler.UpdateOpeningHours)
+	api.Put("/toko/:id_toko/libur", shopHandler.SetVacation)
+	api.Post("/toko/:id_toko/logo", shopHandler.UploadLogo)
+	api.Delete("/toko/:id_toko/logo", shopHandler.DeleteLogo)
+	api.Post("/toko/:id_toko/banner", shopHandler.UploadBanner)
+	api.Delete("/toko/:id_toko/banner", shopHandler.DeleteBanner)
+	api.Get("/toko/:id_toko/verifikasi", shopVerificationHandler.GetShopVerification)
+	api.Post("/toko/:id_toko/verifikasi", shopVerificationHandler.Submit)
+	api.Get("/toko/:id_toko/trx", trxHandler.GetShopTRX)
+	api.Get("/toko/:id_toko/saldo", ledgerHandler.GetShopBalance)
+	api.Get("/toko/:id_toko/saldo/mutasi", ledgerHandler.GetShopLedger)
+	api.Get("/toko/:id_toko/rekening", payoutHandler.GetBankAccount)
+	api.Put("/toko/:id_toko/rekening", payoutHandler.SaveBankAccount)
+	api.Get("/toko/:id_toko/penarikan", payoutHandler.GetShopPayouts)
+	api.Post("/toko/:id_toko/penarikan", payoutHandler.RequestPayout)
+	api.Get("/toko/:id_toko/members", shopMemberHandler.GetMembers)
+	api.Put("/toko/:id_toko/members/:id_user", shopMemberHandler.UpdateMember)
+	api.Delete("/toko/:id_toko/members/:id_user", shopMemberHandler.RemoveMember)
+	api.Get("/toko/:id_toko/invitations", shopMemberHandler.GetInvitations)
+	api.Post("/toko/:id_toko/invitations", shopMemberHandler.Invite)
+	api.Delete("/toko/:id_toko/invitations/:id", shopMemberHandler.RevokeInvitation)
+	api.Get("/toko/:id_toko/products/export", productImportHandler.ExportProducts)
+	api.Get("/toko/:id_toko/products/imports", productImportHandler.GetImports)
+	api.Post("/toko/:id_toko/products/imports", productImportHandler.StartImport)
+	api.Get("/toko/:id_toko/products/imports/:id", productImportHandler.GetImport)
+	api.Get("/toko/:id_toko/products/imports/:id/errors", productImportHandler.GetErrorReport)
 
 	// Product routes
 	api.Get("/product", productHandler.GetListProduct)
@@ -139,13 +296,28 @@ func main() {
 	api.Post("/product", productHandler.CreateProduct)
 	api.Put("/product/:id", productHandler.UpdateProduct)
 	api.Delete("/product/:id", productHandler.DeleteProduct)
+	api.Get("/product/:id/history", productHandler.GetHistory)
 	api.Post("/product/:id/photo", productHandler.UploadProductPhoto)
+	api.Post("/product/:id/photos/uploads", photoUploadHandler.CreateUpload)
+	api.Post("/product/:id/photos/uploads/:id_upload/confirm", photoUploadHandler.ConfirmUpload)
+	api.Put("/product/:id/photos/order", productHandler.ReorderPhotos)
+	api.Put("/product/:id/photos/:id_foto/primary", productHandler.SetPrimaryPhoto)
+	api.Delete("/product/:id/photos/:id_foto", productHandler.DeleteProductPhoto)
+	api.Post("/product/:id/variants", productHandler.CreateVariant)
+	api.Put("/product/:id/variants/:id_variant", productHandler.UpdateVariant)
+	api.Delete("/product/:id/variants/:id_variant", productHandler.DeleteVariant)
+
+	// Private media routes
+	api.Post("/media/private", mediaHandler.UploadPrivateMedia)
+	api.Get("/media/private/:id", mediaHandler.GetPrivateMedia)
+	api.Delete("/media/private/:id", mediaHandler.DeletePrivateMedia)
 
 	// Transaction routes
 	api.Get("/trx", trxHandler.GetListTRX)
 	api.Get("/trx/:id", trxHandler.GetDetailTRX)
 	api.Post("/trx", trxHandler.CreateTRX)
 	api.Post("/trx/:id/check-payment", trxHandler.CheckPayment)
+	api.Post("/trx/:id/selesai", trxHandler.CompleteTRX)
 
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
@@ -0,0 +1,205 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedImage = errors.New(constants.ErrUnsupportedImage)
+	ErrImageTooLarge    = errors.New(constants.ErrImageTooLarge)
+)
+
+// Image is one encoded version of an uploaded image.
+type Image struct {
+	Name        string
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+type rendition struct {
+	name    string
+	maxSize int
+	webp    bool
+}
+
+var renditions = []rendition{
+	{name: constants.PhotoThumbnail, maxSize: constants.PhotoThumbnailSize},
+	{name: constants.PhotoMedium, maxSize: constants.PhotoMediumSize},
+	{name: constants.PhotoLarge, maxSize: constants.PhotoLargeSize},
+	{name: constants.PhotoWebP, maxSize: constants.PhotoLargeSize, webp: true},
+}
+
+// Formats accepted for upload, as sniffed from the content
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// IsSupportedType reports whether images of contentType can be uploaded.
+func IsSupportedType(contentType string) bool {
+	return imageTypes[contentType]
+}
+
+// ProcessImage checks that data is a JPEG, PNG, GIF or WebP image, going by
+// its content rather than the name or type it was uploaded with, and returns
+// the original followed by the resized versions. Every version is decoded
+// and re-encoded, which drops EXIF and other metadata; JPEGs are rotated
+// upright first. Images with transparency are kept as PNG, others become
+// JPEG, and only the first frame of an animated GIF is kept.
+func ProcessImage(data []byte) ([]Image, error) {
+	img, _, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	opaque := isOpaque(img)
+
+	original, err := encode(constants.PhotoOriginal, img, opaque)
+	if err != nil {
+		return nil, err
+	}
+	images := []Image{original}
+
+	for _, r := range renditions {
+		resized := resize(img, r.maxSize, r.maxSize)
+		var encoded Image
+		if r.webp {
+			encoded, err = encodeWebP(r.name, resized)
+		} else {
+			encoded, err = encode(r.name, resized, opaque)
+		}
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, encoded)
+	}
+	return images, nil
+}
+
+// decode checks and decodes an image, turning JPEGs upright. It returns the
+// sniffed content type.
+func decode(data []byte) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	if !imageTypes[contentType] {
+		return nil, "", ErrUnsupportedImage
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	if config.Width*config.Height > constants.MaxPhotoPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, contentType, nil
+}
+
+func encode(name string, img image.Image, opaque bool) (Image, error) {
+	format := FormatPNG
+	if opaque {
+		format = FormatJPEG
+	}
+	encoded, err := encodeAs(format, img, constants.PhotoJPEGQuality)
+	encoded.Name = name
+	return encoded, err
+}
+
+// encodeWebP encodes img as lossless WebP, the only kind that can be
+// written without libwebp.
+func encodeWebP(name string, img image.Image) (Image, error) {
+	encoded, err := encodeAs(FormatWebP, img, 0)
+	encoded.Name = name
+	return encoded, err
+}
+
+// encodeAs encodes img in one of the output formats. quality only applies to
+// JPEG, which has no transparency, so transparent areas become white.
+func encodeAs(format string, img image.Image, quality int) (Image, error) {
+	var buf bytes.Buffer
+	encoded := Image{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+	switch format {
+	case FormatJPEG:
+		if !isOpaque(img) {
+			img = flatten(img)
+		}
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return Image{}, err
+		}
+		encoded.ContentType, encoded.Extension = "image/jpeg", ".jpg"
+	case FormatPNG:
+		if err := png.Encode(&buf, img); err != nil {
+			return Image{}, err
+		}
+		encoded.ContentType, encoded.Extension = "image/png", ".png"
+	case FormatWebP:
+		if err := nativewebp.Encode(&buf, img, nil); err != nil {
+			return Image{}, err
+		}
+		encoded.ContentType, encoded.Extension = "image/webp", ".webp"
+	default:
+		return Image{}, ErrUnsupportedImage
+	}
+	encoded.Data = buf.Bytes()
+	return encoded, nil
+}
+
+// resize scales img down to fit in a maxWidth by maxHeight box, keeping its
+// aspect ratio. Smaller images are returned as they are.
+func resize(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxWidth && height <= maxHeight {
+		return img
+	}
+
+	// Scale by whichever side overflows the box the most
+	if width*maxHeight >= height*maxWidth {
+		height = max(1, height*maxWidth/width)
+		width = maxWidth
+	} else {
+		width = max(1, width*maxHeight/height)
+		height = maxHeight
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// flatten draws img over a white background.
+func flatten(img image.Image) image.Image {
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
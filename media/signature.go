@@ -0,0 +1,48 @@
+package media
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Sign returns a URL-safe signature of message keyed with SECRET_KEY.
+func Sign(message string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SECRET_KEY")))
+	mac.Write([]byte("media:" + message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ValidSignature reports whether signature was made by Sign for message.
+func ValidSignature(message, signature string) bool {
+	return signature != "" && hmac.Equal([]byte(Sign(message)), []byte(signature))
+}
+
+// SignTransform signs a transformation of an object. The signature goes in
+// the s query parameter of its /media URL.
+func SignTransform(objectName string, t Transform) string {
+	return Sign(objectName + "?" + t.String())
+}
+
+// SignExpiring signs access to objectName until expires. The signature goes
+// in the s query parameter, next to expires as a Unix time.
+func SignExpiring(objectName string, expires time.Time) string {
+	return Sign(objectName + "?expires=" + strconv.FormatInt(expires.Unix(), 10))
+}
+
+// CheckExpiring verifies the expires and s query parameters of a URL for
+// objectName and returns when it expires.
+func CheckExpiring(objectName, expires, signature string) (time.Time, bool) {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if !ValidSignature(objectName+"?expires="+expires, signature) {
+		return time.Time{}, false
+	}
+	expiresAt := time.Unix(unix, 0)
+	return expiresAt, time.Now().Before(expiresAt)
+}
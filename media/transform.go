@@ -0,0 +1,227 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"golang.org/x/image/draw"
+)
+
+// Output formats of a Transform
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// How a Transform fits an image in its box
+const (
+	// FitContain scales the image to fit inside the box.
+	FitContain = "contain"
+	// FitCover scales the image to fill the box and crops what sticks out,
+	// keeping the centre.
+	FitCover = "cover"
+)
+
+// ErrInvalidTransform is returned for transformation parameters that are
+// unknown or out of range.
+var ErrInvalidTransform = errors.New(constants.ErrInvalidTransform)
+
+// Transform describes a resized or re-encoded version of an image. A zero
+// Width or Height leaves that side unbounded, an empty Fit means FitContain,
+// an empty Format keeps the format of the original and a zero Quality uses
+// PhotoJPEGQuality. Images are never enlarged.
+type Transform struct {
+	Width   int
+	Height  int
+	Fit     string
+	Format  string
+	Quality int
+}
+
+// Presets are the transformations that can be requested without a
+// signature, by name.
+var Presets = map[string]Transform{
+	"thumb":  {Width: 150, Height: 150, Fit: FitCover},
+	"card":   {Width: 400, Height: 400, Fit: FitCover, Format: FormatWebP},
+	"detail": {Width: 1000, Height: 1000, Fit: FitContain},
+	"zoom":   {Width: 2000, Height: 2000, Fit: FitContain},
+}
+
+// Query parameters of a transformation
+var transformParams = map[string]bool{"w": true, "h": true, "fit": true, "fm": true, "q": true}
+
+// IsTransformParam reports whether a query parameter describes a
+// transformation.
+func IsTransformParam(name string) bool {
+	return transformParams[name]
+}
+
+// ParseTransform reads a transformation from the w, h, fit, fm and q query
+// parameters. Other parameters are ignored.
+func ParseTransform(params map[string]string) (Transform, error) {
+	var t Transform
+	var err error
+	if t.Width, err = parseDimension(params["w"]); err != nil {
+		return Transform{}, err
+	}
+	if t.Height, err = parseDimension(params["h"]); err != nil {
+		return Transform{}, err
+	}
+	t.Fit = params["fit"]
+	t.Format = params["fm"]
+	if value := params["q"]; value != "" {
+		t.Quality, err = strconv.Atoi(value)
+		if err != nil {
+			return Transform{}, ErrInvalidTransform
+		}
+	}
+
+	if err := t.validate(); err != nil {
+		return Transform{}, err
+	}
+	return t, nil
+}
+
+func parseDimension(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > constants.MaxTransformSize {
+		return 0, ErrInvalidTransform
+	}
+	return n, nil
+}
+
+func (t Transform) validate() error {
+	if t.Width == 0 && t.Height == 0 && t.Format == "" {
+		return ErrInvalidTransform
+	}
+	switch t.Fit {
+	case "":
+	case FitContain:
+	case FitCover:
+		if t.Width == 0 || t.Height == 0 {
+			return ErrInvalidTransform
+		}
+	default:
+		return ErrInvalidTransform
+	}
+	switch t.Format {
+	case "", FormatJPEG, FormatPNG, FormatWebP:
+	default:
+		return ErrInvalidTransform
+	}
+	if t.Quality != 0 && (t.Quality < 1 || t.Quality > 100) {
+		return ErrInvalidTransform
+	}
+	return nil
+}
+
+// String returns the transformation as query parameters in a fixed order,
+// which is what signatures are computed over.
+func (t Transform) String() string {
+	var parts []string
+	if t.Width > 0 {
+		parts = append(parts, "w="+strconv.Itoa(t.Width))
+	}
+	if t.Height > 0 {
+		parts = append(parts, "h="+strconv.Itoa(t.Height))
+	}
+	if t.Fit != "" {
+		parts = append(parts, "fit="+t.Fit)
+	}
+	if t.Format != "" {
+		parts = append(parts, "fm="+t.Format)
+	}
+	if t.Quality > 0 {
+		parts = append(parts, "q="+strconv.Itoa(t.Quality))
+	}
+	return strings.Join(parts, "&")
+}
+
+// Key identifies the transformation in object names, e.g. w400_h400_cover.
+func (t Transform) Key() string {
+	return strings.NewReplacer("fit=", "", "fm=", "", "=", "", "&", "_").Replace(t.String())
+}
+
+// Apply decodes an image and returns it transformed.
+func (t Transform) Apply(data []byte) (Image, error) {
+	if err := t.validate(); err != nil {
+		return Image{}, err
+	}
+	img, contentType, err := decode(data)
+	if err != nil {
+		return Image{}, err
+	}
+
+	width, height := t.Width, t.Height
+	bounds := img.Bounds()
+	if width == 0 {
+		width = bounds.Dx()
+	}
+	if height == 0 {
+		height = bounds.Dy()
+	}
+	if t.Fit == FitCover {
+		img = cover(img, width, height)
+	} else {
+		img = resize(img, width, height)
+	}
+
+	format := t.Format
+	if format == "" {
+		format = defaultFormat(contentType)
+	}
+	quality := t.Quality
+	if quality == 0 {
+		quality = constants.PhotoJPEGQuality
+	}
+
+	encoded, err := encodeAs(format, img, quality)
+	if err != nil {
+		return Image{}, fmt.Errorf("encoding %s: %w", format, err)
+	}
+	encoded.Name = t.Key()
+	return encoded, nil
+}
+
+// defaultFormat is the output format for an image of contentType when the
+// transformation does not ask for one.
+func defaultFormat(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return FormatJPEG
+	case "image/webp":
+		return FormatWebP
+	default:
+		return FormatPNG
+	}
+}
+
+// cover crops the largest centred part of img with the aspect ratio of the
+// width by height box and scales it down to the box if it is larger.
+func cover(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	cropWidth, cropHeight := bounds.Dx(), bounds.Dy()
+	if cropWidth*height > cropHeight*width {
+		cropWidth = max(1, cropHeight*width/height)
+	} else {
+		cropHeight = max(1, cropWidth*height/width)
+	}
+	x := bounds.Min.X + (bounds.Dx()-cropWidth)/2
+	y := bounds.Min.Y + (bounds.Dy()-cropHeight)/2
+	crop := image.Rect(x, y, x+cropWidth, y+cropHeight)
+
+	if cropWidth < width {
+		width, height = cropWidth, cropHeight
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+	return dst
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,7 +11,7 @@ import (
 	"github.com/rdsarjito/marketplace-backend/utils"
 )
 
-func AuthMiddleware(userService services.UserService) fiber.Handler {
+func AuthMiddleware(userService services.UserService, roleService services.RoleService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get Authorization header
 		authHeader := c.Get("Authorization")
@@ -41,10 +42,19 @@ func AuthMiddleware(userService services.UserService) fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponse(constants.ErrUserNotFound, nil))
 		}
 
+		// Resolve roles and permissions from the database so changes apply immediately
+		access, err := roleService.ResolveAccess(claims.UserID, claims.TwoFactor)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponse(err.Error(), nil))
+		}
+
 		// Set user data in context
 		c.Locals("userID", claims.UserID)
 		c.Locals("isAdmin", claims.IsAdmin)
+		c.Locals("twoFactor", claims.TwoFactor)
 		c.Locals("user", user)
+		c.Locals("roles", access.Roles)
+		c.Locals("permissions", access.Permissions)
 
 		return c.Next()
 	}
@@ -59,3 +69,17 @@ func AdminMiddleware() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// RequirePermission allows the request only when the user holds every listed
+// permission. It must run after AuthMiddleware.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		granted, _ := c.Locals("permissions").([]string)
+		for _, permission := range permissions {
+			if !slices.Contains(granted, permission) {
+				return c.Status(fiber.StatusForbidden).JSON(response.ErrorResponse(constants.ErrForbidden, nil))
+			}
+		}
+		return c.Next()
+	}
+}
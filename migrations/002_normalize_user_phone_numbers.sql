@@ -0,0 +1,28 @@
+-- Migration: Normalize user phone numbers to E.164 and add verification column
+-- Description: Phone numbers are now stored as E.164 (+62...) so the uniqueness
+-- check is not bypassed by formatting differences. Rows that collide after
+-- normalization are left untouched and listed by the final SELECT for manual review.
+
+ALTER TABLE user
+ADD COLUMN notelp_verified_at TIMESTAMP NULL AFTER notelp;
+
+-- Strip common separators
+UPDATE user
+SET notelp = REPLACE(REPLACE(REPLACE(REPLACE(notelp, ' ', ''), '-', ''), '(', ''), ')', '')
+WHERE notelp NOT LIKE 'google-%';
+
+-- 08xxxxxxxx -> +628xxxxxxxx
+UPDATE user u
+LEFT JOIN user other ON other.notelp = CONCAT('+62', SUBSTRING(u.notelp, 2))
+SET u.notelp = CONCAT('+62', SUBSTRING(u.notelp, 2))
+WHERE u.notelp LIKE '0%' AND other.id IS NULL;
+
+-- 62xxxxxxxxxx -> +62xxxxxxxxxx
+UPDATE user u
+LEFT JOIN user other ON other.notelp = CONCAT('+', u.notelp)
+SET u.notelp = CONCAT('+', u.notelp)
+WHERE u.notelp LIKE '62%' AND other.id IS NULL;
+
+-- Report numbers that could not be normalized
+SELECT id, email, notelp FROM user
+WHERE notelp NOT LIKE '+%' AND notelp NOT LIKE 'google-%';
@@ -0,0 +1,59 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Identity is the subset of the provider's user info needed to sign a user in.
+type Identity struct {
+	Provider      string
+	Subject       string // Stable provider user ID (e.g. Google "sub")
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Provider is an OAuth 2.0 / OpenID Connect identity provider using the
+// authorization code flow with PKCE.
+type Provider interface {
+	Name() string
+	AuthCodeURL(state, codeChallenge, nonce string) string
+	// Exchange redeems the authorization code and checks that the ID token
+	// was issued for the nonce sent with the authorization request.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
+
+// Registry holds the configured providers by name.
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[string]Provider)}
+	for _, p := range providers {
+		if p != nil {
+			r.providers[p.Name()] = p
+		}
+	}
+	return r
+}
+
+func (r *Registry) Get(name string) (Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("oauth provider %q is not configured", name)
+	}
+	return p, nil
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
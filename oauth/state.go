@@ -0,0 +1,162 @@
+package oauth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/utils"
+)
+
+// StateTTL bounds how long a user may take on the provider's consent screen.
+const StateTTL = 10 * time.Minute
+
+// PendingLinkTTL bounds how long the frontend may take to confirm a link.
+const PendingLinkTTL = 5 * time.Minute
+
+// linkStatePrefix marks a state parameter that carries a sealed link flow
+const linkStatePrefix = "link."
+
+// FlowState is kept in an encrypted, HttpOnly cookie for the duration of a
+// sign-in flow. It binds the callback to the browser that started it.
+//
+// Link flows are started by a signed-in user through the API, so there is no
+// browser to bind to: their state travels sealed in the state parameter, and
+// the callback only yields a PendingLink that the same user has to confirm.
+type FlowState struct {
+	Provider     string    `json:"p"`
+	State        string    `json:"s"`
+	CodeVerifier string    `json:"v"`
+	Nonce        string    `json:"n"`
+	LinkUserID   int       `json:"l,omitempty"` // Set for link flows
+	ExpiresAt    time.Time `json:"e"`
+}
+
+// NewFlowState creates a fresh state, PKCE verifier and nonce for a provider.
+func NewFlowState(provider string, linkUserID int) (*FlowState, error) {
+	state, err := RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FlowState{
+		Provider:     provider,
+		State:        state,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(StateTTL),
+	}, nil
+}
+
+// Seal encrypts and authenticates the state for storage in a cookie.
+func (f *FlowState) Seal() (string, error) {
+	payload, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	return utils.EncryptSecret(string(payload))
+}
+
+// LinkState seals a link flow into the state parameter sent to the provider.
+func (f *FlowState) LinkState() (string, error) {
+	sealed, err := f.Seal()
+	if err != nil {
+		return "", err
+	}
+	return linkStatePrefix + sealed, nil
+}
+
+// IsLinkState reports whether the callback's state belongs to a link flow.
+func IsLinkState(state string) bool {
+	return strings.HasPrefix(state, linkStatePrefix)
+}
+
+// OpenFlowState decrypts a sealed cookie and checks it against the provider
+// and the state returned in the callback query.
+func OpenFlowState(sealed, provider, state string) (*FlowState, error) {
+	if sealed == "" {
+		return nil, errors.New("missing oauth state cookie")
+	}
+
+	var f FlowState
+	if err := openSealed(sealed, &f); err != nil {
+		return nil, errors.New("invalid oauth state cookie")
+	}
+
+	if time.Now().After(f.ExpiresAt) {
+		return nil, errors.New("oauth state expired")
+	}
+	if f.Provider != provider || f.LinkUserID != 0 || subtle.ConstantTimeCompare([]byte(f.State), []byte(state)) != 1 {
+		return nil, errors.New("oauth state mismatch")
+	}
+	return &f, nil
+}
+
+// OpenLinkState decrypts the state of a link flow returned in the callback
+// query and checks it against the provider.
+func OpenLinkState(state, provider string) (*FlowState, error) {
+	var f FlowState
+	if err := openSealed(strings.TrimPrefix(state, linkStatePrefix), &f); err != nil {
+		return nil, errors.New("invalid oauth link state")
+	}
+
+	if time.Now().After(f.ExpiresAt) {
+		return nil, errors.New("oauth state expired")
+	}
+	if f.Provider != provider || f.LinkUserID == 0 {
+		return nil, errors.New("oauth state mismatch")
+	}
+	return &f, nil
+}
+
+// PendingLink is an identity returned to a link flow's callback. It is handed
+// to the frontend sealed, and only linked when the user who started the flow
+// confirms it while signed in.
+type PendingLink struct {
+	UserID    int       `json:"u"`
+	Provider  string    `json:"p"`
+	Subject   string    `json:"s"`
+	Email     string    `json:"m,omitempty"`
+	ExpiresAt time.Time `json:"e"`
+}
+
+// Seal encrypts and authenticates the pending link for the redirect URL.
+func (p *PendingLink) Seal() (string, error) {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return utils.EncryptSecret(string(payload))
+}
+
+// OpenPendingLink decrypts a sealed pending link and checks that it has not
+// expired.
+func OpenPendingLink(sealed string) (*PendingLink, error) {
+	var p PendingLink
+	if err := openSealed(sealed, &p); err != nil || p.UserID == 0 || p.Subject == "" {
+		return nil, errors.New("invalid link code")
+	}
+	if time.Now().After(p.ExpiresAt) {
+		return nil, errors.New("link code expired")
+	}
+	return &p, nil
+}
+
+func openSealed(sealed string, out interface{}) error {
+	payload, err := utils.DecryptSecret(sealed)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(payload), out)
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+type IdentityRepository interface {
+	Create(identity *model.UserIdentity) error
+	GetByProviderSubject(provider, subject string) (*model.UserIdentity, error)
+	GetByUserID(userID int) ([]model.UserIdentity, error)
+	Delete(userID int, provider string) (bool, error)
+	CreateExchangeCode(code *model.OAuthExchangeCode) error
+	ConsumeExchangeCode(codeHash string) (*model.OAuthExchangeCode, error)
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(identity *model.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *identityRepository) GetByProviderSubject(provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) GetByUserID(userID int) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	err := r.db.Where("id_user = ?", userID).Order("created_at ASC").Find(&identities).Error
+	return identities, err
+}
+
+func (r *identityRepository) Delete(userID int, provider string) (bool, error) {
+	result := r.db.Where("id_user = ? AND provider = ?", userID, provider).Delete(&model.UserIdentity{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *identityRepository) CreateExchangeCode(code *model.OAuthExchangeCode) error {
+	return r.db.Create(code).Error
+}
+
+// ConsumeExchangeCode marks an unexpired code as used and returns it. A code
+// can only be consumed once.
+func (r *identityRepository) ConsumeExchangeCode(codeHash string) (*model.OAuthExchangeCode, error) {
+	var code model.OAuthExchangeCode
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("code_hash = ? AND used_at IS NULL AND expires_at > ?", codeHash, time.Now()).First(&code).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&model.OAuthExchangeCode{}).Where("id = ? AND used_at IS NULL", code.ID).Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+// LedgerAccountTotal sums the entries of one account of one shop. IDToko is
+// nil for entries that are not about a shop.
+type LedgerAccountTotal struct {
+	Akun     string
+	IDToko   *int
+	NamaToko string
+	Debit    int64
+	Kredit   int64
+}
+
+// LedgerTRXMismatch is a paid order whose amount does not match what the
+// ledger recorded for it, or an order the ledger recorded that is no longer
+// paid.
+type LedgerTRXMismatch struct {
+	IDTRX         int
+	KodeInvoice   string
+	PaymentStatus string
+	HargaTotal    int64
+	Dicatat       int64
+}
+
+type LedgerRepository interface {
+	// Post writes a journal with its entries unless a journal with the same
+	// Kunci exists, and reports whether it was written.
+	Post(journal *model.LedgerJournal) (bool, error)
+	GetByKey(kunci string) (*model.LedgerJournal, error)
+	// ShopAccountTotals sums the shop's entries per account.
+	ShopAccountTotals(shopID int) ([]LedgerAccountTotal, error)
+	// AccountTotals sums all entries per shop and account.
+	AccountTotals() ([]LedgerAccountTotal, error)
+	// GetEntriesByShopID returns the shop's entries newest first, starting
+	// below the entry ID before when it is set.
+	GetEntriesByShopID(shopID, before, limit int) ([]model.LedgerEntry, error)
+	// UnbalancedJournalIDs lists journals whose debits and credits differ.
+	UnbalancedJournalIDs() ([]int, error)
+	// PaidTRXTotal sums the orders paid since the ledger was introduced.
+	PaidTRXTotal() (int64, error)
+	FindTRXMismatches() ([]LedgerTRXMismatch, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// errJournalExists rolls back a posting whose key was already used
+var errJournalExists = errors.New("journal exists")
+
+func (r *ledgerRepository) Post(journal *model.LedgerJournal) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if journal.Kunci != nil {
+			var count int64
+			if err := tx.Model(&model.LedgerJournal{}).Where("kunci = ?", *journal.Kunci).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return errJournalExists
+			}
+		}
+		return createJournal(tx, journal)
+	})
+	if errors.Is(err, errJournalExists) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *ledgerRepository) GetByKey(kunci string) (*model.LedgerJournal, error) {
+	var journal model.LedgerJournal
+	err := r.db.Preload("Entri").Where("kunci = ?", kunci).First(&journal).Error
+	if err != nil {
+		return nil, err
+	}
+	return &journal, nil
+}
+
+func (r *ledgerRepository) ShopAccountTotals(shopID int) ([]LedgerAccountTotal, error) {
+	var totals []LedgerAccountTotal
+	err := r.db.Model(&model.LedgerEntry{}).
+		Select("akun, id_toko, COALESCE(SUM(debit), 0) AS debit, COALESCE(SUM(kredit), 0) AS kredit").
+		Where("id_toko = ?", shopID).
+		Group("akun, id_toko").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *ledgerRepository) AccountTotals() ([]LedgerAccountTotal, error) {
+	var totals []LedgerAccountTotal
+	err := r.db.Model(&model.LedgerEntry{}).
+		Select("ledger_entri.akun, ledger_entri.id_toko, COALESCE(toko.nama_toko, '') AS nama_toko, COALESCE(SUM(ledger_entri.debit), 0) AS debit, COALESCE(SUM(ledger_entri.kredit), 0) AS kredit").
+		Joins("LEFT JOIN toko ON toko.id = ledger_entri.id_toko").
+		Group("ledger_entri.akun, ledger_entri.id_toko, toko.nama_toko").
+		Order("ledger_entri.id_toko, ledger_entri.akun").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *ledgerRepository) GetEntriesByShopID(shopID, before, limit int) ([]model.LedgerEntry, error) {
+	var entries []model.LedgerEntry
+	query := r.db.Preload("Jurnal").Where("id_toko = ?", shopID)
+	if before > 0 {
+		query = query.Where("id < ?", before)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+func (r *ledgerRepository) UnbalancedJournalIDs() ([]int, error) {
+	var ids []int
+	err := r.db.Model(&model.LedgerEntry{}).
+		Select("id_jurnal").
+		Group("id_jurnal").
+		Having("SUM(debit) <> SUM(kredit)").
+		Order("id_jurnal").
+		Scan(&ids).Error
+	return ids, err
+}
+
+func (r *ledgerRepository) PaidTRXTotal() (int64, error) {
+	var total int64
+	err := r.db.Model(&model.TRX{}).
+		Select("COALESCE(SUM(harga_total), 0)").
+		Where("dibayar_at IS NOT NULL AND payment_status = ?", constants.PaymentStatusPaid).
+		Scan(&total).Error
+	return total, err
+}
+
+// FindTRXMismatches compares each order paid since the ledger was introduced
+// with the cash its sale journal recorded.
+func (r *ledgerRepository) FindTRXMismatches() ([]LedgerTRXMismatch, error) {
+	recorded := r.db.Model(&model.LedgerEntry{}).
+		Select("ledger_jurnal.id_trx, SUM(ledger_entri.debit) AS dicatat").
+		Joins("JOIN ledger_jurnal ON ledger_jurnal.id = ledger_entri.id_jurnal").
+		Where("ledger_jurnal.jenis = ? AND ledger_entri.akun = ?", constants.LedgerJournalSale, constants.LedgerAccountPlatformCash).
+		Group("ledger_jurnal.id_trx")
+
+	var mismatches []LedgerTRXMismatch
+	err := r.db.Model(&model.TRX{}).
+		Select("trx.id AS id_trx, trx.kode_invoice, trx.payment_status, trx.harga_total, COALESCE(recorded.dicatat, 0) AS dicatat").
+		Joins("LEFT JOIN (?) AS recorded ON recorded.id_trx = trx.id", recorded).
+		Where("trx.dibayar_at IS NOT NULL").
+		Where("(trx.payment_status = ? AND COALESCE(recorded.dicatat, 0) <> trx.harga_total) OR (trx.payment_status <> ? AND COALESCE(recorded.dicatat, 0) > 0)",
+			constants.PaymentStatusPaid, constants.PaymentStatusPaid).
+		Order("trx.id").
+		Scan(&mismatches).Error
+	return mismatches, err
+}
+
+// createJournal writes a journal and its entries, leaving the entries'
+// journal association alone.
+func createJournal(tx *gorm.DB, journal *model.LedgerJournal) error {
+	return tx.Omit("Entri.Jurnal").Create(journal).Error
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+type LogProductRepository interface {
+	Create(product *model.Product, userID *int) (*model.LogProduct, error)
+	// Current returns the latest snapshot of the product, writing a new one
+	// first if the product has changed since.
+	Current(product *model.Product, userID *int) (*model.LogProduct, error)
+	GetByProductID(productID int) ([]model.LogProduct, error)
+}
+
+type logProductRepository struct {
+	db *gorm.DB
+}
+
+func NewLogProductRepository(db *gorm.DB) LogProductRepository {
+	return &logProductRepository{db: db}
+}
+
+func (r *logProductRepository) Create(product *model.Product, userID *int) (*model.LogProduct, error) {
+	snapshot := newLogProduct(product, userID)
+	if err := r.db.Omit("Toko", "Produk", "Category", "User").Create(snapshot).Error; err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (r *logProductRepository) Current(product *model.Product, userID *int) (*model.LogProduct, error) {
+	var latest model.LogProduct
+	err := r.db.Where("id_produk = ?", product.ID).Order("id DESC").Limit(1).Find(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+	if latest.ID != 0 && sameSnapshot(&latest, product) {
+		return &latest, nil
+	}
+	return r.Create(product, userID)
+}
+
+// GetByProductID lists a product's snapshots, newest first.
+func (r *logProductRepository) GetByProductID(productID int) ([]model.LogProduct, error) {
+	var logs []model.LogProduct
+	err := r.db.Preload("User").Where("id_produk = ?", productID).Order("id DESC").Find(&logs).Error
+	return logs, err
+}
+
+func newLogProduct(product *model.Product, userID *int) *model.LogProduct {
+	return &model.LogProduct{
+		IDProduk:             product.ID,
+		NamaProduk:           product.NamaProduk,
+		Slug:                 product.Slug,
+		HargaReseller:        product.HargaReseller,
+		HargaKonsumen:        product.HargaKonsumen,
+		MinKuantitasReseller: product.MinKuantitasReseller,
+		Stock:                product.Stok,
+		Deskripsi:            product.Deskripsi,
+		IDToko:               product.IDToko,
+		IDCategory:           product.IDCategory,
+		IDUser:               userID,
+	}
+}
+
+// sameSnapshot reports whether the snapshot still describes the product.
+// Stock is left out: it changes with every order and is not part of what
+// the buyer agreed to.
+func sameSnapshot(snapshot *model.LogProduct, product *model.Product) bool {
+	return snapshot.NamaProduk == product.NamaProduk &&
+		snapshot.Slug == product.Slug &&
+		snapshot.HargaReseller == product.HargaReseller &&
+		snapshot.HargaKonsumen == product.HargaKonsumen &&
+		snapshot.MinKuantitasReseller == product.MinKuantitasReseller &&
+		snapshot.Deskripsi == product.Deskripsi &&
+		snapshot.IDToko == product.IDToko &&
+		snapshot.IDCategory == product.IDCategory
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepository interface {
+	GetThrottles(keys []string) ([]model.LoginThrottle, error)
+	IncrementThrottle(key string, window time.Duration) (*model.LoginThrottle, error)
+	LockThrottle(key string, until time.Time) error
+	ResetThrottle(key string) error
+	CreateFailedLogin(attempt *model.FailedLogin) error
+	CreateUnlockToken(token *model.AccountUnlockToken) error
+	ConsumeUnlockToken(tokenHash string) (*model.AccountUnlockToken, error)
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) GetThrottles(keys []string) ([]model.LoginThrottle, error) {
+	var throttles []model.LoginThrottle
+	err := r.db.Where("throttle_key IN ?", keys).Find(&throttles).Error
+	return throttles, err
+}
+
+// IncrementThrottle records one more failure for the key and returns the
+// updated counter. Failures older than the window are forgotten once any
+// lock on the key has expired.
+func (r *loginAttemptRepository) IncrementThrottle(key string, window time.Duration) (*model.LoginThrottle, error) {
+	var throttle model.LoginThrottle
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.LoginThrottle{Key: key, LastFailureAt: now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("throttle_key = ?", key).First(&throttle).Error; err != nil {
+			return err
+		}
+
+		lockExpired := throttle.LockedUntil == nil || now.After(*throttle.LockedUntil)
+		if lockExpired && now.Sub(throttle.LastFailureAt) > window {
+			throttle.Failures = 0
+			throttle.LockedUntil = nil
+		}
+
+		throttle.Failures++
+		throttle.LastFailureAt = now
+		return tx.Save(&throttle).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *loginAttemptRepository) LockThrottle(key string, until time.Time) error {
+	return r.db.Model(&model.LoginThrottle{}).Where("throttle_key = ?", key).Update("locked_until", until).Error
+}
+
+func (r *loginAttemptRepository) ResetThrottle(key string) error {
+	return r.db.Where("throttle_key = ?", key).Delete(&model.LoginThrottle{}).Error
+}
+
+func (r *loginAttemptRepository) CreateFailedLogin(attempt *model.FailedLogin) error {
+	return r.db.Create(attempt).Error
+}
+
+func (r *loginAttemptRepository) CreateUnlockToken(token *model.AccountUnlockToken) error {
+	return r.db.Create(token).Error
+}
+
+// ConsumeUnlockToken marks an unexpired token as used and returns it. A token
+// can only be consumed once.
+func (r *loginAttemptRepository) ConsumeUnlockToken(tokenHash string) (*model.AccountUnlockToken, error) {
+	var token model.AccountUnlockToken
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).First(&token).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&model.AccountUnlockToken{}).Where("id = ? AND used_at IS NULL", token.ID).Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
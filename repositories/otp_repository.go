@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+type OTPRepository interface {
+	Create(otp *model.PhoneOTP) error
+	GetLatest(phone, purpose string) (*model.PhoneOTP, error)
+	// TakeAttempt counts an attempt against the code unless it already has
+	// maxAttempts, and reports whether it did.
+	TakeAttempt(id, maxAttempts int) (bool, error)
+	// MarkConsumed consumes the code and reports whether it was still unused.
+	MarkConsumed(id int) (bool, error)
+	InvalidateActive(phone, purpose string) error
+}
+
+type otpRepository struct {
+	db *gorm.DB
+}
+
+func NewOTPRepository(db *gorm.DB) OTPRepository {
+	return &otpRepository{db: db}
+}
+
+func (r *otpRepository) Create(otp *model.PhoneOTP) error {
+	return r.db.Create(otp).Error
+}
+
+// GetLatest returns the most recently issued code for a phone and purpose,
+// whether or not it has been consumed.
+func (r *otpRepository) GetLatest(phone, purpose string) (*model.PhoneOTP, error) {
+	var otp model.PhoneOTP
+	err := r.db.Where("notelp = ? AND purpose = ?", phone, purpose).Order("created_at DESC, id DESC").First(&otp).Error
+	if err != nil {
+		return nil, err
+	}
+	return &otp, nil
+}
+
+func (r *otpRepository) TakeAttempt(id, maxAttempts int) (bool, error) {
+	result := r.db.Model(&model.PhoneOTP{}).Where("id = ? AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *otpRepository) MarkConsumed(id int) (bool, error) {
+	result := r.db.Model(&model.PhoneOTP{}).Where("id = ? AND consumed_at IS NULL", id).Update("consumed_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// InvalidateActive consumes every outstanding code so only the newest one is usable.
+func (r *otpRepository) InvalidateActive(phone, purpose string) error {
+	return r.db.Model(&model.PhoneOTP{}).
+		Where("notelp = ? AND purpose = ? AND consumed_at IS NULL", phone, purpose).
+		Update("consumed_at", time.Now()).Error
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PayoutRepository interface {
+	GetBankAccount(shopID int) (*model.ShopBankAccount, error)
+	SaveBankAccount(account *model.ShopBankAccount) error
+	// Create saves a payout with the journal reserving its amount, only if
+	// the shop's available balance covers it, and reports whether it did.
+	Create(payout *model.Payout, journal *model.LedgerJournal) (bool, error)
+	GetByID(id int) (*model.Payout, error)
+	GetByShopID(shopID int) ([]model.Payout, error)
+	GetAll(status string) ([]model.Payout, error)
+	// Review saves the decision on a pending payout with the journal
+	// settling its amount, and reports whether the payout was still pending.
+	Review(payout *model.Payout, journal *model.LedgerJournal) (bool, error)
+}
+
+type payoutRepository struct {
+	db *gorm.DB
+}
+
+func NewPayoutRepository(db *gorm.DB) PayoutRepository {
+	return &payoutRepository{db: db}
+}
+
+func (r *payoutRepository) GetBankAccount(shopID int) (*model.ShopBankAccount, error) {
+	var account model.ShopBankAccount
+	err := r.db.Where("id_toko = ?", shopID).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *payoutRepository) SaveBankAccount(account *model.ShopBankAccount) error {
+	return r.db.Save(account).Error
+}
+
+// errPayoutUnavailable rolls back a payout the balance does not cover, or a
+// review of a payout that is no longer pending
+var errPayoutUnavailable = errors.New("payout unavailable")
+
+func (r *payoutRepository) Create(payout *model.Payout, journal *model.LedgerJournal) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the shop so concurrent requests see each other's reservations
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.Shop{}, payout.IDToko).Error; err != nil {
+			return err
+		}
+
+		var available int64
+		err := tx.Model(&model.LedgerEntry{}).
+			Select("COALESCE(SUM(kredit), 0) - COALESCE(SUM(debit), 0)").
+			Where("id_toko = ? AND akun = ?", payout.IDToko, constants.LedgerAccountShopAvailable).
+			Scan(&available).Error
+		if err != nil {
+			return err
+		}
+		if available < payout.Jumlah {
+			return errPayoutUnavailable
+		}
+
+		if err := tx.Omit("Toko").Create(payout).Error; err != nil {
+			return err
+		}
+		journal.IDPenarikan = &payout.ID
+		return createJournal(tx, journal)
+	})
+	if errors.Is(err, errPayoutUnavailable) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *payoutRepository) GetByID(id int) (*model.Payout, error) {
+	var payout model.Payout
+	err := r.db.Preload("Toko").First(&payout, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payout, nil
+}
+
+func (r *payoutRepository) GetByShopID(shopID int) ([]model.Payout, error) {
+	var payouts []model.Payout
+	err := r.db.Preload("Toko").Where("id_toko = ?", shopID).Order("id DESC").Find(&payouts).Error
+	return payouts, err
+}
+
+// GetAll lists payouts with the given status, or all of them, oldest first
+// so the queue is worked in order.
+func (r *payoutRepository) GetAll(status string) ([]model.Payout, error) {
+	var payouts []model.Payout
+	query := r.db.Preload("Toko")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("id ASC").Find(&payouts).Error
+	return payouts, err
+}
+
+func (r *payoutRepository) Review(payout *model.Payout, journal *model.LedgerJournal) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Payout{}).
+			Where("id = ? AND status = ?", payout.ID, constants.PayoutPending).
+			Updates(map[string]interface{}{
+				"status":      payout.Status,
+				"catatan":     payout.Catatan,
+				"reviewed_by": payout.ReviewedBy,
+				"reviewed_at": payout.ReviewedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPayoutUnavailable
+		}
+		journal.IDPenarikan = &payout.ID
+		return createJournal(tx, journal)
+	})
+	if errors.Is(err, errPayoutUnavailable) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+type PhotoUploadRepository interface {
+	Create(upload *model.PhotoUpload) error
+	GetByID(id int) (*model.PhotoUpload, error)
+	Update(upload *model.PhotoUpload) error
+	// Claim marks a pending upload that has not expired by now as processing,
+	// until leaseUntil, and reports whether it did; only one confirmation can
+	// claim an upload.
+	Claim(id int, now, leaseUntil time.Time) (bool, error)
+	// Release puts a claimed upload back to pending with its own expiry.
+	Release(upload *model.PhotoUpload) error
+	Delete(id int) error
+	GetExpired(now time.Time, limit int) ([]model.PhotoUpload, error)
+}
+
+type photoUploadRepository struct {
+	db *gorm.DB
+}
+
+func NewPhotoUploadRepository(db *gorm.DB) PhotoUploadRepository {
+	return &photoUploadRepository{db: db}
+}
+
+func (r *photoUploadRepository) Create(upload *model.PhotoUpload) error {
+	return r.db.Create(upload).Error
+}
+
+func (r *photoUploadRepository) GetByID(id int) (*model.PhotoUpload, error) {
+	var upload model.PhotoUpload
+	err := r.db.First(&upload, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *photoUploadRepository) Update(upload *model.PhotoUpload) error {
+	return r.db.Save(upload).Error
+}
+
+func (r *photoUploadRepository) Claim(id int, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&model.PhotoUpload{}).
+		Where("id = ? AND status = ? AND expires_at > ?", id, constants.PhotoUploadPending, now).
+		Updates(map[string]interface{}{"status": constants.PhotoUploadProcessing, "expires_at": leaseUntil})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *photoUploadRepository) Release(upload *model.PhotoUpload) error {
+	return r.db.Model(&model.PhotoUpload{}).
+		Where("id = ? AND status = ?", upload.ID, constants.PhotoUploadProcessing).
+		Updates(map[string]interface{}{"status": constants.PhotoUploadPending, "expires_at": upload.ExpiresAt}).Error
+}
+
+func (r *photoUploadRepository) Delete(id int) error {
+	return r.db.Delete(&model.PhotoUpload{}, id).Error
+}
+
+// GetExpired lists pending uploads that expired before now, oldest first,
+// along with claims whose confirmation never finished.
+func (r *photoUploadRepository) GetExpired(now time.Time, limit int) ([]model.PhotoUpload, error) {
+	var uploads []model.PhotoUpload
+	err := r.db.Where("status IN ? AND expires_at < ?", []string{constants.PhotoUploadPending, constants.PhotoUploadProcessing}, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&uploads).Error
+	return uploads, err
+}
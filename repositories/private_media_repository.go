@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+type PrivateMediaRepository interface {
+	Create(media *model.PrivateMedia) error
+	GetByID(id int) (*model.PrivateMedia, error)
+	Delete(id int) error
+	InUse(id int) (bool, error)
+}
+
+type privateMediaRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivateMediaRepository(db *gorm.DB) PrivateMediaRepository {
+	return &privateMediaRepository{db: db}
+}
+
+func (r *privateMediaRepository) Create(media *model.PrivateMedia) error {
+	return r.db.Create(media).Error
+}
+
+func (r *privateMediaRepository) GetByID(id int) (*model.PrivateMedia, error) {
+	var media model.PrivateMedia
+	err := r.db.First(&media, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+func (r *privateMediaRepository) Delete(id int) error {
+	return r.db.Delete(&model.PrivateMedia{}, id).Error
+}
+
+// InUse reports whether a shop verification request refers to the file.
+func (r *privateMediaRepository) InUse(id int) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.ShopVerificationDocument{}).Where("id_media = ?", id).Count(&count).Error
+	return count > 0, err
+}
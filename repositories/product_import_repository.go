@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+type ProductImportRepository interface {
+	Create(productImport *model.ProductImport) error
+	GetByID(id int) (*model.ProductImport, error)
+	GetByShopID(shopID int) ([]model.ProductImport, error)
+	Update(productImport *model.ProductImport) error
+	AddErrors(importErrors []model.ProductImportError) error
+	GetErrors(importID int) ([]model.ProductImportError, error)
+	FailUnfinished(message string) (int64, error)
+}
+
+type productImportRepository struct {
+	db *gorm.DB
+}
+
+func NewProductImportRepository(db *gorm.DB) ProductImportRepository {
+	return &productImportRepository{db: db}
+}
+
+func (r *productImportRepository) Create(productImport *model.ProductImport) error {
+	return r.db.Create(productImport).Error
+}
+
+func (r *productImportRepository) GetByID(id int) (*model.ProductImport, error) {
+	var productImport model.ProductImport
+	err := r.db.First(&productImport, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &productImport, nil
+}
+
+// GetByShopID lists the shop's imports, newest first.
+func (r *productImportRepository) GetByShopID(shopID int) ([]model.ProductImport, error) {
+	var productImports []model.ProductImport
+	err := r.db.Where("id_toko = ?", shopID).Order("id DESC").Find(&productImports).Error
+	return productImports, err
+}
+
+func (r *productImportRepository) Update(productImport *model.ProductImport) error {
+	return r.db.Save(productImport).Error
+}
+
+func (r *productImportRepository) AddErrors(importErrors []model.ProductImportError) error {
+	if len(importErrors) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(importErrors, 500).Error
+}
+
+// GetErrors lists an import's rejected rows in file order.
+func (r *productImportRepository) GetErrors(importID int) ([]model.ProductImportError, error) {
+	var importErrors []model.ProductImportError
+	err := r.db.Where("id_import = ?", importID).Order("baris ASC, id ASC").Find(&importErrors).Error
+	return importErrors, err
+}
+
+// FailUnfinished marks imports that were still pending or processing as
+// failed, e.g. after a restart interrupted them.
+func (r *productImportRepository) FailUnfinished(message string) (int64, error) {
+	result := r.db.Model(&model.ProductImport{}).
+		Where("status IN ?", []string{constants.ProductImportPending, constants.ProductImportProcessing}).
+		Updates(map[string]interface{}{
+			"status":      constants.ProductImportFailed,
+			"pesan":       message,
+			"finished_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
@@ -1,19 +1,70 @@
 package repositories
 
 import (
+    "errors"
+    "time"
+
+    "github.com/rdsarjito/marketplace-backend/constants"
     "github.com/rdsarjito/marketplace-backend/domain/model"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
+// ProductQuery describes one page of the product catalog. Zero values mean
+// "no filter"; Sort defaults to newest first.
+type ProductQuery struct {
+	// IDs restricts the results to these products, e.g. keyword matches from
+	// the search index. Sorting by relevance keeps the order of IDs.
+	IDs        []int
+	CategoryID int
+	ShopID     int
+	MinPrice   *int64
+	MaxPrice   *int64
+	InStock    bool
+	Sort       string
+	After      *ProductCursor
+	Limit      int
+}
+
+// ProductCursor holds the sort key of the last product of the previous page.
+// Only the field matching the query's sort order is used, plus ID as tie-breaker.
+type ProductCursor struct {
+	ID        int
+	Rank      int
+	CreatedAt time.Time
+	Price     int64
+	Terjual   int64
+}
+
+const productPriceExpr = "produk.harga_konsumen"
+
+const productTerjualExpr = "COALESCE(sales.terjual, 0)"
+
+const productPhotoOrder = "is_primary DESC, posisi ASC, id ASC"
+
 type ProductRepository interface {
 	Create(product *model.Product) error
+	// CreateWithVariants creates product and runs addVariants with a variant
+	// repository bound to the same transaction; an error from either rolls
+	// both back.
+	CreateWithVariants(product *model.Product, addVariants func(variants ProductVariantRepository) error) error
 	GetByID(id int) (*model.Product, error)
-	GetAll() ([]model.Product, error)
+	Search(query ProductQuery) ([]model.Product, int64, error)
+	FindInBatches(batchSize int, fn func(products []model.Product) error) error
+	FindPhotosInBatches(batchSize int, fn func(photos []model.PhotoProduct) error) error
 	GetByShopID(shopID int) ([]model.Product, error)
+	CountByShopID(shopID int) (int64, error)
 	GetByCategoryID(categoryID int) ([]model.Product, error)
 	Update(product *model.Product) error
+	DecrementStock(productID int, variantID *int, quantity int) (bool, error)
+	IncrementStock(productID int, variantID *int, quantity int) error
 	Delete(id int) error
     AddPhoto(photo *model.PhotoProduct) error
+	CountPhotos(productID int) (int64, error)
+	GetPhoto(productID, photoID int) (*model.PhotoProduct, error)
+	ReorderPhotos(productID int, photoIDs []int) error
+	SetPrimaryPhoto(productID, photoID int) error
+	DeletePhoto(photo *model.PhotoProduct) error
 }
 
 type productRepository struct {
@@ -28,35 +79,169 @@ func (r *productRepository) Create(product *model.Product) error {
 	return r.db.Create(product).Error
 }
 
+func (r *productRepository) CreateWithVariants(product *model.Product, addVariants func(variants ProductVariantRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		return addVariants(NewProductVariantRepository(tx))
+	})
+}
+
 func (r *productRepository) GetByID(id int) (*model.Product, error) {
 	var product model.Product
-    err := r.db.Preload("Toko").Preload("Category").Preload("PhotosProduct", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).First(&product, id).Error
+    err := preloadProductDetails(r.db).First(&product, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
-func (r *productRepository) GetAll() ([]model.Product, error) {
+// Search returns the products matching the query, in sort order, along with
+// the total number of matches ignoring the cursor and limit.
+func (r *productRepository) Search(query ProductQuery) ([]model.Product, int64, error) {
+	filtered := r.db.Model(&model.Product{})
+	if query.IDs != nil {
+		filtered = filtered.Where("produk.id IN ?", query.IDs)
+	}
+	if query.CategoryID != 0 {
+		filtered = filtered.Where("produk.id_category = ?", query.CategoryID)
+	}
+	if query.ShopID != 0 {
+		filtered = filtered.Where("produk.id_toko = ?", query.ShopID)
+	}
+	if query.MinPrice != nil {
+		filtered = filtered.Where(productPriceExpr+" >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		filtered = filtered.Where(productPriceExpr+" <= ?", *query.MaxPrice)
+	}
+	if query.InStock {
+		filtered = filtered.Where("produk.stok > 0")
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sales := r.db.Table("detail_trx").
+		Select("detail_trx.id_produk, SUM(detail_trx.kuantitas) AS terjual").
+		Joins("JOIN trx ON trx.id = detail_trx.id_trx AND trx.deleted_at IS NULL").
+		Where("trx.payment_status = ?", constants.PaymentStatusPaid).
+		Group("detail_trx.id_produk")
+
+	page := filtered.Session(&gorm.Session{}).
+		Select("produk.*, "+productTerjualExpr+" AS terjual").
+		Joins("LEFT JOIN (?) AS sales ON sales.id_produk = produk.id", sales)
+
+	// Keyset pagination: continue strictly after the cursor in sort order
+	switch query.Sort {
+	case constants.ProductSortRelevance:
+		// FIELD() gives the 1-based position of the product in IDs
+		rank := clause.Expr{SQL: "FIELD(produk.id, ?)", Vars: []interface{}{query.IDs}}
+		if c := query.After; c != nil {
+			page = page.Where("? > ?", rank, c.Rank)
+		}
+		page = page.Order(rank)
+	case constants.ProductSortPriceAsc:
+		if c := query.After; c != nil {
+			page = page.Where("("+productPriceExpr+" > ? OR ("+productPriceExpr+" = ? AND produk.id > ?))", c.Price, c.Price, c.ID)
+		}
+		page = page.Order(productPriceExpr + " ASC").Order("produk.id ASC")
+	case constants.ProductSortPriceDesc:
+		if c := query.After; c != nil {
+			page = page.Where("("+productPriceExpr+" < ? OR ("+productPriceExpr+" = ? AND produk.id < ?))", c.Price, c.Price, c.ID)
+		}
+		page = page.Order(productPriceExpr + " DESC").Order("produk.id DESC")
+	case constants.ProductSortBestSelling:
+		if c := query.After; c != nil {
+			page = page.Where("("+productTerjualExpr+" < ? OR ("+productTerjualExpr+" = ? AND produk.id < ?))", c.Terjual, c.Terjual, c.ID)
+		}
+		page = page.Order(productTerjualExpr + " DESC").Order("produk.id DESC")
+	default:
+		if c := query.After; c != nil {
+			page = page.Where("(produk.created_at < ? OR (produk.created_at = ? AND produk.id < ?))", c.CreatedAt, c.CreatedAt, c.ID)
+		}
+		page = page.Order("produk.created_at DESC").Order("produk.id DESC")
+	}
+
 	var products []model.Product
-    err := r.db.Preload("Toko").Preload("Category").Preload("PhotosProduct", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).Find(&products).Error
-	return products, err
+	err := preloadProductDetails(page).Limit(query.Limit).Find(&products).Error
+	return products, total, err
 }
 
 func (r *productRepository) GetByShopID(shopID int) ([]model.Product, error) {
 	var products []model.Product
-    err := r.db.Preload("Toko").Preload("Category").Preload("PhotosProduct", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).Where("id_toko = ?", shopID).Find(&products).Error
+    err := r.db.Preload("Toko").Preload("Category").Preload("PhotosProduct", func(db *gorm.DB) *gorm.DB { return db.Order(productPhotoOrder) }).Preload("PhotosProduct.Renditions").Where("id_toko = ?", shopID).Find(&products).Error
 	return products, err
 }
 
+func (r *productRepository) CountByShopID(shopID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Product{}).Where("id_toko = ?", shopID).Count(&count).Error
+	return count, err
+}
+
 func (r *productRepository) GetByCategoryID(categoryID int) ([]model.Product, error) {
 	var products []model.Product
-    err := r.db.Preload("Toko").Preload("Category").Preload("PhotosProduct", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).Where("id_category = ?", categoryID).Find(&products).Error
+    err := r.db.Preload("Toko").Preload("Category").Preload("PhotosProduct", func(db *gorm.DB) *gorm.DB { return db.Order(productPhotoOrder) }).Preload("PhotosProduct.Renditions").Where("id_category = ?", categoryID).Find(&products).Error
 	return products, err
 }
 
 func (r *productRepository) Update(product *model.Product) error {
-	return r.db.Save(product).Error
+	return r.db.Omit(clause.Associations).Save(product).Error
+}
+
+// errStockUnavailable rolls back a partial stock decrement
+var errStockUnavailable = errors.New("stock unavailable")
+
+// DecrementStock takes quantity off the product's stock, and off the
+// variant's when variantID is set, only if enough is left. It reports
+// whether the stock was taken.
+func (r *productRepository) DecrementStock(productID int, variantID *int, quantity int) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if variantID != nil {
+			result := tx.Model(&model.ProductVariant{}).
+				Where("id = ? AND id_produk = ? AND stok >= ?", *variantID, productID, quantity).
+				UpdateColumn("stok", gorm.Expr("stok - ?", quantity))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errStockUnavailable
+			}
+		}
+
+		result := tx.Model(&model.Product{}).
+			Where("id = ? AND stok >= ?", productID, quantity).
+			UpdateColumn("stok", gorm.Expr("stok - ?", quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errStockUnavailable
+		}
+		return nil
+	})
+	if errors.Is(err, errStockUnavailable) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// IncrementStock returns quantity to the product's (and variant's) stock.
+func (r *productRepository) IncrementStock(productID int, variantID *int, quantity int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if variantID != nil {
+			if err := tx.Model(&model.ProductVariant{}).Where("id = ?", *variantID).
+				UpdateColumn("stok", gorm.Expr("stok + ?", quantity)).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.Product{}).Where("id = ?", productID).
+			UpdateColumn("stok", gorm.Expr("stok + ?", quantity)).Error
+	})
 }
 
 func (r *productRepository) Delete(id int) error {
@@ -66,3 +251,111 @@ func (r *productRepository) Delete(id int) error {
 func (r *productRepository) AddPhoto(photo *model.PhotoProduct) error {
     return r.db.Create(photo).Error
 }
+
+func (r *productRepository) CountPhotos(productID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.PhotoProduct{}).Where("id_produk = ?", productID).Count(&count).Error
+	return count, err
+}
+
+func (r *productRepository) GetPhoto(productID, photoID int) (*model.PhotoProduct, error) {
+	var photo model.PhotoProduct
+	err := r.db.Preload("Renditions").Where("id = ? AND id_produk = ?", photoID, productID).First(&photo).Error
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+// ReorderPhotos sets each photo's position to its index in photoIDs.
+func (r *productRepository) ReorderPhotos(productID int, photoIDs []int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, photoID := range photoIDs {
+			err := tx.Model(&model.PhotoProduct{}).Where("id = ? AND id_produk = ?", photoID, productID).
+				Update("posisi", i).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetPrimaryPhoto makes photoID the product's only primary photo.
+func (r *productRepository) SetPrimaryPhoto(productID, photoID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.PhotoProduct{}).Where("id_produk = ? AND id <> ?", productID, photoID).
+			Update("is_primary", false).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.PhotoProduct{}).Where("id = ? AND id_produk = ?", photoID, productID).
+			Update("is_primary", true).Error
+	})
+}
+
+// DeletePhoto removes a photo, unlinks it from variants and closes the gap
+// in the remaining photos' positions. If it was the primary photo, the first
+// remaining photo becomes primary.
+func (r *productRepository) DeletePhoto(photo *model.PhotoProduct) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Deleted variants keep their row, so unlink those too
+		err := tx.Unscoped().Model(&model.ProductVariant{}).Where("id_foto = ?", photo.ID).Update("id_foto", nil).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("id_foto = ?", photo.ID).Delete(&model.PhotoRendition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.PhotoProduct{}, photo.ID).Error; err != nil {
+			return err
+		}
+
+		var remaining []model.PhotoProduct
+		err = tx.Where("id_produk = ?", photo.IDProduk).Order("posisi ASC, id ASC").Find(&remaining).Error
+		if err != nil {
+			return err
+		}
+		for i, other := range remaining {
+			updates := map[string]interface{}{"posisi": i}
+			if photo.IsPrimary && i == 0 {
+				updates["is_primary"] = true
+			}
+			if err := tx.Model(&model.PhotoProduct{}).Where("id = ?", other.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindInBatches walks every product in ID order, batchSize at a time.
+func (r *productRepository) FindInBatches(batchSize int, fn func(products []model.Product) error) error {
+	var products []model.Product
+	return r.db.FindInBatches(&products, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(products)
+	}).Error
+}
+
+// FindPhotosInBatches walks the photos of every product that has not been
+// deleted, with their renditions, batchSize at a time.
+func (r *productRepository) FindPhotosInBatches(batchSize int, fn func(photos []model.PhotoProduct) error) error {
+	var photos []model.PhotoProduct
+	return r.db.Preload("Renditions").
+		Where("id_produk IN (?)", r.db.Model(&model.Product{}).Select("id")).
+		FindInBatches(&photos, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(photos)
+		}).Error
+}
+
+// preloadProductDetails loads everything ProductResponse shows.
+func preloadProductDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Toko").Preload("Category").
+		Preload("PhotosProduct", func(db *gorm.DB) *gorm.DB { return db.Order(productPhotoOrder) }).
+		Preload("PhotosProduct.Renditions").
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("posisi ASC, id ASC") }).
+		Preload("Options.Values", func(db *gorm.DB) *gorm.DB { return db.Order("posisi ASC, id ASC") }).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Variants.OptionValues.Option").
+		Preload("Variants.Foto")
+}
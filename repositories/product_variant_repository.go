@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductVariantRepository interface {
+	GetByID(productID, id int) (*model.ProductVariant, error)
+	GetByProductID(productID int) ([]model.ProductVariant, error)
+	SKUExists(sku string, exceptID int) (bool, error)
+	GetOptions(productID int) ([]model.ProductOption, error)
+	FindOrCreateOption(option *model.ProductOption) error
+	FindOrCreateOptionValue(value *model.ProductOptionValue) error
+	Create(variant *model.ProductVariant) error
+	Update(variant *model.ProductVariant) error
+	Delete(id int) error
+	DeleteOptions(productID int) error
+	SyncProduct(productID int) error
+}
+
+type productVariantRepository struct {
+	db *gorm.DB
+}
+
+func NewProductVariantRepository(db *gorm.DB) ProductVariantRepository {
+	return &productVariantRepository{db: db}
+}
+
+func (r *productVariantRepository) GetByID(productID, id int) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	err := r.db.Preload("OptionValues.Option").Where("id_produk = ?", productID).First(&variant, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *productVariantRepository) GetByProductID(productID int) ([]model.ProductVariant, error) {
+	var variants []model.ProductVariant
+	err := r.db.Preload("OptionValues.Option").Where("id_produk = ?", productID).Order("id ASC").Find(&variants).Error
+	return variants, err
+}
+
+// SKUExists reports whether an active variant other than exceptID uses sku.
+func (r *productVariantRepository) SKUExists(sku string, exceptID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.ProductVariant{}).Where("sku = ? AND id <> ?", sku, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *productVariantRepository) GetOptions(productID int) ([]model.ProductOption, error) {
+	var options []model.ProductOption
+	err := r.db.Preload("Values", func(db *gorm.DB) *gorm.DB { return db.Order("posisi ASC, id ASC") }).
+		Where("id_produk = ?", productID).Order("posisi ASC, id ASC").Find(&options).Error
+	return options, err
+}
+
+func (r *productVariantRepository) FindOrCreateOption(option *model.ProductOption) error {
+	return r.db.Where(model.ProductOption{IDProduk: option.IDProduk, Nama: option.Nama}).
+		Attrs(model.ProductOption{Posisi: option.Posisi}).
+		FirstOrCreate(option).Error
+}
+
+func (r *productVariantRepository) FindOrCreateOptionValue(value *model.ProductOptionValue) error {
+	return r.db.Where(model.ProductOptionValue{IDOption: value.IDOption, Nilai: value.Nilai}).
+		Attrs(model.ProductOptionValue{Posisi: value.Posisi}).
+		FirstOrCreate(value).Error
+}
+
+func (r *productVariantRepository) Create(variant *model.ProductVariant) error {
+	return r.db.Omit("OptionValues.*", "Foto").Create(variant).Error
+}
+
+// Update saves the variant and replaces its option values.
+func (r *productVariantRepository) Update(variant *model.ProductVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(variant).Error; err != nil {
+			return err
+		}
+		return tx.Model(variant).Omit("OptionValues.*").Association("OptionValues").Replace(variant.OptionValues)
+	})
+}
+
+func (r *productVariantRepository) Delete(id int) error {
+	return r.db.Delete(&model.ProductVariant{}, id).Error
+}
+
+// DeleteOptions removes a product's option types and values; used once its
+// last variant is gone.
+func (r *productVariantRepository) DeleteOptions(productID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		optionIDs := tx.Model(&model.ProductOption{}).Select("id").Where("id_produk = ?", productID)
+		if err := tx.Where("id_option IN (?)", optionIDs).Delete(&model.ProductOptionValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id_produk = ?", productID).Delete(&model.ProductOption{}).Error
+	})
+}
+
+// SyncProduct sets the product's stock to the total of its variants and its
+// prices to the lowest variant prices. Products without variants are left
+// untouched.
+func (r *productVariantRepository) SyncProduct(productID int) error {
+	var totals struct {
+		Count         int64
+		Stok          int
+		HargaKonsumen int64
+		HargaReseller int64
+	}
+	err := r.db.Model(&model.ProductVariant{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(stok), 0) AS stok, COALESCE(MIN(harga_konsumen), 0) AS harga_konsumen, COALESCE(MIN(harga_reseller), 0) AS harga_reseller").
+		Where("id_produk = ?", productID).
+		Scan(&totals).Error
+	if err != nil {
+		return err
+	}
+	if totals.Count == 0 {
+		return nil
+	}
+
+	return r.db.Model(&model.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"stok":           totals.Stok,
+		"harga_konsumen": totals.HargaKonsumen,
+		"harga_reseller": totals.HargaReseller,
+	}).Error
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ResellerRepository interface {
+	Create(application *model.ResellerApplication) error
+	GetByID(id int) (*model.ResellerApplication, error)
+	GetLatestByUserID(userID int) (*model.ResellerApplication, error)
+	HasOpenApplication(userID int) (bool, error)
+	GetAll(status string) ([]model.ResellerApplication, error)
+	// Review saves the application's new status if it is still in status
+	// from, and in the same transaction gives the applicant the role with
+	// roleID when grant is set, or takes it away otherwise. A roleID of 0
+	// leaves roles alone. It reports false when the status had changed.
+	Review(application *model.ResellerApplication, from string, roleID int, grant bool) (bool, error)
+}
+
+type resellerRepository struct {
+	db *gorm.DB
+}
+
+func NewResellerRepository(db *gorm.DB) ResellerRepository {
+	return &resellerRepository{db: db}
+}
+
+func (r *resellerRepository) Create(application *model.ResellerApplication) error {
+	return r.db.Omit("User").Create(application).Error
+}
+
+func (r *resellerRepository) GetByID(id int) (*model.ResellerApplication, error) {
+	var application model.ResellerApplication
+	err := r.db.Preload("User").First(&application, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func (r *resellerRepository) GetLatestByUserID(userID int) (*model.ResellerApplication, error) {
+	var application model.ResellerApplication
+	err := r.db.Preload("User").Where("id_user = ?", userID).Order("id DESC").First(&application).Error
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+// HasOpenApplication reports whether the user has an application that is
+// still pending or has been approved.
+func (r *resellerRepository) HasOpenApplication(userID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.ResellerApplication{}).
+		Where("id_user = ? AND status IN ?", userID, []string{constants.ResellerPending, constants.ResellerApproved}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetAll lists applications, oldest first, optionally filtered by status.
+func (r *resellerRepository) GetAll(status string) ([]model.ResellerApplication, error) {
+	var applications []model.ResellerApplication
+	query := r.db.Preload("User").Order("id ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&applications).Error
+	return applications, err
+}
+
+// errApplicationReviewed rolls back a review that lost to another one
+var errApplicationReviewed = errors.New("application already reviewed")
+
+func (r *resellerRepository) Review(application *model.ResellerApplication, from string, roleID int, grant bool) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.ResellerApplication{}).
+			Where("id = ? AND status = ?", application.ID, from).
+			Updates(map[string]interface{}{
+				"status":      application.Status,
+				"catatan":     application.Catatan,
+				"reviewed_by": application.ReviewedBy,
+				"reviewed_at": application.ReviewedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errApplicationReviewed
+		}
+
+		if roleID == 0 {
+			return nil
+		}
+		if grant {
+			return tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&model.UserRole{IDUser: application.IDUser, IDRole: roleID}).Error
+		}
+		return tx.Where("id_user = ? AND id_role = ?", application.IDUser, roleID).Delete(&model.UserRole{}).Error
+	})
+	if errors.Is(err, errApplicationReviewed) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepository interface {
+	GetAll() ([]model.Role, error)
+	GetByName(name string) (*model.Role, error)
+	GetByNames(names []string) ([]model.Role, error)
+	GetByUserID(userID int) ([]model.Role, error)
+	SetUserRoles(userID int, roleIDs []int) error
+	SavePermission(permission *model.Permission) error
+	SaveRole(role *model.Role) error
+	AddPermissions(role *model.Role, permissions []model.Permission) error
+	AssignRoleToAdmins(roleID int) error
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) GetAll() ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.Preload("Permissions").Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) GetByName(name string) (*model.Role, error) {
+	var role model.Role
+	err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) GetByNames(names []string) ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.Preload("Permissions").Where("name IN ?", names).Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) GetByUserID(userID int) ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.id_role = roles.id").
+		Where("user_roles.id_user = ?", userID).
+		Order("roles.id ASC").
+		Find(&roles).Error
+	return roles, err
+}
+
+// SetUserRoles replaces the explicit role assignments of a user.
+func (r *roleRepository) SetUserRoles(userID int, roleIDs []int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_user = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+		if len(roleIDs) == 0 {
+			return nil
+		}
+
+		userRoles := make([]model.UserRole, 0, len(roleIDs))
+		for _, roleID := range roleIDs {
+			userRoles = append(userRoles, model.UserRole{IDUser: userID, IDRole: roleID})
+		}
+		return tx.Create(&userRoles).Error
+	})
+}
+
+// SavePermission creates the permission if needed and refreshes its description.
+func (r *roleRepository) SavePermission(permission *model.Permission) error {
+	return r.db.Where(model.Permission{Name: permission.Name}).
+		Assign(model.Permission{Description: permission.Description}).
+		FirstOrCreate(permission).Error
+}
+
+// SaveRole creates the role if needed and refreshes its description and 2FA flag.
+func (r *roleRepository) SaveRole(role *model.Role) error {
+	return r.db.Where(model.Role{Name: role.Name}).
+		Assign(map[string]interface{}{
+			"description":         role.Description,
+			"requires_two_factor": role.RequiresTwoFactor,
+		}).
+		FirstOrCreate(role).Error
+}
+
+// AddPermissions grants permissions to a role, keeping any it already has.
+func (r *roleRepository) AddPermissions(role *model.Role, permissions []model.Permission) error {
+	return r.db.Model(role).Omit("Permissions.*").Association("Permissions").Append(permissions)
+}
+
+// AssignRoleToAdmins gives the role to every user still flagged with the
+// legacy isAdmin column.
+func (r *roleRepository) AssignRoleToAdmins(roleID int) error {
+	var userIDs []int
+	if err := r.db.Model(&model.User{}).Where("isAdmin = ?", true).Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	userRoles := make([]model.UserRole, 0, len(userIDs))
+	for _, userID := range userIDs {
+		userRoles = append(userRoles, model.UserRole{IDUser: userID, IDRole: roleID, CreatedAt: now})
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&userRoles).Error
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+type ShopMemberRepository interface {
+	GetMember(shopID, userID int) (*model.ShopMember, error)
+	GetMembers(shopID int) ([]model.ShopMember, error)
+	GetByUserID(userID int) ([]model.ShopMember, error)
+	UpdateMember(member *model.ShopMember) error
+	DeleteMember(shopID, userID int) (bool, error)
+	CreateInvitation(invitation *model.ShopInvitation) error
+	GetInvitation(shopID, invitationID int) (*model.ShopInvitation, error)
+	GetInvitationByTokenHash(tokenHash string) (*model.ShopInvitation, error)
+	GetPendingInvitations(shopID int) ([]model.ShopInvitation, error)
+	UpdateInvitation(invitation *model.ShopInvitation) error
+	RevokePendingInvitations(shopID int, email string) error
+	AcceptInvitation(invitation *model.ShopInvitation, member *model.ShopMember) error
+}
+
+type shopMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewShopMemberRepository(db *gorm.DB) ShopMemberRepository {
+	return &shopMemberRepository{db: db}
+}
+
+func (r *shopMemberRepository) GetMember(shopID, userID int) (*model.ShopMember, error) {
+	var member model.ShopMember
+	err := r.db.Preload("User").Where("id_toko = ? AND id_user = ?", shopID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *shopMemberRepository) GetMembers(shopID int) ([]model.ShopMember, error) {
+	var members []model.ShopMember
+	err := r.db.Preload("User").Where("id_toko = ?", shopID).Order("created_at ASC").Find(&members).Error
+	return members, err
+}
+
+func (r *shopMemberRepository) GetByUserID(userID int) ([]model.ShopMember, error) {
+	var members []model.ShopMember
+	err := r.db.Preload("Toko").Where("id_user = ?", userID).Order("created_at ASC").Find(&members).Error
+	return members, err
+}
+
+func (r *shopMemberRepository) UpdateMember(member *model.ShopMember) error {
+	return r.db.Save(member).Error
+}
+
+func (r *shopMemberRepository) DeleteMember(shopID, userID int) (bool, error) {
+	result := r.db.Where("id_toko = ? AND id_user = ?", shopID, userID).Delete(&model.ShopMember{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *shopMemberRepository) CreateInvitation(invitation *model.ShopInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *shopMemberRepository) GetInvitation(shopID, invitationID int) (*model.ShopInvitation, error) {
+	var invitation model.ShopInvitation
+	err := r.db.Where("id_toko = ? AND id = ?", shopID, invitationID).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *shopMemberRepository) GetInvitationByTokenHash(tokenHash string) (*model.ShopInvitation, error) {
+	var invitation model.ShopInvitation
+	err := r.db.Preload("Toko").Where("token_hash = ?", tokenHash).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *shopMemberRepository) GetPendingInvitations(shopID int) ([]model.ShopInvitation, error) {
+	var invitations []model.ShopInvitation
+	err := r.db.Where("id_toko = ? AND status = ? AND expires_at > ?", shopID, constants.ShopInvitationPending, time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *shopMemberRepository) UpdateInvitation(invitation *model.ShopInvitation) error {
+	return r.db.Save(invitation).Error
+}
+
+// RevokePendingInvitations revokes outstanding invitations for an email so
+// only the newest one can be accepted.
+func (r *shopMemberRepository) RevokePendingInvitations(shopID int, email string) error {
+	return r.db.Model(&model.ShopInvitation{}).
+		Where("id_toko = ? AND email = ? AND status = ?", shopID, email, constants.ShopInvitationPending).
+		Updates(map[string]interface{}{"status": constants.ShopInvitationRevoked, "responded_at": time.Now()}).Error
+}
+
+// AcceptInvitation marks a pending invitation as accepted and creates the
+// membership in one transaction. An invitation can only be accepted once.
+func (r *shopMemberRepository) AcceptInvitation(invitation *model.ShopInvitation, member *model.ShopMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.ShopInvitation{}).
+			Where("id = ? AND status = ?", invitation.ID, constants.ShopInvitationPending).
+			Updates(map[string]interface{}{"status": constants.ShopInvitationAccepted, "responded_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		invitation.Status = constants.ShopInvitationAccepted
+		invitation.RespondedAt = &now
+		return tx.Create(member).Error
+	})
+}
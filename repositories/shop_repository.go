@@ -1,16 +1,25 @@
 package repositories
 
 import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/rdsarjito/marketplace-backend/constants"
 	"github.com/rdsarjito/marketplace-backend/domain/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ShopRepository interface {
 	Create(shop *model.Shop) error
 	GetByID(id int) (*model.Shop, error)
 	GetByUserID(userID int) (*model.Shop, error)
+	GetByHandle(handle string) (*model.Shop, error)
+	HandleExists(handle string, exceptID int) (bool, error)
 	GetAll() ([]model.Shop, error)
 	Update(shop *model.Shop) error
+	ReplaceOpeningHours(shopID int, hours []model.ShopOpeningHour) error
 	Delete(id int) error
 }
 
@@ -23,12 +32,12 @@ func NewShopRepository(db *gorm.DB) ShopRepository {
 }
 
 func (r *shopRepository) Create(shop *model.Shop) error {
-	return r.db.Create(shop).Error
+	return shopDuplicateError(r.db.Create(shop).Error)
 }
 
 func (r *shopRepository) GetByID(id int) (*model.Shop, error) {
 	var shop model.Shop
-	err := r.db.Preload("User").First(&shop, id).Error
+	err := preloadShopDetails(r.db).First(&shop, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -37,23 +46,76 @@ func (r *shopRepository) GetByID(id int) (*model.Shop, error) {
 
 func (r *shopRepository) GetByUserID(userID int) (*model.Shop, error) {
 	var shop model.Shop
-	err := r.db.Preload("User").Where("id_user = ?", userID).First(&shop).Error
+	err := preloadShopDetails(r.db).Where("id_user = ?", userID).First(&shop).Error
 	if err != nil {
 		return nil, err
 	}
 	return &shop, nil
 }
 
+func (r *shopRepository) GetByHandle(handle string) (*model.Shop, error) {
+	var shop model.Shop
+	err := preloadShopDetails(r.db).Where("url_toko = ?", handle).First(&shop).Error
+	if err != nil {
+		return nil, err
+	}
+	return &shop, nil
+}
+
+// HandleExists reports whether a shop other than exceptID uses handle.
+// Deleted shops keep their handle, so they are included.
+func (r *shopRepository) HandleExists(handle string, exceptID int) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&model.Shop{}).Where("url_toko = ? AND id <> ?", handle, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
 func (r *shopRepository) GetAll() ([]model.Shop, error) {
 	var shops []model.Shop
-	err := r.db.Preload("User").Find(&shops).Error
+	err := preloadShopDetails(r.db).Find(&shops).Error
 	return shops, err
 }
 
+// Update saves the shop's own columns. Opening hours are changed with
+// ReplaceOpeningHours and the verification status through
+// ShopVerificationRepository.
 func (r *shopRepository) Update(shop *model.Shop) error {
-	return r.db.Save(shop).Error
+	return shopDuplicateError(r.db.Omit(clause.Associations, "StatusVerifikasi").Save(shop).Error)
+}
+
+func (r *shopRepository) ReplaceOpeningHours(shopID int, hours []model.ShopOpeningHour) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_toko = ?", shopID).Delete(&model.ShopOpeningHour{}).Error; err != nil {
+			return err
+		}
+		if len(hours) == 0 {
+			return nil
+		}
+		return tx.Create(&hours).Error
+	})
 }
 
 func (r *shopRepository) Delete(id int) error {
 	return r.db.Delete(&model.Shop{}, id).Error
 }
+
+// shopDuplicateError reports a shop that lost a race for its owner or
+// handle the same way as the checks made before saving it.
+func shopDuplicateError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1062 {
+		return err
+	}
+	switch {
+	case strings.Contains(mysqlErr.Message, "idx_toko_id_user"):
+		return errors.New(constants.ErrShopAlreadyExists)
+	case strings.Contains(mysqlErr.Message, "idx_toko_url_toko"):
+		return errors.New(constants.ErrShopHandleTaken)
+	}
+	return err
+}
+
+func preloadShopDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").
+		Preload("JamOperasional", func(db *gorm.DB) *gorm.DB { return db.Order("hari ASC") })
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+type ShopVerificationRepository interface {
+	// Create saves a verification request with its documents and sets the
+	// shop's status to the request's.
+	Create(verification *model.ShopVerification) error
+	GetByID(id int) (*model.ShopVerification, error)
+	GetLatestByShopID(shopID int) (*model.ShopVerification, error)
+	GetAll(status string) ([]model.ShopVerification, error)
+	// Update saves a reviewed request and sets the shop's status to the
+	// request's.
+	Update(verification *model.ShopVerification) error
+}
+
+type shopVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewShopVerificationRepository(db *gorm.DB) ShopVerificationRepository {
+	return &shopVerificationRepository{db: db}
+}
+
+func (r *shopVerificationRepository) Create(verification *model.ShopVerification) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Toko", "User", "Dokumen.Media").Create(verification).Error; err != nil {
+			return err
+		}
+		return setShopVerificationStatus(tx, verification)
+	})
+}
+
+func (r *shopVerificationRepository) GetByID(id int) (*model.ShopVerification, error) {
+	var verification model.ShopVerification
+	err := preloadShopVerification(r.db).First(&verification, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+func (r *shopVerificationRepository) GetLatestByShopID(shopID int) (*model.ShopVerification, error) {
+	var verification model.ShopVerification
+	err := preloadShopVerification(r.db).Where("id_toko = ?", shopID).Order("id DESC").First(&verification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// GetAll lists requests with the given status, or all of them, oldest
+// first so the review queue is worked in order.
+func (r *shopVerificationRepository) GetAll(status string) ([]model.ShopVerification, error) {
+	var verifications []model.ShopVerification
+	query := preloadShopVerification(r.db)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("id ASC").Find(&verifications).Error
+	return verifications, err
+}
+
+func (r *shopVerificationRepository) Update(verification *model.ShopVerification) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Toko", "User", "Dokumen").Save(verification).Error; err != nil {
+			return err
+		}
+		return setShopVerificationStatus(tx, verification)
+	})
+}
+
+func setShopVerificationStatus(tx *gorm.DB, verification *model.ShopVerification) error {
+	return tx.Model(&model.Shop{}).Where("id = ?", verification.IDToko).
+		Update("status_verifikasi", verification.Status).Error
+}
+
+func preloadShopVerification(db *gorm.DB) *gorm.DB {
+	return db.Preload("Toko").Preload("User").Preload("Dokumen.Media")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/rdsarjito/marketplace-backend/constants"
 	"github.com/rdsarjito/marketplace-backend/domain/model"
 	"gorm.io/gorm"
 )
@@ -12,11 +13,20 @@ type TRXRepository interface {
 	Create(trx *model.TRX) error
 	GetByID(id int) (*model.TRX, error)
 	GetByUserID(userID int) ([]model.TRX, error)
+	GetByShopID(shopID int) ([]model.TRX, error)
 	GetByInvoiceCode(invoiceCode string) (*model.TRX, error)
 	Update(trx *model.TRX) error
 	UpdatePaymentStatus(trxID int, paymentStatus string, paymentToken, paymentURL, midtransOrderID string, paymentExpiredAt *time.Time, paymentVANumbersJSON, paymentActionsJSON, paymentQRString string) error
 	Delete(id int) error
 	CreateDetail(detail *model.DetailTRX) error
+	// MarkPaid records when the order was paid, unless it already was.
+	MarkPaid(trxID int, paidAt time.Time) error
+	// Complete marks a paid order completed, and reports whether it was
+	// still open.
+	Complete(trxID int, completedAt time.Time) (bool, error)
+	// GetDueForCompletion returns open orders paid before paidBefore, in ID
+	// order starting after afterID.
+	GetDueForCompletion(paidBefore time.Time, afterID, limit int) ([]model.TRX, error)
 }
 
 type trxRepository struct {
@@ -33,7 +43,7 @@ func (r *trxRepository) Create(trx *model.TRX) error {
 
 func (r *trxRepository) GetByID(id int) (*model.TRX, error) {
 	var trx model.TRX
-	err := r.db.Preload("User").Preload("Address").Preload("DetailTRX.Product").Preload("DetailTRX.Shop").First(&trx, id).Error
+	err := r.db.Preload("User").Preload("Address").Preload("DetailTRX.Product").Preload("DetailTRX.LogProduk").Scopes(preloadDetailVariant).Preload("DetailTRX.Shop").First(&trx, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -42,13 +52,25 @@ func (r *trxRepository) GetByID(id int) (*model.TRX, error) {
 
 func (r *trxRepository) GetByUserID(userID int) ([]model.TRX, error) {
 	var trxs []model.TRX
-	err := r.db.Preload("User").Preload("Address").Preload("DetailTRX.Product").Preload("DetailTRX.Shop").Where("id_user = ?", userID).Find(&trxs).Error
+	err := r.db.Preload("User").Preload("Address").Preload("DetailTRX.Product").Preload("DetailTRX.LogProduk").Scopes(preloadDetailVariant).Preload("DetailTRX.Shop").Where("id_user = ?", userID).Find(&trxs).Error
+	return trxs, err
+}
+
+// GetByShopID returns transactions containing items from the shop, with only
+// that shop's detail lines loaded. The buyer's account is not loaded.
+func (r *trxRepository) GetByShopID(shopID int) ([]model.TRX, error) {
+	var trxs []model.TRX
+	err := r.db.Preload("Address").
+		Preload("DetailTRX", "id_toko = ?", shopID).Preload("DetailTRX.Product").Preload("DetailTRX.LogProduk").Scopes(preloadDetailVariant).Preload("DetailTRX.Shop").
+		Where("id IN (?)", r.db.Model(&model.DetailTRX{}).Select("id_trx").Where("id_toko = ?", shopID)).
+		Order("created_at DESC").
+		Find(&trxs).Error
 	return trxs, err
 }
 
 func (r *trxRepository) GetByInvoiceCode(invoiceCode string) (*model.TRX, error) {
 	var trx model.TRX
-	err := r.db.Preload("User").Preload("Address").Preload("DetailTRX.Product").Preload("DetailTRX.Shop").Where("kode_invoice = ?", invoiceCode).First(&trx).Error
+	err := r.db.Preload("User").Preload("Address").Preload("DetailTRX.Product").Preload("DetailTRX.LogProduk").Scopes(preloadDetailVariant).Preload("DetailTRX.Shop").Where("kode_invoice = ?", invoiceCode).First(&trx).Error
 	if err != nil {
 		return nil, err
 	}
@@ -100,3 +122,29 @@ func (r *trxRepository) Delete(id int) error {
 func (r *trxRepository) CreateDetail(detail *model.DetailTRX) error {
 	return r.db.Create(detail).Error
 }
+
+func (r *trxRepository) MarkPaid(trxID int, paidAt time.Time) error {
+	return r.db.Model(&model.TRX{}).Where("id = ? AND dibayar_at IS NULL", trxID).Update("dibayar_at", paidAt).Error
+}
+
+func (r *trxRepository) Complete(trxID int, completedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.TRX{}).
+		Where("id = ? AND payment_status = ? AND selesai_at IS NULL", trxID, constants.PaymentStatusPaid).
+		Update("selesai_at", completedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *trxRepository) GetDueForCompletion(paidBefore time.Time, afterID, limit int) ([]model.TRX, error) {
+	var trxs []model.TRX
+	err := r.db.Where("payment_status = ? AND selesai_at IS NULL AND dibayar_at < ? AND id > ?", constants.PaymentStatusPaid, paidBefore, afterID).
+		Order("id").Limit(limit).Find(&trxs).Error
+	return trxs, err
+}
+
+// preloadDetailVariant loads the purchased variant of each detail line,
+// including variants deleted since.
+func preloadDetailVariant(db *gorm.DB) *gorm.DB {
+	return db.Preload("DetailTRX.Variant", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("DetailTRX.Variant.OptionValues.Option").
+		Preload("DetailTRX.Variant.Foto")
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	GetByUserID(userID int) (*model.UserTwoFactor, error)
+	Save(twoFactor *model.UserTwoFactor) error
+	Delete(userID int) error
+	UpdateLastUsedStep(userID int, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID int, codes []model.RecoveryCode) error
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(userID int) (int64, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) GetByUserID(userID int) (*model.UserTwoFactor, error) {
+	var twoFactor model.UserTwoFactor
+	err := r.db.Where("id_user = ?", userID).First(&twoFactor).Error
+	if err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Save(twoFactor *model.UserTwoFactor) error {
+	return r.db.Save(twoFactor).Error
+}
+
+// Delete removes the enrollment together with its recovery codes.
+func (r *twoFactorRepository) Delete(userID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_user = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id_user = ?", userID).Delete(&model.UserTwoFactor{}).Error
+	})
+}
+
+// UpdateLastUsedStep records the TOTP step just accepted. It returns false if
+// the step was already used, which prevents replaying a code.
+func (r *twoFactorRepository) UpdateLastUsedStep(userID int, step int64) (bool, error) {
+	result := r.db.Model(&model.UserTwoFactor{}).
+		Where("id_user = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID int, codes []model.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_user = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks a matching unused code as used and reports whether one was found.
+func (r *twoFactorRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	result := r.db.Model(&model.RecoveryCode{}).
+		Where("id_user = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *twoFactorRepository) CountUnusedRecoveryCodes(userID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.RecoveryCode{}).Where("id_user = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,184 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/analysis/lang/id"
+)
+
+// Terms at least this long are matched with an edit distance of one.
+const typoMinLength = 5
+
+// synonymGroups lists interchangeable catalog terms. Queries are expanded
+// with the other members of a term's group.
+var synonymGroups = [][]string{
+	{"hp", "handphone", "ponsel", "smartphone"},
+	{"kaos", "baju", "atasan", "tshirt"},
+	{"celana", "pants"},
+	{"sepatu", "shoes", "sneakers"},
+	{"tas", "bag", "ransel"},
+	{"jaket", "jacket", "hoodie"},
+	{"laptop", "notebook"},
+	{"sabun", "soap"},
+	{"murah", "hemat", "diskon"},
+}
+
+var (
+	synonyms  = buildSynonyms(synonymGroups)
+	stopWords = loadStopWords()
+)
+
+// queryTerm is one word of a search query together with the alternatives
+// (itself, its stem and synonyms) any of which may match.
+type queryTerm struct {
+	Word         string
+	Alternatives []string
+}
+
+// parseQuery splits a user query into terms, dropping stop words and
+// expanding each term with its stem and synonyms.
+func parseQuery(text string) []queryTerm {
+	var terms []queryTerm
+	for _, word := range tokenize(text) {
+		if utf8.RuneCountInString(word) < 2 || stopWords[word] {
+			continue
+		}
+
+		alternatives := []string{word}
+		seen := map[string]bool{word: true}
+		add := func(alt string) {
+			if !seen[alt] {
+				seen[alt] = true
+				alternatives = append(alternatives, alt)
+			}
+		}
+
+		stem := StemIndonesian(word)
+		add(stem)
+		for _, synonym := range synonyms[word] {
+			add(synonym)
+		}
+		for _, synonym := range synonyms[stem] {
+			add(synonym)
+		}
+
+		terms = append(terms, queryTerm{Word: word, Alternatives: alternatives})
+	}
+	return terms
+}
+
+// tokenize lowercases text and splits it on anything but letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func buildSynonyms(groups [][]string) map[string][]string {
+	index := make(map[string][]string)
+	for _, group := range groups {
+		for _, term := range group {
+			for _, other := range group {
+				if other != term {
+					index[term] = append(index[term], other)
+				}
+			}
+		}
+	}
+	return index
+}
+
+func loadStopWords() map[string]bool {
+	tokenMap := analysis.NewTokenMap()
+	if err := tokenMap.LoadBytes(id.IndonesianStopWords); err != nil {
+		panic(err)
+	}
+
+	words := make(map[string]bool, len(tokenMap))
+	for word := range tokenMap {
+		words[word] = true
+	}
+	return words
+}
+
+// highlight wraps words starting with any of the prefixes in <mark> tags.
+// Long text is cut to a fragment around the first match. The result is
+// HTML-escaped; ok is false when nothing matched.
+func highlight(text string, prefixes []string, fragmentSize int) (string, bool) {
+	type span struct{ start, end int }
+
+	var matches []span
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := strings.ToLower(text[start:end])
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(word, prefix) {
+				matches = append(matches, span{start, end})
+				break
+			}
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+		} else {
+			flush(i)
+		}
+	}
+	flush(len(text))
+
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	from, to := 0, len(text)
+	if fragmentSize > 0 && len(text) > fragmentSize {
+		from = matches[0].start - fragmentSize/4
+		if from < 0 {
+			from = 0
+		}
+		to = from + fragmentSize
+		if to > len(text) {
+			to = len(text)
+		}
+		// Keep the cut on rune boundaries
+		for from > 0 && !utf8.RuneStart(text[from]) {
+			from--
+		}
+		for to < len(text) && !utf8.RuneStart(text[to]) {
+			to++
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	curr := from
+	for _, m := range matches {
+		if m.start < from || m.end > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[curr:m.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[m.start:m.end]))
+		b.WriteString("</mark>")
+		curr = m.end
+	}
+	b.WriteString(html.EscapeString(text[curr:to]))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String(), true
+}
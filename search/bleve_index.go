@@ -0,0 +1,230 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/lang/id"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/registry"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+const (
+	indonesianAnalyzer = "indonesian"
+	stemFilterName     = "stem_id"
+)
+
+// Fail fast instead of waiting forever when another process holds the index
+const bleveOpenTimeout = "2s"
+
+// Relative weight of a match in the product name over the description
+const nameBoost = 2.0
+
+func init() {
+	if err := registry.RegisterTokenFilter(stemFilterName, func(config map[string]interface{}, cache *registry.Cache) (analysis.TokenFilter, error) {
+		return indonesianStemFilter{}, nil
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// indonesianStemFilter applies StemIndonesian to every token.
+type indonesianStemFilter struct{}
+
+func (indonesianStemFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	for _, token := range input {
+		token.Term = []byte(StemIndonesian(string(token.Term)))
+	}
+	return input
+}
+
+// bleveIndex is an embedded on-disk Bleve index. Text is analyzed with
+// Indonesian stop words and stemming; queries add synonyms and fuzzy
+// matching for typos.
+type bleveIndex struct {
+	mu    sync.RWMutex
+	path  string
+	index bleve.Index
+}
+
+// NewBleveIndex opens the Bleve index at path, creating it if it does not
+// exist yet. Run the reindex command to fill a new index.
+func NewBleveIndex(path string) (SearchIndex, error) {
+	index, err := openBleve(path)
+	if err != nil {
+		return nil, err
+	}
+	return &bleveIndex{path: path, index: index}, nil
+}
+
+func (i *bleveIndex) Index(doc Document) error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.index.Index(strconv.Itoa(doc.ID), bleveDocument(doc))
+}
+
+func (i *bleveIndex) Delete(id int) error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.index.Delete(strconv.Itoa(id))
+}
+
+func (i *bleveIndex) Search(text string, limit int) ([]Hit, error) {
+	terms := parseQuery(text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	// Every term must match, in either field, through one of its alternatives
+	all := bleve.NewConjunctionQuery()
+	for _, term := range terms {
+		either := bleve.NewDisjunctionQuery()
+		for _, alt := range term.Alternatives {
+			either.AddQuery(matchQuery(alt, "nama_produk", nameBoost))
+			either.AddQuery(matchQuery(alt, "deskripsi", 1))
+		}
+		all.AddQuery(either)
+	}
+
+	req := bleve.NewSearchRequestOptions(all, limit, 0, false)
+	req.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	req.Highlight.AddField("nama_produk")
+	req.Highlight.AddField("deskripsi")
+
+	i.mu.RLock()
+	result, err := i.index.Search(req)
+	i.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, 0, len(result.Hits))
+	for _, match := range result.Hits {
+		id, err := strconv.Atoi(match.ID)
+		if err != nil {
+			continue
+		}
+		hits = append(hits, Hit{ID: id, Score: match.Score, Highlights: match.Fragments})
+	}
+	return hits, nil
+}
+
+// Rebuild writes a fresh index next to the live one and swaps it in, so
+// searches keep working while it runs. Changes indexed in the meantime go to
+// the old index and are lost with it.
+func (i *bleveIndex) Rebuild(load func(add func(docs []Document) error) error) error {
+	tmpPath := i.path + ".rebuild"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	}
+
+	fresh, err := bleve.New(tmpPath, newProductMapping())
+	if err != nil {
+		return err
+	}
+
+	err = load(func(docs []Document) error {
+		batch := fresh.NewBatch()
+		for _, doc := range docs {
+			if err := batch.Index(strconv.Itoa(doc.ID), bleveDocument(doc)); err != nil {
+				return err
+			}
+		}
+		return fresh.Batch(batch)
+	})
+	if closeErr := fresh.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if err := i.index.Close(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(i.path); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, i.path); err != nil {
+		return err
+	}
+
+	i.index, err = openBleve(i.path)
+	return err
+}
+
+func (i *bleveIndex) Close() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.index.Close()
+}
+
+func openBleve(path string) (bleve.Index, error) {
+	index, err := bleve.OpenUsing(path, map[string]interface{}{"bolt_timeout": bleveOpenTimeout})
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		return bleve.New(path, newProductMapping())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open search index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+func newProductMapping() mapping.IndexMapping {
+	indexMapping := bleve.NewIndexMapping()
+	if err := indexMapping.AddCustomAnalyzer(indonesianAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{lowercase.Name, id.StopName, stemFilterName},
+	}); err != nil {
+		panic(err)
+	}
+	indexMapping.DefaultAnalyzer = indonesianAnalyzer
+
+	text := bleve.NewTextFieldMapping()
+	text.Analyzer = indonesianAnalyzer
+
+	product := bleve.NewDocumentStaticMapping()
+	product.AddFieldMappingsAt("nama_produk", text)
+	product.AddFieldMappingsAt("deskripsi", text)
+
+	indexMapping.DefaultMapping = product
+	return indexMapping
+}
+
+func bleveDocument(doc Document) map[string]interface{} {
+	return map[string]interface{}{
+		"nama_produk": doc.NamaProduk,
+		"deskripsi":   doc.Deskripsi,
+	}
+}
+
+func matchQuery(text, field string, boost float64) query.Query {
+	q := bleve.NewMatchQuery(text)
+	q.SetField(field)
+	q.SetBoost(boost)
+	if utf8.RuneCountInString(text) >= typoMinLength {
+		q.SetFuzziness(1)
+		q.SetPrefix(1)
+	}
+	return q
+}
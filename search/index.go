@@ -0,0 +1,76 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+// Supported SEARCH_ENGINE values
+const (
+	EngineMySQL = "mysql"
+	EngineBleve = "bleve"
+)
+
+const defaultBleveIndexPath = "data/search/products.bleve"
+
+// SearchIndex is a full-text index over the product catalog. It only ranks
+// product IDs; filtering, sorting and loading stay in ProductRepository.
+type SearchIndex interface {
+	// Index adds or replaces a product document.
+	Index(doc Document) error
+	// Delete removes a product from the index.
+	Delete(id int) error
+	// Search returns up to limit matches for text, best match first.
+	Search(text string, limit int) ([]Hit, error)
+	// Rebuild recreates the index from scratch. load is called once and must
+	// pass every product to add, in batches.
+	Rebuild(load func(add func(docs []Document) error) error) error
+	Close() error
+}
+
+// Document is the searchable representation of a product.
+type Document struct {
+	ID         int
+	NamaProduk string
+	Deskripsi  string
+}
+
+// Hit is a single search match. Highlights maps field names (nama_produk,
+// deskripsi) to HTML fragments with matches wrapped in <mark>.
+type Hit struct {
+	ID         int
+	Score      float64
+	Highlights map[string][]string
+}
+
+// NewSearchIndexFromEnv initializes the SearchIndex selected by SEARCH_ENGINE
+// (mysql by default). The Bleve index is stored at SEARCH_INDEX_PATH.
+func NewSearchIndexFromEnv(db *gorm.DB) (SearchIndex, error) {
+	engine := strings.ToLower(strings.TrimSpace(os.Getenv("SEARCH_ENGINE")))
+
+	switch engine {
+	case "", EngineMySQL:
+		return NewMySQLIndex(db)
+	case EngineBleve:
+		path := strings.TrimSpace(os.Getenv("SEARCH_INDEX_PATH"))
+		if path == "" {
+			path = defaultBleveIndexPath
+		}
+		return NewBleveIndex(path)
+	default:
+		return nil, fmt.Errorf("unknown SEARCH_ENGINE %q (use %s or %s)", engine, EngineMySQL, EngineBleve)
+	}
+}
+
+// DocumentFromProduct maps a product to its search document.
+func DocumentFromProduct(product model.Product) Document {
+	return Document{
+		ID:         product.ID,
+		NamaProduk: product.NamaProduk,
+		Deskripsi:  product.Deskripsi,
+	}
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"gorm.io/gorm"
+)
+
+const mysqlFulltextIndex = "idx_produk_fulltext"
+
+// Characters around the first match kept in a deskripsi highlight
+const highlightFragmentSize = 160
+
+// mysqlIndex searches the produk table through an InnoDB FULLTEXT index.
+// MySQL keeps the index up to date itself, so Index and Delete are no-ops.
+// Stemming and synonyms are applied by expanding the boolean-mode query;
+// typo tolerance is not available with this engine.
+type mysqlIndex struct {
+	db *gorm.DB
+}
+
+// NewMySQLIndex returns a SearchIndex backed by MySQL FULLTEXT, creating
+// the index on produk(nama_produk, deskripsi) if needed.
+func NewMySQLIndex(db *gorm.DB) (SearchIndex, error) {
+	index := &mysqlIndex{db: db}
+	if !db.Migrator().HasIndex(&model.Product{}, mysqlFulltextIndex) {
+		if err := index.createFulltextIndex(); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+func (i *mysqlIndex) Index(doc Document) error {
+	return nil
+}
+
+func (i *mysqlIndex) Delete(id int) error {
+	return nil
+}
+
+func (i *mysqlIndex) Search(text string, limit int) ([]Hit, error) {
+	terms := parseQuery(text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	// Every term must match through one of its alternatives. Stems are
+	// prefix-matched so "sepatunya" still finds "sepatu".
+	var clauses []string
+	var prefixes []string
+	for _, term := range terms {
+		var alternatives []string
+		for _, alt := range term.Alternatives {
+			for _, word := range tokenize(alt) {
+				alternatives = append(alternatives, word+"*")
+				prefixes = append(prefixes, word)
+			}
+		}
+		clauses = append(clauses, "+("+strings.Join(alternatives, " ")+")")
+	}
+	booleanQuery := strings.Join(clauses, " ")
+
+	var rows []struct {
+		ID         int
+		NamaProduk string
+		Deskripsi  string
+		Score      float64
+	}
+	err := i.db.Model(&model.Product{}).
+		Select("id, nama_produk, deskripsi, MATCH(nama_produk, deskripsi) AGAINST (? IN BOOLEAN MODE) AS score", booleanQuery).
+		Where("MATCH(nama_produk, deskripsi) AGAINST (? IN BOOLEAN MODE)", booleanQuery).
+		Order("score DESC").Order("id DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, 0, len(rows))
+	for _, row := range rows {
+		hit := Hit{ID: row.ID, Score: row.Score, Highlights: map[string][]string{}}
+		if fragment, ok := highlight(row.NamaProduk, prefixes, 0); ok {
+			hit.Highlights["nama_produk"] = []string{fragment}
+		}
+		if fragment, ok := highlight(row.Deskripsi, prefixes, highlightFragmentSize); ok {
+			hit.Highlights["deskripsi"] = []string{fragment}
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// Rebuild drops and recreates the FULLTEXT index; the documents themselves
+// are read by MySQL from the produk table.
+func (i *mysqlIndex) Rebuild(load func(add func(docs []Document) error) error) error {
+	if i.db.Migrator().HasIndex(&model.Product{}, mysqlFulltextIndex) {
+		if err := i.db.Migrator().DropIndex(&model.Product{}, mysqlFulltextIndex); err != nil {
+			return err
+		}
+	}
+	return i.createFulltextIndex()
+}
+
+func (i *mysqlIndex) Close() error {
+	return nil
+}
+
+func (i *mysqlIndex) createFulltextIndex() error {
+	return i.db.Exec("CREATE FULLTEXT INDEX " + mysqlFulltextIndex + " ON produk (nama_produk, deskripsi)").Error
+}
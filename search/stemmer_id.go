@@ -0,0 +1,193 @@
+package search
+
+import "strings"
+
+// StemIndonesian reduces an Indonesian word to its root by removing
+// particles, possessive pronouns, derivational prefixes and suffixes. It is
+// a port of the algorithm used by Lucene's IndonesianStemmer (Tala, 2003).
+func StemIndonesian(word string) string {
+	s := &indonesianStemmer{word: []rune(strings.ToLower(word))}
+	s.syllables = s.countSyllables()
+
+	if s.syllables > 2 {
+		s.removeParticle()
+	}
+	if s.syllables > 2 {
+		s.removePossessivePronoun()
+	}
+	s.stemDerivational()
+
+	return string(s.word)
+}
+
+const (
+	removedKe = 1 << iota
+	removedPeng
+	removedDi
+	removedMeng
+	removedTer
+	removedBer
+	removedPe
+)
+
+type indonesianStemmer struct {
+	word      []rune
+	syllables int
+	flags     int
+}
+
+func (s *indonesianStemmer) stemDerivational() {
+	before := len(s.word)
+	if s.syllables > 2 {
+		s.removeFirstOrderPrefix()
+	}
+
+	if len(s.word) != before {
+		// A first order prefix was removed
+		before = len(s.word)
+		if s.syllables > 2 {
+			s.removeSuffix()
+		}
+		if len(s.word) != before && s.syllables > 2 {
+			s.removeSecondOrderPrefix()
+		}
+		return
+	}
+
+	if s.syllables > 2 {
+		s.removeSecondOrderPrefix()
+	}
+	if s.syllables > 2 {
+		s.removeSuffix()
+	}
+}
+
+func (s *indonesianStemmer) countSyllables() int {
+	count := 0
+	for _, r := range s.word {
+		if isVowel(r) {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *indonesianStemmer) removeParticle() {
+	for _, suffix := range []string{"kah", "lah", "pun"} {
+		if s.hasSuffix(suffix) {
+			s.trimSuffix(3)
+			return
+		}
+	}
+}
+
+func (s *indonesianStemmer) removePossessivePronoun() {
+	switch {
+	case s.hasSuffix("ku"), s.hasSuffix("mu"):
+		s.trimSuffix(2)
+	case s.hasSuffix("nya"):
+		s.trimSuffix(3)
+	}
+}
+
+func (s *indonesianStemmer) removeFirstOrderPrefix() {
+	switch {
+	case s.hasPrefix("meng"):
+		s.flags |= removedMeng
+		s.trimPrefix(4)
+	case s.hasPrefix("meny") && len(s.word) > 4 && isVowel(s.word[4]):
+		s.flags |= removedMeng
+		s.word[3] = 's'
+		s.trimPrefix(3)
+	case s.hasPrefix("men"), s.hasPrefix("mem"):
+		s.flags |= removedMeng
+		s.trimPrefix(3)
+	case s.hasPrefix("me"):
+		s.flags |= removedMeng
+		s.trimPrefix(2)
+	case s.hasPrefix("peng"):
+		s.flags |= removedPeng
+		s.trimPrefix(4)
+	case s.hasPrefix("peny") && len(s.word) > 4 && isVowel(s.word[4]):
+		s.flags |= removedPeng
+		s.word[3] = 's'
+		s.trimPrefix(3)
+	case s.hasPrefix("peny"):
+		s.flags |= removedPeng
+		s.trimPrefix(4)
+	case s.hasPrefix("pen") && len(s.word) > 4 && isVowel(s.word[3]):
+		s.flags |= removedPeng
+		s.word[2] = 't'
+		s.trimPrefix(2)
+	case s.hasPrefix("pen"), s.hasPrefix("pem"):
+		s.flags |= removedPeng
+		s.trimPrefix(3)
+	case s.hasPrefix("di"):
+		s.flags |= removedDi
+		s.trimPrefix(2)
+	case s.hasPrefix("ter"):
+		s.flags |= removedTer
+		s.trimPrefix(3)
+	case s.hasPrefix("ke"):
+		s.flags |= removedKe
+		s.trimPrefix(2)
+	}
+}
+
+func (s *indonesianStemmer) removeSecondOrderPrefix() {
+	switch {
+	case s.hasPrefix("ber"):
+		s.flags |= removedBer
+		s.trimPrefix(3)
+	case string(s.word) == "belajar":
+		s.flags |= removedBer
+		s.trimPrefix(3)
+	case s.hasPrefix("be") && len(s.word) > 4 && !isVowel(s.word[2]) && s.word[3] == 'e' && s.word[4] == 'r':
+		s.flags |= removedBer
+		s.trimPrefix(2)
+	case s.hasPrefix("per"):
+		s.trimPrefix(3)
+	case string(s.word) == "pelajar":
+		s.trimPrefix(3)
+	case s.hasPrefix("pe"):
+		s.flags |= removedPe
+		s.trimPrefix(2)
+	}
+}
+
+func (s *indonesianStemmer) removeSuffix() {
+	switch {
+	case s.hasSuffix("kan") && s.flags&(removedKe|removedPeng|removedPe) == 0:
+		s.trimSuffix(3)
+	case s.hasSuffix("an") && s.flags&(removedDi|removedMeng|removedTer) == 0:
+		s.trimSuffix(2)
+	case s.hasSuffix("i") && !s.hasSuffix("si") && s.flags&(removedBer|removedKe|removedPeng) == 0:
+		s.trimSuffix(1)
+	}
+}
+
+func (s *indonesianStemmer) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(string(s.word), prefix)
+}
+
+func (s *indonesianStemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.word), suffix)
+}
+
+func (s *indonesianStemmer) trimPrefix(n int) {
+	s.word = s.word[n:]
+	s.syllables--
+}
+
+func (s *indonesianStemmer) trimSuffix(n int) {
+	s.word = s.word[:len(s.word)-n]
+	s.syllables--
+}
+
+func isVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/rdsarjito/marketplace-backend/constants"
@@ -16,27 +17,33 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// dummyPasswordHash is compared against when the account has no usable
+// password, so failed logins take the same time whether or not it exists.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+
 type AuthService interface {
 	RegisterUser(req *request.RegisterRequest) (*response.AuthResponse, error)
-	LoginUser(req *request.LoginRequest) (*response.AuthResponse, error)
-	ForgotPassword(req *request.ForgotPasswordRequest) (*response.ForgotPasswordResponse, error)
+	LoginUser(req *request.LoginRequest, clientIP, userAgent string) (*response.AuthResponse, error)
+	ForgotPassword(req *request.ForgotPasswordRequest, clientIP string) (*response.ForgotPasswordResponse, error)
 	ResetPassword(req *request.ResetPasswordRequest) (*response.ResetPasswordResponse, error)
-    LoginWithGoogle(email, name, picture string) (*response.AuthResponse, error)
+	UnlockAccount(req *request.UnlockAccountRequest) error
 }
 
 type authService struct {
 	userRepo           repositories.UserRepository
-	shopRepo           repositories.ShopRepository
 	provinceCityRepo   repositories.ProvinceCityRepository
 	emailService       EmailService
+	twoFactorService   TwoFactorService
+	loginThrottle      LoginThrottleService
 }
 
-func NewAuthService(userRepo repositories.UserRepository, shopRepo repositories.ShopRepository, provinceCityRepo repositories.ProvinceCityRepository, emailService EmailService) AuthService {
+func NewAuthService(userRepo repositories.UserRepository, provinceCityRepo repositories.ProvinceCityRepository, emailService EmailService, twoFactorService TwoFactorService, loginThrottle LoginThrottleService) AuthService {
 	return &authService{
 		userRepo:         userRepo,
-		shopRepo:         shopRepo,
 		provinceCityRepo: provinceCityRepo,
 		emailService:     emailService,
+		twoFactorService: twoFactorService,
+		loginThrottle:    loginThrottle,
 	}
 }
 
@@ -47,8 +54,14 @@ func (s *authService) RegisterUser(req *request.RegisterRequest) (*response.Auth
 		return nil, errors.New(constants.ErrEmailAlreadyExists)
 	}
 
+	// Normalize phone number to E.164 before checking uniqueness
+	noTelp, err := utils.NormalizePhoneNumber(req.NoTelp)
+	if err != nil {
+		return nil, errors.New(constants.ErrInvalidPhone)
+	}
+
 	// Check if phone number already exists
-	existingUser, _ = s.userRepo.GetByPhone(req.NoTelp)
+	existingUser, _ = s.userRepo.GetByPhone(noTelp)
 	if existingUser != nil {
 		return nil, errors.New(constants.ErrPhoneAlreadyExists)
 	}
@@ -69,7 +82,7 @@ func (s *authService) RegisterUser(req *request.RegisterRequest) (*response.Auth
 	user := &model.User{
 		Nama:         req.Nama,
 		KataSandi:    string(hashedPassword),
-		NoTelp:       req.NoTelp,
+		NoTelp:       noTelp,
 		TanggalLahir: tanggalLahir,
 		JenisKelamin: req.JenisKelamin,
 		Tentang:      req.Tentang,
@@ -84,17 +97,6 @@ func (s *authService) RegisterUser(req *request.RegisterRequest) (*response.Auth
 		return nil, err
 	}
 
-	// Create shop for user
-	shop := &model.Shop{
-		NamaToko: req.Nama + "'s Shop",
-		URLToko:  "shop-" + req.Email,
-		IDUser:   user.ID,
-	}
-
-	if err := s.shopRepo.Create(shop); err != nil {
-		return nil, err
-	}
-
 	// Generate token
 	token, err := utils.GenerateToken(user.ID, user.IsAdmin)
 	if err != nil {
@@ -102,20 +104,7 @@ func (s *authService) RegisterUser(req *request.RegisterRequest) (*response.Auth
 	}
 
 	// Return response
-	userProfile := response.UserProfile{
-		ID:            user.ID,
-		Nama:          user.Nama,
-		NoTelp:        user.NoTelp,
-		TanggalLahir:  user.TanggalLahir.Format("2006-01-02"),
-		JenisKelamin:  user.JenisKelamin,
-		Tentang:       user.Tentang,
-		Pekerjaan:     user.Pekerjaan,
-		Email:         user.Email,
-		IDProvinsi:    user.IDProvinsi,
-		IDKota:        user.IDKota,
-		PhotoURL:      user.PhotoURL,
-		IsAdmin:       user.IsAdmin,
-	}
+	userProfile := mapUserToProfile(user)
 
 	return &response.AuthResponse{
 		Token: token,
@@ -123,153 +112,67 @@ func (s *authService) RegisterUser(req *request.RegisterRequest) (*response.Auth
 	}, nil
 }
 
-func (s *authService) LoginUser(req *request.LoginRequest) (*response.AuthResponse, error) {
-	// Get user by email
-	user, err := s.userRepo.GetByEmail(req.Email)
-	if err != nil {
-		return nil, errors.New(constants.ErrInvalidCredentials)
-	}
-
-	// Check password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.KataSandi), []byte(req.KataSandi)); err != nil {
-		return nil, errors.New(constants.ErrInvalidCredentials)
+func (s *authService) LoginUser(req *request.LoginRequest, clientIP, userAgent string) (*response.AuthResponse, error) {
+	attempt := &model.FailedLogin{
+		Email:     req.Email,
+		IPAddress: clientIP,
+		UserAgent: userAgent,
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.IsAdmin)
-	if err != nil {
+	// Refuse early while the account or client is backing off
+	if err := s.loginThrottle.CheckLogin(req.Email, clientIP); err != nil {
+		var throttled *ThrottledError
+		if errors.As(err, &throttled) {
+			attempt.Reason = constants.LoginFailureThrottled
+			s.loginThrottle.AuditLoginFailure(attempt)
+		}
 		return nil, err
 	}
 
-	// Return response
-	userProfile := response.UserProfile{
-		ID:            user.ID,
-		Nama:          user.Nama,
-		NoTelp:        user.NoTelp,
-		TanggalLahir:  user.TanggalLahir.Format("2006-01-02"),
-		JenisKelamin:  user.JenisKelamin,
-		Tentang:       user.Tentang,
-		Pekerjaan:     user.Pekerjaan,
-		Email:         user.Email,
-		IDProvinsi:    user.IDProvinsi,
-		IDKota:        user.IDKota,
-		PhotoURL:      user.PhotoURL,
-		IsAdmin:       user.IsAdmin,
-	}
-
-	return &response.AuthResponse{
-		Token: token,
-		User:  userProfile,
-	}, nil
-}
+	// Get user by email
+	user, _ := s.userRepo.GetByEmail(req.Email)
+	canUsePassword := user != nil && hasPassword(user)
 
-// LoginWithGoogle logs the user in using Google account email. If the user
-// does not exist, it creates a minimal user profile and a shop entry.
-func (s *authService) LoginWithGoogle(email, name, picture string) (*response.AuthResponse, error) {
-    if email == "" {
-        return nil, errors.New("Invalid Google account: email missing")
-    }
-
-    // Try to find by email
-    user, err := s.userRepo.GetByEmail(email)
-    if err != nil || user == nil {
-        // Create minimal user (fill required non-null fields with defaults)
-        now := time.Now()
-        newUser := &model.User{
-            Nama:         name,
-            KataSandi:    "google-oauth", // not used for Google login
-            NoTelp:       "google-" + email,
-            PhotoURL:     picture,
-            TanggalLahir: now,
-            JenisKelamin: "",
-            Tentang:      "",
-            Pekerjaan:    "",
-            Email:        email,
-            IDProvinsi:   "",
-            IDKota:       "",
-            IsAdmin:      false,
-        }
-        if err := s.userRepo.Create(newUser); err != nil {
-            return nil, err
-        }
-        // Create default shop
-        shop := &model.Shop{
-            NamaToko: name + "'s Shop",
-            URLToko:  "shop-" + email,
-            IDUser:   newUser.ID,
-        }
-        if err := s.shopRepo.Create(shop); err != nil {
-            return nil, err
-        }
-        user = newUser
-    } else {
-        // Update photo if provided and not already set
-        if picture != "" && user.PhotoURL == "" {
-            user.PhotoURL = picture
-            _ = s.userRepo.Update(user)
-        }
-    }
-
-    // Generate token
-    token, err := utils.GenerateToken(user.ID, user.IsAdmin)
-    if err != nil {
-        return nil, err
-    }
-
-    userProfile := response.UserProfile{
-        ID:            user.ID,
-        Nama:          user.Nama,
-        NoTelp:        user.NoTelp,
-        TanggalLahir:  user.TanggalLahir.Format("2006-01-02"),
-        JenisKelamin:  user.JenisKelamin,
-        Tentang:       user.Tentang,
-        Pekerjaan:     user.Pekerjaan,
-        Email:         user.Email,
-        IDProvinsi:    user.IDProvinsi,
-        IDKota:        user.IDKota,
-        PhotoURL:      user.PhotoURL,
-        IsAdmin:       user.IsAdmin,
-    }
-
-    return &response.AuthResponse{
-        Token: token,
-        User:  userProfile,
-    }, nil
-}
+	// Always run bcrypt so the response time doesn't reveal whether the account exists
+	passwordHash := dummyPasswordHash
+	if canUsePassword {
+		passwordHash = []byte(user.KataSandi)
+	}
 
-func (s *authService) ForgotPassword(req *request.ForgotPasswordRequest) (*response.ForgotPasswordResponse, error) {
-	// Check if user exists
-	user, err := s.userRepo.GetByEmail(req.Email)
-	if err != nil {
-		// For security, don't reveal if email exists or not
-		return &response.ForgotPasswordResponse{
-			Message: "Jika email terdaftar, link reset password telah dikirim ke email Anda",
-		}, nil
+	if err := bcrypt.CompareHashAndPassword(passwordHash, []byte(req.KataSandi)); err != nil || !canUsePassword {
+		attempt.Reason = constants.LoginFailureUnknownEmail
+		if user != nil {
+			attempt.IDUser = &user.ID
+			attempt.Reason = constants.LoginFailureInvalidPassword
+		}
+
+		locked, err := s.loginThrottle.RecordLoginFailure(attempt)
+		if err != nil {
+			return nil, err
+		}
+		if locked && user != nil {
+			go s.sendUnlockEmail(user)
+		}
+		return nil, errors.New(constants.ErrInvalidCredentials)
 	}
 
-	// Generate random token
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
+	if err := s.loginThrottle.ResetLogin(req.Email); err != nil {
 		return nil, err
 	}
-	token := hex.EncodeToString(tokenBytes)
 
-	// Create password reset token
-	resetToken := &model.PasswordResetToken{
-		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(24 * time.Hour), // Token expires in 24 hours
-		Used:      false,
-	}
+	// Issue token, or a 2FA challenge if enabled
+	return s.twoFactorService.CompleteLogin(user)
+}
 
-	if err := s.userRepo.CreatePasswordResetToken(resetToken); err != nil {
+func (s *authService) ForgotPassword(req *request.ForgotPasswordRequest, clientIP string) (*response.ForgotPasswordResponse, error) {
+	if err := s.loginThrottle.CheckPasswordReset(req.Email, clientIP); err != nil {
 		return nil, err
 	}
 
-	// Send email with reset link
-	if err := s.emailService.SendPasswordResetEmail(user.Email, token); err != nil {
-		// Log error but don't fail the request (for security)
-		fmt.Printf("Failed to send email to %s: %v\n", user.Email, err)
+	// For security, don't reveal if email exists or not. The reset email is
+	// sent in the background so both cases respond in the same time.
+	if user, err := s.userRepo.GetByEmail(req.Email); err == nil {
+		go s.sendPasswordReset(user)
 	}
 
 	return &response.ForgotPasswordResponse{
@@ -301,8 +204,13 @@ func (s *authService) ResetPassword(req *request.ResetPasswordRequest) (*respons
 		return nil, err
 	}
 
-	// Update user password
+	// Update user password. The reset link was emailed, so it also proves
+	// the user owns the address
 	user.KataSandi = string(hashedPassword)
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
 	if err := s.userRepo.Update(user); err != nil {
 		return nil, err
 	}
@@ -316,3 +224,57 @@ func (s *authService) ResetPassword(req *request.ResetPasswordRequest) (*respons
 		Message: "Password berhasil direset",
 	}, nil
 }
+
+func (s *authService) UnlockAccount(req *request.UnlockAccountRequest) error {
+	userID, err := s.loginThrottle.RedeemUnlockToken(req.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New(constants.ErrUserNotFound)
+	}
+
+	return s.loginThrottle.ResetLogin(user.Email)
+}
+
+func (s *authService) sendPasswordReset(user *model.User) {
+	// Generate random token
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		log.Printf("Failed to generate reset token for %s: %v", user.Email, err)
+		return
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	// Create password reset token
+	resetToken := &model.PasswordResetToken{
+		UserID:    user.ID,
+		Token:     token,
+		ExpiresAt: time.Now().Add(24 * time.Hour), // Token expires in 24 hours
+		Used:      false,
+	}
+
+	if err := s.userRepo.CreatePasswordResetToken(resetToken); err != nil {
+		log.Printf("Failed to store reset token for %s: %v", user.Email, err)
+		return
+	}
+
+	// Send email with reset link
+	if err := s.emailService.SendPasswordResetEmail(user.Email, token); err != nil {
+		fmt.Printf("Failed to send email to %s: %v\n", user.Email, err)
+	}
+}
+
+func (s *authService) sendUnlockEmail(user *model.User) {
+	token, err := s.loginThrottle.IssueUnlockToken(user.ID)
+	if err != nil {
+		log.Printf("Failed to issue unlock token for %s: %v", user.Email, err)
+		return
+	}
+
+	if err := s.emailService.SendAccountLockedEmail(user.Email, token); err != nil {
+		fmt.Printf("Failed to send email to %s: %v\n", user.Email, err)
+	}
+}
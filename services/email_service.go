@@ -2,15 +2,22 @@ package services
 
 import (
 	"fmt"
+	"html"
 	"os"
 
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/utils"
 	"gopkg.in/gomail.v2"
 )
 
 type EmailService interface {
 	SendPasswordResetEmail(email, token string) error
-	SendPaymentSuccessEmail(email, invoiceCode string, totalAmount int) error
-	SendPaymentExpiredEmail(email, invoiceCode string, totalAmount int) error
+	SendPaymentSuccessEmail(email, invoiceCode string, totalAmount int64) error
+	SendPaymentExpiredEmail(email, invoiceCode string, totalAmount int64) error
+	SendAccountLockedEmail(email, token string) error
+	SendShopInvitationEmail(email, shopName, role, token string) error
+	SendResellerStatusEmail(email, namaUsaha, status, catatan string) error
+	SendShopVerificationStatusEmail(email, namaToko, status, catatan string) error
 }
 
 type emailService struct {
@@ -20,6 +27,7 @@ type emailService struct {
 	smtpPassword string
 	fromEmail    string
 	fromName     string
+	frontendURL  string
 }
 
 func NewEmailService() EmailService {
@@ -30,6 +38,7 @@ func NewEmailService() EmailService {
 		smtpPassword: getEnv("SMTP_PASSWORD", ""),
 		fromEmail:    getEnv("FROM_EMAIL", "noreply@warungbudehramah.com"),
 		fromName:     getEnv("FROM_NAME", "Warung Budeh Ramah"),
+		frontendURL:  getEnv("FRONTEND_URL", "http://localhost:5173"),
 	}
 }
 
@@ -153,20 +162,20 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
-func (s *emailService) SendPaymentSuccessEmail(email, invoiceCode string, totalAmount int) error {
+func (s *emailService) SendPaymentSuccessEmail(email, invoiceCode string, totalAmount int64) error {
 	// Jika tidak ada konfigurasi SMTP, log ke console (untuk development)
 	if s.smtpUsername == "" || s.smtpPassword == "" {
 		fmt.Printf("=== EMAIL PAYMENT SUCCESS ===\n")
 		fmt.Printf("To: %s\n", email)
 		fmt.Printf("Subject: Pembayaran Berhasil - Warung Budeh Ramah\n")
 		fmt.Printf("Invoice: %s\n", invoiceCode)
-		fmt.Printf("Total: Rp %d\n", totalAmount)
+		fmt.Printf("Total: %s\n", utils.FormatRupiah(totalAmount))
 		fmt.Printf("=============================\n")
 		return nil
 	}
 
 	// Format total amount
-	totalAmountStr := fmt.Sprintf("Rp %s", formatCurrency(totalAmount))
+	totalAmountStr := utils.FormatRupiah(totalAmount)
 
 	// Template email HTML
 	htmlBody := fmt.Sprintf(`
@@ -259,20 +268,20 @@ Email ini dikirim secara otomatis, mohon tidak membalas email ini.
 	return nil
 }
 
-func (s *emailService) SendPaymentExpiredEmail(email, invoiceCode string, totalAmount int) error {
+func (s *emailService) SendPaymentExpiredEmail(email, invoiceCode string, totalAmount int64) error {
 	// Jika tidak ada konfigurasi SMTP, log ke console (untuk development)
 	if s.smtpUsername == "" || s.smtpPassword == "" {
 		fmt.Printf("=== EMAIL PAYMENT EXPIRED ===\n")
 		fmt.Printf("To: %s\n", email)
 		fmt.Printf("Subject: Pembayaran Kadaluarsa - Warung Budeh Ramah\n")
 		fmt.Printf("Invoice: %s\n", invoiceCode)
-		fmt.Printf("Total: Rp %d\n", totalAmount)
+		fmt.Printf("Total: %s\n", utils.FormatRupiah(totalAmount))
 		fmt.Printf("=============================\n")
 		return nil
 	}
 
 	// Format total amount
-	totalAmountStr := fmt.Sprintf("Rp %s", formatCurrency(totalAmount))
+	totalAmountStr := utils.FormatRupiah(totalAmount)
 
 	// Template email HTML
 	htmlBody := fmt.Sprintf(`
@@ -368,15 +377,400 @@ Email ini dikirim secara otomatis, mohon tidak membalas email ini.
 	return nil
 }
 
-// formatCurrency formats number to Indonesian currency format
-func formatCurrency(amount int) string {
-	amountStr := fmt.Sprintf("%d", amount)
-	result := ""
-	for i, char := range amountStr {
-		if i > 0 && (len(amountStr)-i)%3 == 0 {
-			result += "."
+func (s *emailService) SendAccountLockedEmail(email, token string) error {
+	// Buat link untuk membuka kunci akun
+	unlockURL := fmt.Sprintf("%s/unlock-account?token=%s", s.frontendURL, token)
+
+	// Jika tidak ada konfigurasi SMTP, log ke console (untuk development)
+	if s.smtpUsername == "" || s.smtpPassword == "" {
+		fmt.Printf("=== EMAIL ACCOUNT LOCKED ===\n")
+		fmt.Printf("To: %s\n", email)
+		fmt.Printf("Subject: Akun Dikunci Sementara - Warung Budeh Ramah\n")
+		fmt.Printf("Unlock URL: %s\n", unlockURL)
+		fmt.Printf("=============================\n")
+		return nil
+	}
+
+	// Template email HTML
+	htmlBody := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Akun Dikunci Sementara - Warung Budeh Ramah</title>
+		<style>
+			body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.header { background-color: #FF6B6B; color: white; padding: 20px; text-align: center; }
+			.content { padding: 30px; background-color: #f9f9f9; }
+			.button { display: inline-block; background-color: #03AC0E; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; margin: 20px 0; }
+			.footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Warung Budeh Ramah</h1>
+			</div>
+			<div class="content">
+				<h2>Akun Dikunci Sementara</h2>
+				<p>Halo,</p>
+				<p>Kami mendeteksi terlalu banyak percobaan login yang gagal pada akun Warung Budeh Ramah Anda, sehingga akun dikunci sementara selama %d menit.</p>
+				<p>Jika itu Anda, klik tombol di bawah ini untuk membuka kunci akun sekarang:</p>
+				<p style="text-align: center;">
+					<a href="%s" class="button">Buka Kunci Akun</a>
+				</p>
+				<p>Atau copy dan paste link berikut ke browser Anda:</p>
+				<p style="word-break: break-all; background-color: #eee; padding: 10px; border-radius: 3px;">
+					%s
+				</p>
+				<p><strong>Jika Anda tidak mencoba login</strong>, seseorang mungkin sedang mencoba menebak password Anda. Kami sarankan untuk segera mengganti password Anda.</p>
+			</div>
+			<div class="footer">
+				<p>Email ini dikirim secara otomatis, mohon tidak membalas email ini.</p>
+				<p>&copy; 2024 Warung Budeh Ramah. All rights reserved.</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`, int(constants.AccountLockoutDuration.Minutes()), unlockURL, unlockURL)
+
+	// Template email plain text
+	textBody := fmt.Sprintf(`
+Akun Dikunci Sementara - Warung Budeh Ramah
+
+Halo,
+
+Kami mendeteksi terlalu banyak percobaan login yang gagal pada akun Warung Budeh Ramah Anda, sehingga akun dikunci sementara selama %d menit.
+
+Jika itu Anda, buka link berikut untuk membuka kunci akun sekarang:
+%s
+
+Jika Anda tidak mencoba login, seseorang mungkin sedang mencoba menebak password Anda. Kami sarankan untuk segera mengganti password Anda.
+
+Email ini dikirim secara otomatis, mohon tidak membalas email ini.
+
+© 2024 Warung Budeh Ramah. All rights reserved.
+	`, int(constants.AccountLockoutDuration.Minutes()), unlockURL)
+
+	// Buat email message
+	m := gomail.NewMessage()
+	m.SetHeader("From", fmt.Sprintf("%s <%s>", s.fromName, s.fromEmail))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", "Akun Dikunci Sementara - Warung Budeh Ramah")
+	m.SetBody("text/plain", textBody)
+	m.AddAlternative("text/html", htmlBody)
+
+	// Kirim email
+	d := gomail.NewDialer(s.smtpHost, s.smtpPort, s.smtpUsername, s.smtpPassword)
+
+	if err := d.DialAndSend(m); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+
+	return nil
+}
+
+func (s *emailService) SendShopInvitationEmail(email, shopName, role, token string) error {
+	// Buat link untuk menerima undangan
+	invitationURL := fmt.Sprintf("%s/shop-invitations?token=%s", s.frontendURL, token)
+
+	// Jika tidak ada konfigurasi SMTP, log ke console (untuk development)
+	if s.smtpUsername == "" || s.smtpPassword == "" {
+		fmt.Printf("=== EMAIL SHOP INVITATION ===\n")
+		fmt.Printf("To: %s\n", email)
+		fmt.Printf("Subject: Undangan Bergabung ke %s - Warung Budeh Ramah\n", shopName)
+		fmt.Printf("Role: %s\n", role)
+		fmt.Printf("Invitation URL: %s\n", invitationURL)
+		fmt.Printf("=============================\n")
+		return nil
+	}
+
+	// Template email HTML
+	htmlBody := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Undangan Bergabung - Warung Budeh Ramah</title>
+		<style>
+			body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.header { background-color: #03AC0E; color: white; padding: 20px; text-align: center; }
+			.content { padding: 30px; background-color: #f9f9f9; }
+			.button { display: inline-block; background-color: #03AC0E; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; margin: 20px 0; }
+			.footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Warung Budeh Ramah</h1>
+			</div>
+			<div class="content">
+				<h2>Undangan Bergabung ke Toko</h2>
+				<p>Halo,</p>
+				<p>Anda diundang untuk bergabung ke toko <strong>%s</strong> sebagai <strong>%s</strong>.</p>
+				<p>Masuk ke akun Warung Budeh Ramah Anda dengan email ini, lalu klik tombol di bawah untuk menerima atau menolak undangan:</p>
+				<p style="text-align: center;">
+					<a href="%s" class="button">Lihat Undangan</a>
+				</p>
+				<p>Atau copy dan paste link berikut ke browser Anda:</p>
+				<p style="word-break: break-all; background-color: #eee; padding: 10px; border-radius: 3px;">
+					%s
+				</p>
+				<p>Undangan ini berlaku selama %d hari. Jika Anda tidak mengenal toko ini, abaikan email ini.</p>
+			</div>
+			<div class="footer">
+				<p>Email ini dikirim secara otomatis, mohon tidak membalas email ini.</p>
+				<p>&copy; 2024 Warung Budeh Ramah. All rights reserved.</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`, html.EscapeString(shopName), role, invitationURL, invitationURL, int(constants.ShopInvitationTTL.Hours()/24))
+
+	// Template email plain text
+	textBody := fmt.Sprintf(`
+Undangan Bergabung ke Toko - Warung Budeh Ramah
+
+Halo,
+
+Anda diundang untuk bergabung ke toko %s sebagai %s.
+
+Masuk ke akun Warung Budeh Ramah Anda dengan email ini, lalu buka link berikut untuk menerima atau menolak undangan:
+%s
+
+Undangan ini berlaku selama %d hari. Jika Anda tidak mengenal toko ini, abaikan email ini.
+
+Email ini dikirim secara otomatis, mohon tidak membalas email ini.
+
+© 2024 Warung Budeh Ramah. All rights reserved.
+	`, shopName, role, invitationURL, int(constants.ShopInvitationTTL.Hours()/24))
+
+	// Buat email message
+	m := gomail.NewMessage()
+	m.SetHeader("From", fmt.Sprintf("%s <%s>", s.fromName, s.fromEmail))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", fmt.Sprintf("Undangan Bergabung ke %s - Warung Budeh Ramah", shopName))
+	m.SetBody("text/plain", textBody)
+	m.AddAlternative("text/html", htmlBody)
+
+	// Kirim email
+	d := gomail.NewDialer(s.smtpHost, s.smtpPort, s.smtpUsername, s.smtpPassword)
+
+	if err := d.DialAndSend(m); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+
+	return nil
+}
+
+func (s *emailService) SendResellerStatusEmail(email, namaUsaha, status, catatan string) error {
+	var subject, message string
+	switch status {
+	case constants.ResellerApproved:
+		subject = "Pengajuan Reseller Disetujui"
+		message = "Selamat! Pengajuan reseller Anda telah disetujui. Mulai sekarang Anda mendapatkan harga reseller saat berbelanja."
+	case constants.ResellerRejected:
+		subject = "Pengajuan Reseller Ditolak"
+		message = "Mohon maaf, pengajuan reseller Anda belum dapat kami setujui. Anda dapat mengajukan kembali kapan saja."
+	case constants.ResellerRevoked:
+		subject = "Status Reseller Dicabut"
+		message = "Status reseller Anda telah dicabut. Transaksi berikutnya akan menggunakan harga konsumen."
+	default:
+		return nil
+	}
+
+	// Jika tidak ada konfigurasi SMTP, log ke console (untuk development)
+	if s.smtpUsername == "" || s.smtpPassword == "" {
+		fmt.Printf("=== EMAIL RESELLER STATUS ===\n")
+		fmt.Printf("To: %s\n", email)
+		fmt.Printf("Subject: %s - Warung Budeh Ramah\n", subject)
+		fmt.Printf("Usaha: %s\n", namaUsaha)
+		fmt.Printf("Status: %s\n", status)
+		if catatan != "" {
+			fmt.Printf("Catatan: %s\n", catatan)
 		}
-		result += string(char)
+		fmt.Printf("=============================\n")
+		return nil
+	}
+
+	catatanHTML, catatanText := "", ""
+	if catatan != "" {
+		catatanHTML = fmt.Sprintf(`<p>Catatan dari tim kami:</p>
+				<p style="background-color: #eee; padding: 10px; border-radius: 3px;">%s</p>`, html.EscapeString(catatan))
+		catatanText = fmt.Sprintf("\nCatatan dari tim kami:\n%s\n", catatan)
 	}
-	return result
+
+	// Template email HTML
+	htmlBody := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>%s - Warung Budeh Ramah</title>
+		<style>
+			body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.header { background-color: #03AC0E; color: white; padding: 20px; text-align: center; }
+			.content { padding: 30px; background-color: #f9f9f9; }
+			.footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Warung Budeh Ramah</h1>
+			</div>
+			<div class="content">
+				<h2>%s</h2>
+				<p>Halo,</p>
+				<p>Terkait pengajuan reseller untuk usaha <strong>%s</strong>:</p>
+				<p>%s</p>
+				%s
+			</div>
+			<div class="footer">
+				<p>Email ini dikirim secara otomatis, mohon tidak membalas email ini.</p>
+				<p>&copy; 2024 Warung Budeh Ramah. All rights reserved.</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`, subject, subject, html.EscapeString(namaUsaha), message, catatanHTML)
+
+	// Template email plain text
+	textBody := fmt.Sprintf(`
+%s - Warung Budeh Ramah
+
+Halo,
+
+Terkait pengajuan reseller untuk usaha %s:
+
+%s
+%s
+Email ini dikirim secara otomatis, mohon tidak membalas email ini.
+
+© 2024 Warung Budeh Ramah. All rights reserved.
+	`, subject, namaUsaha, message, catatanText)
+
+	// Buat email message
+	m := gomail.NewMessage()
+	m.SetHeader("From", fmt.Sprintf("%s <%s>", s.fromName, s.fromEmail))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", fmt.Sprintf("%s - Warung Budeh Ramah", subject))
+	m.SetBody("text/plain", textBody)
+	m.AddAlternative("text/html", htmlBody)
+
+	// Kirim email
+	d := gomail.NewDialer(s.smtpHost, s.smtpPort, s.smtpUsername, s.smtpPassword)
+
+	if err := d.DialAndSend(m); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+
+	return nil
+}
+
+func (s *emailService) SendShopVerificationStatusEmail(email, namaToko, status, catatan string) error {
+	var subject, message string
+	switch status {
+	case constants.ShopVerified:
+		subject = "Verifikasi Toko Disetujui"
+		message = "Selamat! Toko Anda telah terverifikasi. Batasan untuk toko yang belum terverifikasi tidak lagi berlaku."
+	case constants.ShopVerificationRejected:
+		subject = "Verifikasi Toko Ditolak"
+		message = "Mohon maaf, verifikasi toko Anda belum dapat kami setujui. Silakan perbaiki dokumen Anda dan ajukan kembali."
+	default:
+		return nil
+	}
+
+	// Jika tidak ada konfigurasi SMTP, log ke console (untuk development)
+	if s.smtpUsername == "" || s.smtpPassword == "" {
+		fmt.Printf("=== EMAIL VERIFIKASI TOKO ===\n")
+		fmt.Printf("To: %s\n", email)
+		fmt.Printf("Subject: %s - Warung Budeh Ramah\n", subject)
+		fmt.Printf("Toko: %s\n", namaToko)
+		fmt.Printf("Status: %s\n", status)
+		if catatan != "" {
+			fmt.Printf("Catatan: %s\n", catatan)
+		}
+		fmt.Printf("=============================\n")
+		return nil
+	}
+
+	catatanHTML, catatanText := "", ""
+	if catatan != "" {
+		catatanHTML = fmt.Sprintf(`<p>Catatan dari tim kami:</p>
+				<p style="background-color: #eee; padding: 10px; border-radius: 3px;">%s</p>`, html.EscapeString(catatan))
+		catatanText = fmt.Sprintf("\nCatatan dari tim kami:\n%s\n", catatan)
+	}
+
+	// Template email HTML
+	htmlBody := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>%s - Warung Budeh Ramah</title>
+		<style>
+			body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.header { background-color: #03AC0E; color: white; padding: 20px; text-align: center; }
+			.content { padding: 30px; background-color: #f9f9f9; }
+			.footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Warung Budeh Ramah</h1>
+			</div>
+			<div class="content">
+				<h2>%s</h2>
+				<p>Halo,</p>
+				<p>Terkait pengajuan verifikasi untuk toko <strong>%s</strong>:</p>
+				<p>%s</p>
+				%s
+			</div>
+			<div class="footer">
+				<p>Email ini dikirim secara otomatis, mohon tidak membalas email ini.</p>
+				<p>&copy; 2024 Warung Budeh Ramah. All rights reserved.</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`, subject, subject, html.EscapeString(namaToko), message, catatanHTML)
+
+	// Template email plain text
+	textBody := fmt.Sprintf(`
+%s - Warung Budeh Ramah
+
+Halo,
+
+Terkait pengajuan verifikasi untuk toko %s:
+
+%s
+%s
+Email ini dikirim secara otomatis, mohon tidak membalas email ini.
+
+© 2024 Warung Budeh Ramah. All rights reserved.
+	`, subject, namaToko, message, catatanText)
+
+	// Buat email message
+	m := gomail.NewMessage()
+	m.SetHeader("From", fmt.Sprintf("%s <%s>", s.fromName, s.fromEmail))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", fmt.Sprintf("%s - Warung Budeh Ramah", subject))
+	m.SetBody("text/plain", textBody)
+	m.AddAlternative("text/html", htmlBody)
+
+	// Kirim email
+	d := gomail.NewDialer(s.smtpHost, s.smtpPort, s.smtpUsername, s.smtpPassword)
+
+	if err := d.DialAndSend(m); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+)
+
+const (
+	ledgerEntriesPageSize = 50
+	ledgerCompletionBatch = 100
+)
+
+// LedgerOptions sets how sales are credited to shops.
+type LedgerOptions struct {
+	// CommissionBps is the platform's cut of each paid line in basis points
+	CommissionBps int
+	// HoldPeriod is how long after payment an order is completed when the
+	// buyer has not completed it
+	HoldPeriod time.Duration
+}
+
+// LedgerService keeps the double-entry ledger of what the platform owes
+// each shop. A paid order credits its shops' pending balances, less the
+// commission, and completing the order makes that money available for
+// payouts.
+type LedgerService interface {
+	// RecordSale credits the shops with a paid order's lines. Recording an
+	// order again has no effect. Nothing is recorded if any line has an
+	// amount of 0 or less.
+	RecordSale(trx *model.TRX) error
+	// CompleteTRX marks a paid order completed and releases its held funds.
+	// It reports false when the order was already completed.
+	CompleteTRX(trx *model.TRX) (bool, error)
+	// CompleteDueTRX completes the orders paid longer than the hold period
+	// ago and returns how many it completed. Orders that fail are logged and
+	// tried again on the next run.
+	CompleteDueTRX() (int, error)
+	// RunSettlement calls CompleteDueTRX every interval until ctx is done.
+	RunSettlement(ctx context.Context, interval time.Duration)
+	GetShopBalance(userID, shopID int) (*response.ShopBalanceResponse, error)
+	GetShopLedger(userID, shopID int, query *request.ShopLedgerQuery) (*response.ShopLedgerResponse, error)
+	// GetReconciliation compares the ledger with the paid orders and checks
+	// that it balances.
+	GetReconciliation() (*response.LedgerReconciliationResponse, error)
+}
+
+type ledgerService struct {
+	ledgerRepo              repositories.LedgerRepository
+	trxRepo                 repositories.TRXRepository
+	shopMemberService       ShopMemberService
+	shopVerificationService ShopVerificationService
+	options                 LedgerOptions
+}
+
+func NewLedgerService(ledgerRepo repositories.LedgerRepository, trxRepo repositories.TRXRepository, shopMemberService ShopMemberService, shopVerificationService ShopVerificationService, options LedgerOptions) LedgerService {
+	return &ledgerService{
+		ledgerRepo:              ledgerRepo,
+		trxRepo:                 trxRepo,
+		shopMemberService:       shopMemberService,
+		shopVerificationService: shopVerificationService,
+		options:                 options,
+	}
+}
+
+func (s *ledgerService) RecordSale(trx *model.TRX) error {
+	// A negative line would debit its shop's pending balance
+	for _, detail := range trx.DetailTRX {
+		if detail.HargaTotal <= 0 {
+			return errors.New(constants.ErrInvalidSaleLine)
+		}
+	}
+
+	journal := &model.LedgerJournal{
+		Jenis:      constants.LedgerJournalSale,
+		Kunci:      ledgerKey(constants.LedgerJournalSale, trx.ID),
+		IDTRX:      &trx.ID,
+		Keterangan: fmt.Sprintf("Penjualan %s, komisi %d.%02d%%", trx.KodeInvoice, s.options.CommissionBps/100, s.options.CommissionBps%100),
+	}
+	for _, detail := range trx.DetailTRX {
+		shopID, detailID := detail.IDToko, detail.ID
+		commission := detail.HargaTotal * int64(s.options.CommissionBps) / 10000
+		journal.Entri = append(journal.Entri,
+			model.LedgerEntry{Akun: constants.LedgerAccountPlatformCash, IDToko: &shopID, IDDetailTRX: &detailID, Debit: detail.HargaTotal},
+			model.LedgerEntry{Akun: constants.LedgerAccountShopPending, IDToko: &shopID, IDDetailTRX: &detailID, Kredit: detail.HargaTotal - commission},
+		)
+		if commission > 0 {
+			journal.Entri = append(journal.Entri,
+				model.LedgerEntry{Akun: constants.LedgerAccountCommission, IDToko: &shopID, IDDetailTRX: &detailID, Kredit: commission})
+		}
+	}
+	if len(journal.Entri) == 0 {
+		return nil
+	}
+
+	_, err := s.post(journal)
+	return err
+}
+
+func (s *ledgerService) CompleteTRX(trx *model.TRX) (bool, error) {
+	if trx.PaymentStatus != constants.PaymentStatusPaid {
+		return false, errors.New(constants.ErrTRXNotPaid)
+	}
+
+	// Orders paid before the ledger was introduced were never credited
+	if trx.DibayarAt != nil && len(trx.DetailTRX) > 0 {
+		// In case recording the sale failed when the payment came in
+		if err := s.RecordSale(trx); err != nil {
+			return false, err
+		}
+		// Release before marking the order completed, so that a failure
+		// is retried; releasing twice has no effect
+		if err := s.release(trx); err != nil {
+			return false, err
+		}
+	}
+
+	return s.trxRepo.Complete(trx.ID, time.Now())
+}
+
+// release moves what the order's sale credited to each shop from its
+// pending to its available balance.
+func (s *ledgerService) release(trx *model.TRX) error {
+	sale, err := s.ledgerRepo.GetByKey(*ledgerKey(constants.LedgerJournalSale, trx.ID))
+	if err != nil {
+		return err
+	}
+
+	journal := &model.LedgerJournal{
+		Jenis:      constants.LedgerJournalRelease,
+		Kunci:      ledgerKey(constants.LedgerJournalRelease, trx.ID),
+		IDTRX:      &trx.ID,
+		Keterangan: fmt.Sprintf("Pesanan %s selesai", trx.KodeInvoice),
+	}
+	for _, entry := range sale.Entri {
+		if entry.Akun != constants.LedgerAccountShopPending || entry.Kredit == 0 {
+			continue
+		}
+		journal.Entri = append(journal.Entri,
+			model.LedgerEntry{Akun: constants.LedgerAccountShopPending, IDToko: entry.IDToko, IDDetailTRX: entry.IDDetailTRX, Debit: entry.Kredit},
+			model.LedgerEntry{Akun: constants.LedgerAccountShopAvailable, IDToko: entry.IDToko, IDDetailTRX: entry.IDDetailTRX, Kredit: entry.Kredit},
+		)
+	}
+	if len(journal.Entri) == 0 {
+		return nil
+	}
+
+	_, err = s.post(journal)
+	return err
+}
+
+func (s *ledgerService) CompleteDueTRX() (int, error) {
+	paidBefore := time.Now().Add(-s.options.HoldPeriod)
+	completed, lastID := 0, 0
+	for {
+		trxs, err := s.trxRepo.GetDueForCompletion(paidBefore, lastID, ledgerCompletionBatch)
+		if err != nil {
+			return completed, err
+		}
+		for i := range trxs {
+			lastID = trxs[i].ID
+			trx, err := s.trxRepo.GetByID(trxs[i].ID)
+			if err == nil {
+				var done bool
+				done, err = s.CompleteTRX(trx)
+				if done {
+					completed++
+				}
+			}
+			if err != nil {
+				log.Printf("[Ledger] failed to complete transaction %d: %v", trxs[i].ID, err)
+			}
+		}
+		if len(trxs) < ledgerCompletionBatch {
+			return completed, nil
+		}
+	}
+}
+
+func (s *ledgerService) RunSettlement(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		completed, err := s.CompleteDueTRX()
+		if err != nil {
+			log.Printf("[Ledger] failed to complete orders past the hold period: %v", err)
+		} else if completed > 0 {
+			log.Printf("[Ledger] completed %d orders past the hold period", completed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ledgerService) GetShopBalance(userID, shopID int) (*response.ShopBalanceResponse, error) {
+	shop, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermFinance)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.ledgerRepo.ShopAccountTotals(shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &response.ShopBalanceResponse{
+		IDToko:        shopID,
+		BisaDicairkan: s.shopVerificationService.CheckPayoutAllowed(shop) == nil,
+	}
+	for _, total := range totals {
+		switch total.Akun {
+		case constants.LedgerAccountShopPending:
+			balance.SaldoTertahan += total.Kredit - total.Debit
+		case constants.LedgerAccountShopAvailable:
+			balance.SaldoTersedia += total.Kredit - total.Debit
+		case constants.LedgerAccountShopPayout:
+			balance.SaldoDiproses += total.Kredit - total.Debit
+		case constants.LedgerAccountPlatformCash:
+			balance.TotalDicairkan += total.Kredit
+		}
+	}
+	return balance, nil
+}
+
+func (s *ledgerService) GetShopLedger(userID, shopID int, query *request.ShopLedgerQuery) (*response.ShopLedgerResponse, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermFinance); err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = ledgerEntriesPageSize
+	}
+	// Fetch one extra entry to know whether there are more
+	entries, err := s.ledgerRepo.GetEntriesByShopID(shopID, query.Before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &response.ShopLedgerResponse{Entri: []response.LedgerEntryResponse{}}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		ledger.HasMore = true
+		ledger.NextBefore = entries[limit-1].ID
+	}
+	for _, entry := range entries {
+		ledger.Entri = append(ledger.Entri, response.LedgerEntryResponse{
+			ID:          entry.ID,
+			IDJurnal:    entry.IDJurnal,
+			Jenis:       entry.Jurnal.Jenis,
+			Keterangan:  entry.Jurnal.Keterangan,
+			Akun:        entry.Akun,
+			IDTRX:       entry.Jurnal.IDTRX,
+			IDDetailTRX: entry.IDDetailTRX,
+			IDPenarikan: entry.Jurnal.IDPenarikan,
+			Debit:       entry.Debit,
+			Kredit:      entry.Kredit,
+			CreatedAt:   entry.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return ledger, nil
+}
+
+func (s *ledgerService) GetReconciliation() (*response.LedgerReconciliationResponse, error) {
+	totals, err := s.ledgerRepo.AccountTotals()
+	if err != nil {
+		return nil, err
+	}
+	unbalanced, err := s.ledgerRepo.UnbalancedJournalIDs()
+	if err != nil {
+		return nil, err
+	}
+	paidTotal, err := s.ledgerRepo.PaidTRXTotal()
+	if err != nil {
+		return nil, err
+	}
+	mismatches, err := s.ledgerRepo.FindTRXMismatches()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &response.LedgerReconciliationResponse{
+		TotalPenjualan:      paidTotal,
+		JurnalTidakSeimbang: []int{},
+		TransaksiTidakCocok: []response.LedgerTRXMismatchResponse{},
+		Toko:                []response.ShopLedgerSummaryResponse{},
+	}
+	if unbalanced != nil {
+		report.JurnalTidakSeimbang = unbalanced
+	}
+
+	shops := make(map[int]*response.ShopLedgerSummaryResponse)
+	var shopOrder []int
+	for _, total := range totals {
+		balance := total.Kredit - total.Debit
+		switch total.Akun {
+		case constants.LedgerAccountPlatformCash:
+			report.TotalDicatat += total.Debit
+			report.TotalDicairkan += total.Kredit
+			report.SaldoKas += total.Debit - total.Kredit
+		case constants.LedgerAccountCommission:
+			report.TotalKomisi += balance
+		default:
+			report.KewajibanToko += balance
+		}
+
+		if total.IDToko == nil {
+			continue
+		}
+		shop, ok := shops[*total.IDToko]
+		if !ok {
+			shop = &response.ShopLedgerSummaryResponse{IDToko: *total.IDToko, NamaToko: total.NamaToko}
+			shops[*total.IDToko] = shop
+			shopOrder = append(shopOrder, *total.IDToko)
+		}
+		switch total.Akun {
+		case constants.LedgerAccountPlatformCash:
+			shop.Penjualan += total.Debit
+			shop.TotalDicairkan += total.Kredit
+		case constants.LedgerAccountCommission:
+			shop.Komisi += balance
+		case constants.LedgerAccountShopPending:
+			shop.SaldoTertahan += balance
+		case constants.LedgerAccountShopAvailable:
+			shop.SaldoTersedia += balance
+		case constants.LedgerAccountShopPayout:
+			shop.SaldoDiproses += balance
+		}
+	}
+	for _, shopID := range shopOrder {
+		report.Toko = append(report.Toko, *shops[shopID])
+	}
+
+	for _, mismatch := range mismatches {
+		report.TransaksiTidakCocok = append(report.TransaksiTidakCocok, response.LedgerTRXMismatchResponse{
+			IDTRX:         mismatch.IDTRX,
+			KodeInvoice:   mismatch.KodeInvoice,
+			PaymentStatus: mismatch.PaymentStatus,
+			HargaTotal:    mismatch.HargaTotal,
+			Dicatat:       mismatch.Dicatat,
+		})
+	}
+
+	report.Seimbang = len(report.JurnalTidakSeimbang) == 0 && report.SaldoKas == report.KewajibanToko+report.TotalKomisi
+	return report, nil
+}
+
+// post writes a journal after checking that it balances.
+func (s *ledgerService) post(journal *model.LedgerJournal) (bool, error) {
+	var debit, kredit int64
+	for _, entry := range journal.Entri {
+		debit += entry.Debit
+		kredit += entry.Kredit
+	}
+	if debit != kredit {
+		return false, errors.New(constants.ErrUnbalancedJournal)
+	}
+	return s.ledgerRepo.Post(journal)
+}
+
+// ledgerKey identifies the journal of the given kind for an order.
+func ledgerKey(jenis string, trxID int) *string {
+	key := fmt.Sprintf("%s:trx:%d", jenis, trxID)
+	return &key
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/utils"
+)
+
+// ThrottledError is returned while a login or password reset is backing off
+// or locked out. RetryAfter tells the client when to try again.
+type ThrottledError struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Locked {
+		return constants.ErrAccountLocked
+	}
+	return constants.ErrTooManyLoginAttempts
+}
+
+// throttleRule describes when failures against a key start to back off and
+// when they lock the key out entirely.
+type throttleRule struct {
+	backoffAfter int
+	lockAfter    int
+	lockFor      time.Duration
+}
+
+var (
+	accountThrottleRule = throttleRule{constants.AccountBackoffAfter, constants.AccountLockoutAfter, constants.AccountLockoutDuration}
+	ipThrottleRule      = throttleRule{constants.IPBackoffAfter, constants.IPLockoutAfter, constants.IPLockoutDuration}
+	// A challenge gets no backoff, it is locked until it has expired anyway
+	challengeThrottleRule = throttleRule{constants.TwoFactorChallengeMaxAttempts, constants.TwoFactorChallengeMaxAttempts, utils.ChallengeTokenTTL}
+)
+
+// delay returns how long the key is blocked after the given number of failures.
+func (r throttleRule) delay(failures int) time.Duration {
+	if failures >= r.lockAfter {
+		return r.lockFor
+	}
+	if failures <= r.backoffAfter {
+		return 0
+	}
+
+	delay := constants.LoginBackoffBase << uint(failures-r.backoffAfter-1)
+	if delay <= 0 || delay > constants.LoginBackoffMax {
+		return constants.LoginBackoffMax
+	}
+	return delay
+}
+
+type LoginThrottleService interface {
+	CheckLogin(email, clientIP string) error
+	// RecordLoginFailure audits the attempt and counts it against the account
+	// and the client IP. It reports whether the account has just been locked.
+	RecordLoginFailure(attempt *model.FailedLogin) (bool, error)
+	AuditLoginFailure(attempt *model.FailedLogin)
+	ResetLogin(email string) error
+	// CheckPasswordReset counts every request, so reset emails can't be
+	// triggered in bulk for one address or from one client.
+	CheckPasswordReset(email, clientIP string) error
+	// CheckTwoFactor rejects a 2FA challenge that was already completed or
+	// ran out of attempts, and throttles the user's 2FA failures.
+	CheckTwoFactor(userID int, challengeID string) error
+	RecordTwoFactorFailure(userID int, challengeID string) error
+	// CompleteTwoFactor uses up the challenge until it expires and clears
+	// the user's 2FA failures.
+	CompleteTwoFactor(userID int, challengeID string, expiresAt time.Time) error
+	IssueUnlockToken(userID int) (string, error)
+	RedeemUnlockToken(token string) (int, error)
+}
+
+type loginThrottleService struct {
+	loginAttemptRepo repositories.LoginAttemptRepository
+}
+
+func NewLoginThrottleService(loginAttemptRepo repositories.LoginAttemptRepository) LoginThrottleService {
+	return &loginThrottleService{loginAttemptRepo: loginAttemptRepo}
+}
+
+func (s *loginThrottleService) CheckLogin(email, clientIP string) error {
+	return s.check(map[string]throttleRule{
+		loginAccountKey(email): accountThrottleRule,
+		"login:ip:" + clientIP: ipThrottleRule,
+	})
+}
+
+func (s *loginThrottleService) RecordLoginFailure(attempt *model.FailedLogin) (bool, error) {
+	s.AuditLoginFailure(attempt)
+
+	if _, err := s.increment("login:ip:"+attempt.IPAddress, ipThrottleRule); err != nil {
+		return false, err
+	}
+
+	throttle, err := s.increment(loginAccountKey(attempt.Email), accountThrottleRule)
+	if err != nil {
+		return false, err
+	}
+	return throttle.Failures == accountThrottleRule.lockAfter, nil
+}
+
+func (s *loginThrottleService) AuditLoginFailure(attempt *model.FailedLogin) {
+	if err := s.loginAttemptRepo.CreateFailedLogin(attempt); err != nil {
+		log.Printf("[LoginThrottle] failed to audit login failure for %s: %v", attempt.Email, err)
+	}
+}
+
+func (s *loginThrottleService) ResetLogin(email string) error {
+	return s.loginAttemptRepo.ResetThrottle(loginAccountKey(email))
+}
+
+func (s *loginThrottleService) CheckPasswordReset(email, clientIP string) error {
+	rules := map[string]throttleRule{
+		"reset:account:" + normalizeEmail(email): accountThrottleRule,
+		"reset:ip:" + clientIP:                   ipThrottleRule,
+	}
+	if err := s.check(rules); err != nil {
+		return err
+	}
+
+	for key, rule := range rules {
+		if _, err := s.increment(key, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *loginThrottleService) CheckTwoFactor(userID int, challengeID string) error {
+	throttles, err := s.loginAttemptRepo.GetThrottles([]string{twoFactorChallengeKey(challengeID)})
+	if err != nil {
+		return err
+	}
+	for _, throttle := range throttles {
+		if throttle.LockedUntil != nil && time.Now().Before(*throttle.LockedUntil) {
+			return errors.New(constants.ErrInvalidToken)
+		}
+	}
+
+	return s.check(map[string]throttleRule{twoFactorAccountKey(userID): accountThrottleRule})
+}
+
+func (s *loginThrottleService) RecordTwoFactorFailure(userID int, challengeID string) error {
+	if _, err := s.increment(twoFactorChallengeKey(challengeID), challengeThrottleRule); err != nil {
+		return err
+	}
+	_, err := s.increment(twoFactorAccountKey(userID), accountThrottleRule)
+	return err
+}
+
+func (s *loginThrottleService) CompleteTwoFactor(userID int, challengeID string, expiresAt time.Time) error {
+	key := twoFactorChallengeKey(challengeID)
+	if _, err := s.loginAttemptRepo.IncrementThrottle(key, constants.LoginFailureWindow); err != nil {
+		return err
+	}
+	if err := s.loginAttemptRepo.LockThrottle(key, expiresAt); err != nil {
+		return err
+	}
+	return s.loginAttemptRepo.ResetThrottle(twoFactorAccountKey(userID))
+}
+
+func (s *loginThrottleService) IssueUnlockToken(userID int) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	if err := s.loginAttemptRepo.CreateUnlockToken(&model.AccountUnlockToken{
+		IDUser:    userID,
+		TokenHash: hashUnlockToken(token),
+		ExpiresAt: time.Now().Add(constants.AccountUnlockTokenTTL),
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *loginThrottleService) RedeemUnlockToken(token string) (int, error) {
+	unlockToken, err := s.loginAttemptRepo.ConsumeUnlockToken(hashUnlockToken(token))
+	if err != nil {
+		return 0, errors.New(constants.ErrInvalidUnlockToken)
+	}
+	return unlockToken.IDUser, nil
+}
+
+// check returns a *ThrottledError for the longest block among the keys.
+func (s *loginThrottleService) check(rules map[string]throttleRule) error {
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+
+	throttles, err := s.loginAttemptRepo.GetThrottles(keys)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var throttled *ThrottledError
+	for _, throttle := range throttles {
+		if throttle.LockedUntil == nil || !now.Before(*throttle.LockedUntil) {
+			continue
+		}
+		if throttled == nil {
+			throttled = &ThrottledError{}
+		}
+		if retryAfter := throttle.LockedUntil.Sub(now); retryAfter > throttled.RetryAfter {
+			throttled.RetryAfter = retryAfter
+		}
+		if throttle.Failures >= rules[throttle.Key].lockAfter {
+			throttled.Locked = true
+		}
+	}
+
+	if throttled != nil {
+		return throttled
+	}
+	return nil
+}
+
+func (s *loginThrottleService) increment(key string, rule throttleRule) (*model.LoginThrottle, error) {
+	throttle, err := s.loginAttemptRepo.IncrementThrottle(key, constants.LoginFailureWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	if delay := rule.delay(throttle.Failures); delay > 0 {
+		if err := s.loginAttemptRepo.LockThrottle(key, throttle.LastFailureAt.Add(delay)); err != nil {
+			return nil, err
+		}
+	}
+	return throttle, nil
+}
+
+// loginAccountKey keys account throttling on the submitted email, so unknown
+// addresses are throttled exactly like registered ones.
+func loginAccountKey(email string) string {
+	return "login:account:" + normalizeEmail(email)
+}
+
+func twoFactorAccountKey(userID int) string {
+	return "2fa:account:" + strconv.Itoa(userID)
+}
+
+func twoFactorChallengeKey(challengeID string) string {
+	return "2fa:challenge:" + challengeID
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func hashUnlockToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/storage"
+)
+
+const mediaGCBatchSize = 500
+
+// MediaGCOptions controls a run of RemoveOrphans.
+type MediaGCOptions struct {
+	// Objects modified more recently than this are left alone, since the
+	// photo they belong to may still be being saved
+	GracePeriod time.Duration
+	// DryRun only reports the orphans without touching them
+	DryRun bool
+	// Quarantine moves orphaned photos under MediaQuarantinePrefix instead
+	// of deleting them. Cached transformations are always deleted.
+	Quarantine bool
+}
+
+// OrphanedObject is an object in media storage that nothing refers to.
+type OrphanedObject struct {
+	Name         string
+	Size         int64
+	LastModified time.Time
+	// Error is set when the object could not be removed
+	Error string
+}
+
+// MediaGCReport describes what a run of RemoveOrphans found and did.
+type MediaGCReport struct {
+	Scanned       int
+	Orphans       []OrphanedObject
+	OrphanedBytes int64
+	Removed       int
+	Failed        int
+}
+
+// MediaGCService finds product photos left in media storage after their
+// product was deleted or saving them failed.
+type MediaGCService interface {
+	// RemoveOrphans lists the objects under ProductMediaPrefix and their
+	// cached transformations, and deletes or quarantines the ones that no
+	// photo of a live product uses and that are older than the grace period.
+	RemoveOrphans(ctx context.Context, opts MediaGCOptions) (*MediaGCReport, error)
+}
+
+type mediaGCService struct {
+	productRepo  repositories.ProductRepository
+	mediaStorage storage.MediaStorage
+}
+
+func NewMediaGCService(productRepo repositories.ProductRepository, mediaStorage storage.MediaStorage) MediaGCService {
+	return &mediaGCService{
+		productRepo:  productRepo,
+		mediaStorage: mediaStorage,
+	}
+}
+
+func (s *mediaGCService) RemoveOrphans(ctx context.Context, opts MediaGCOptions) (*MediaGCReport, error) {
+	report := &MediaGCReport{}
+	cutoff := time.Now().Add(-opts.GracePeriod)
+
+	// List before loading the photos, so that any object listed has had
+	// its photo saved by the time they are loaded
+	var candidates []storage.ObjectInfo
+	collect := func(info storage.ObjectInfo) error {
+		report.Scanned++
+		if info.LastModified.Before(cutoff) {
+			candidates = append(candidates, info)
+		}
+		return nil
+	}
+	prefixes := []string{constants.ProductMediaPrefix, constants.MediaCachePrefix + constants.ProductMediaPrefix}
+	for _, prefix := range prefixes {
+		if err := s.mediaStorage.List(ctx, prefix, collect); err != nil {
+			return nil, err
+		}
+	}
+	if len(candidates) == 0 {
+		return report, nil
+	}
+
+	used := make(map[string]bool)
+	err := s.productRepo.FindPhotosInBatches(mediaGCBatchSize, func(photos []model.PhotoProduct) error {
+		for i := range photos {
+			if name := photoObjectName(&photos[i]); name != "" {
+				used[name] = true
+			}
+			for _, rendition := range photos[i].Renditions {
+				used[rendition.ObjectName] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range candidates {
+		if used[sourceObjectName(info.Name)] {
+			continue
+		}
+		report.Orphans = append(report.Orphans, OrphanedObject{
+			Name:         info.Name,
+			Size:         info.Size,
+			LastModified: info.LastModified,
+		})
+		report.OrphanedBytes += info.Size
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool { return report.Orphans[i].Name < report.Orphans[j].Name })
+
+	if opts.DryRun {
+		return report, nil
+	}
+	for i := range report.Orphans {
+		orphan := &report.Orphans[i]
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := s.removeOrphan(ctx, orphan.Name, opts.Quarantine); err != nil {
+			log.Printf("[MediaGC] Failed to remove %s: %v", orphan.Name, err)
+			orphan.Error = err.Error()
+			report.Failed++
+			continue
+		}
+		report.Removed++
+	}
+	return report, nil
+}
+
+// removeOrphan deletes an object, or moves it under MediaQuarantinePrefix
+// when quarantine is set. Cached transformations can be made again, so they
+// are never quarantined.
+func (s *mediaGCService) removeOrphan(ctx context.Context, objectName string, quarantine bool) error {
+	if quarantine && !strings.HasPrefix(objectName, constants.MediaCachePrefix) {
+		info, err := s.mediaStorage.Stat(ctx, objectName)
+		if err != nil {
+			return err
+		}
+		obj, err := s.mediaStorage.GetObject(ctx, objectName)
+		if err != nil {
+			return err
+		}
+		_, err = s.mediaStorage.Upload(ctx, constants.MediaQuarantinePrefix+objectName, obj, info.Size, info.ContentType)
+		obj.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return s.mediaStorage.Delete(ctx, objectName)
+}
+
+// sourceObjectName returns the object a cached transformation was made
+// from, cache/<object name>/<transformation>, or objectName itself.
+func sourceObjectName(objectName string) string {
+	if !strings.HasPrefix(objectName, constants.MediaCachePrefix) {
+		return objectName
+	}
+	return path.Dir(strings.TrimPrefix(objectName, constants.MediaCachePrefix))
+}
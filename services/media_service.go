@@ -0,0 +1,292 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/media"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/storage"
+	"golang.org/x/sync/singleflight"
+)
+
+// File types accepted as private media and the extensions they are stored
+// with
+var privateMediaExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+}
+
+// MediaService prepares objects to be served from /media.
+type MediaService interface {
+	// TransformedObject returns the object to serve for a request for
+	// objectName with transformation query parameters: either a preset, or
+	// w, h, fit, fm and q signed with s. The transformed image is created in
+	// media storage the first time it is requested.
+	TransformedObject(ctx context.Context, objectName string, params map[string]string) (string, error)
+	// CheckPrivateAccess verifies the expires and s parameters of a request
+	// for a private object and returns when the access expires.
+	CheckPrivateAccess(objectName string, params map[string]string) (time.Time, error)
+	// UploadPrivate stores a private file owned by userID.
+	UploadPrivate(ctx context.Context, userID int, kategori, fileName string, file io.Reader) (*response.PrivateMediaResponse, error)
+	// GetPrivate returns one of the user's private files with a new signed
+	// URL.
+	GetPrivate(userID, id int) (*response.PrivateMediaResponse, error)
+	// GetPrivateForStaff returns any private file. It is only routed for
+	// users holding PermMediaPrivate.
+	GetPrivateForStaff(id int) (*response.PrivateMediaResponse, error)
+	DeletePrivate(ctx context.Context, userID, id int) error
+	// PrivateURL returns a signed URL for a private object and when it
+	// expires.
+	PrivateURL(objectName string) (string, time.Time)
+	// PrivateMediaToResponse maps a private file with a new signed URL.
+	PrivateMediaToResponse(privateMedia *model.PrivateMedia) *response.PrivateMediaResponse
+}
+
+type mediaService struct {
+	mediaStorage     storage.MediaStorage
+	privateMediaRepo repositories.PrivateMediaRepository
+	// Requests for an image that is still being created wait for it
+	// instead of creating it again
+	inflight singleflight.Group
+}
+
+func NewMediaService(mediaStorage storage.MediaStorage, privateMediaRepo repositories.PrivateMediaRepository) MediaService {
+	return &mediaService{
+		mediaStorage:     mediaStorage,
+		privateMediaRepo: privateMediaRepo,
+	}
+}
+
+func (s *mediaService) TransformedObject(ctx context.Context, objectName string, params map[string]string) (string, error) {
+	// Transformed images are public, so private files cannot be transformed
+	if !storage.IsCleanObjectName(objectName) ||
+		strings.HasPrefix(objectName, constants.MediaCachePrefix) || strings.HasPrefix(objectName, constants.PrivateMediaPrefix) {
+		return "", errors.New(constants.ErrInvalidTransform)
+	}
+
+	transform, err := s.parseTransform(objectName, params)
+	if err != nil {
+		return "", err
+	}
+
+	derived := constants.MediaCachePrefix + objectName + "/" + transform.Key()
+	if _, err := s.mediaStorage.Stat(ctx, derived); err == nil {
+		return derived, nil
+	} else if !errors.Is(err, storage.ErrObjectNotFound) {
+		return "", err
+	}
+
+	// Other requests may be waiting on this one, so finish even if its
+	// client goes away
+	_, err, _ = s.inflight.Do(derived, func() (interface{}, error) {
+		return nil, s.createTransformed(context.WithoutCancel(ctx), objectName, derived, transform)
+	})
+	if err != nil {
+		return "", err
+	}
+	return derived, nil
+}
+
+// parseTransform reads a preset, or a transformation that must be signed.
+func (s *mediaService) parseTransform(objectName string, params map[string]string) (media.Transform, error) {
+	if name, ok := params["preset"]; ok {
+		for param := range params {
+			if media.IsTransformParam(param) {
+				return media.Transform{}, errors.New(constants.ErrInvalidTransform)
+			}
+		}
+		preset, ok := media.Presets[name]
+		if !ok {
+			return media.Transform{}, errors.New(constants.ErrInvalidTransform)
+		}
+		return preset, nil
+	}
+
+	transform, err := media.ParseTransform(params)
+	if err != nil {
+		return media.Transform{}, errors.New(constants.ErrInvalidTransform)
+	}
+	if !media.ValidSignature(objectName+"?"+transform.String(), params["s"]) {
+		return media.Transform{}, errors.New(constants.ErrInvalidMediaSignature)
+	}
+	return transform, nil
+}
+
+func (s *mediaService) createTransformed(ctx context.Context, objectName, derived string, transform media.Transform) error {
+	original, err := s.mediaStorage.GetObject(ctx, objectName)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return errors.New(constants.ErrMediaNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	defer original.Close()
+
+	data, err := io.ReadAll(io.LimitReader(original, constants.MaxTransformSourceSize+1))
+	if err != nil {
+		// S3 backends only report a missing object once it is read
+		if _, statErr := s.mediaStorage.Stat(ctx, objectName); errors.Is(statErr, storage.ErrObjectNotFound) {
+			return errors.New(constants.ErrMediaNotFound)
+		}
+		return err
+	}
+	if len(data) > constants.MaxTransformSourceSize {
+		return errors.New(constants.ErrImageTooLarge)
+	}
+
+	image, err := transform.Apply(data)
+	switch {
+	case errors.Is(err, media.ErrUnsupportedImage):
+		return errors.New(constants.ErrUnsupportedImage)
+	case errors.Is(err, media.ErrImageTooLarge):
+		return errors.New(constants.ErrImageTooLarge)
+	case err != nil:
+		return err
+	}
+
+	if _, err := s.mediaStorage.Upload(ctx, derived, bytes.NewReader(image.Data), int64(len(image.Data)), image.ContentType); err != nil {
+		log.Printf("[Media] failed to upload %s: %v", derived, err)
+		return err
+	}
+	return nil
+}
+
+func (s *mediaService) CheckPrivateAccess(objectName string, params map[string]string) (time.Time, error) {
+	if params["expires"] == "" || params["s"] == "" {
+		return time.Time{}, errors.New(constants.ErrInvalidMediaSignature)
+	}
+	expiresAt, valid := media.CheckExpiring(objectName, params["expires"], params["s"])
+	if expiresAt.IsZero() {
+		return time.Time{}, errors.New(constants.ErrInvalidMediaSignature)
+	}
+	if !valid {
+		return time.Time{}, errors.New(constants.ErrMediaURLExpired)
+	}
+	return expiresAt, nil
+}
+
+func (s *mediaService) UploadPrivate(ctx context.Context, userID int, kategori, fileName string, file io.Reader) (*response.PrivateMediaResponse, error) {
+	if !slices.Contains(constants.PrivateMediaCategories, kategori) {
+		return nil, errors.New(constants.ErrInvalidMediaCategory)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, constants.MaxPrivateMediaFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > constants.MaxPrivateMediaFileSize {
+		return nil, errors.New(constants.ErrPrivateMediaTooLarge)
+	}
+
+	// Go by the content, not the name or type the file was sent with
+	contentType := http.DetectContentType(data)
+	extension, ok := privateMediaExtensions[contentType]
+	if !ok {
+		return nil, errors.New(constants.ErrUnsupportedPrivateMedia)
+	}
+
+	baseName := sanitizeFilename(strings.TrimSuffix(fileName, path.Ext(fileName)))
+	objectName := fmt.Sprintf("%s%s/%d/%d_%s%s", constants.PrivateMediaPrefix, kategori, userID, time.Now().UnixNano(), baseName, extension)
+	if _, err := s.mediaStorage.Upload(ctx, objectName, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		log.Printf("[Media] failed to upload %s: %v", objectName, err)
+		return nil, errors.New(constants.ErrPrivateMediaUploadFailed)
+	}
+
+	privateMedia := &model.PrivateMedia{
+		IDUser:      userID,
+		Kategori:    kategori,
+		NamaFile:    fileName,
+		ObjectName:  objectName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	if err := s.privateMediaRepo.Create(privateMedia); err != nil {
+		s.deleteObject(ctx, objectName)
+		return nil, err
+	}
+
+	return s.PrivateMediaToResponse(privateMedia), nil
+}
+
+func (s *mediaService) GetPrivate(userID, id int) (*response.PrivateMediaResponse, error) {
+	privateMedia, err := s.privateMediaRepo.GetByID(id)
+	// Other users' files are reported as missing so their IDs reveal nothing
+	if err != nil || privateMedia.IDUser != userID {
+		return nil, errors.New(constants.ErrMediaNotFound)
+	}
+	return s.PrivateMediaToResponse(privateMedia), nil
+}
+
+func (s *mediaService) GetPrivateForStaff(id int) (*response.PrivateMediaResponse, error) {
+	privateMedia, err := s.privateMediaRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New(constants.ErrMediaNotFound)
+	}
+	return s.PrivateMediaToResponse(privateMedia), nil
+}
+
+func (s *mediaService) DeletePrivate(ctx context.Context, userID, id int) error {
+	privateMedia, err := s.privateMediaRepo.GetByID(id)
+	if err != nil || privateMedia.IDUser != userID {
+		return errors.New(constants.ErrMediaNotFound)
+	}
+	// Verification documents must stay available to reviewers
+	inUse, err := s.privateMediaRepo.InUse(privateMedia.ID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New(constants.ErrPrivateMediaInUse)
+	}
+
+	if err := s.privateMediaRepo.Delete(privateMedia.ID); err != nil {
+		return err
+	}
+	s.deleteObject(ctx, privateMedia.ObjectName)
+	return nil
+}
+
+func (s *mediaService) PrivateURL(objectName string) (string, time.Time) {
+	expiresAt := time.Now().Add(constants.PrivateMediaURLExpiry)
+	signature := media.SignExpiring(objectName, expiresAt)
+	url := fmt.Sprintf("%s?expires=%s&s=%s", storage.ObjectURL(objectName), strconv.FormatInt(expiresAt.Unix(), 10), signature)
+	return url, expiresAt
+}
+
+func (s *mediaService) PrivateMediaToResponse(privateMedia *model.PrivateMedia) *response.PrivateMediaResponse {
+	url, expiresAt := s.PrivateURL(privateMedia.ObjectName)
+	return &response.PrivateMediaResponse{
+		ID:           privateMedia.ID,
+		IDUser:       privateMedia.IDUser,
+		Kategori:     privateMedia.Kategori,
+		NamaFile:     privateMedia.NamaFile,
+		ContentType:  privateMedia.ContentType,
+		Size:         privateMedia.Size,
+		URL:          url,
+		URLExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+		CreatedAt:    privateMedia.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func (s *mediaService) deleteObject(ctx context.Context, objectName string) {
+	if err := s.mediaStorage.Delete(ctx, objectName); err != nil {
+		log.Printf("[Media] failed to delete %s: %v", objectName, err)
+	}
+}
@@ -29,7 +29,7 @@ type midtransService struct {
 // CreatePaymentRequest untuk membuat payment request ke Midtrans
 type CreatePaymentRequest struct {
 	OrderID         string                   `json:"order_id"`
-	GrossAmount     int                      `json:"gross_amount"`
+	GrossAmount     int64                    `json:"gross_amount"`
 	PaymentType     string                   `json:"payment_type"` // virtual_account, e_wallet, bank_transfer, etc
 	CustomerDetails map[string]interface{}   `json:"customer_details"`
 	ItemDetails     []map[string]interface{} `json:"item_details"`
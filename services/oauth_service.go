@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/oauth"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const oauthExchangeCodeTTL = time.Minute
+
+// OAuthCallbackResult tells the handler where to send the browser after a callback.
+type OAuthCallbackResult struct {
+	ExchangeCode string // Set for sign-in flows
+	LinkCode     string // Set for link flows, to be confirmed by the signed-in user
+}
+
+type OAuthService interface {
+	Providers() []string
+	// Begin starts a sign-in flow bound to the browser by the sealed state.
+	Begin(provider string) (authURL string, sealedState string, err error)
+	Callback(ctx context.Context, provider, code, state, sealedState string) (*OAuthCallbackResult, error)
+	Exchange(req *request.OAuthExchangeRequest) (*response.AuthResponse, error)
+	// BeginLink starts linking a provider account to the signed-in user.
+	BeginLink(userID int, provider string) (*response.OAuthLinkResponse, error)
+	// ConfirmLink links the identity from a link flow's callback, if the
+	// signed-in user is the one who started the flow.
+	ConfirmLink(userID int, provider string, req *request.ConfirmOAuthLinkRequest) (*response.IdentityResponse, error)
+	ListIdentities(userID int) ([]response.IdentityResponse, error)
+	Unlink(userID int, provider string) error
+}
+
+type oauthService struct {
+	registry         *oauth.Registry
+	identityRepo     repositories.IdentityRepository
+	userRepo         repositories.UserRepository
+	twoFactorService TwoFactorService
+}
+
+func NewOAuthService(registry *oauth.Registry, identityRepo repositories.IdentityRepository, userRepo repositories.UserRepository, twoFactorService TwoFactorService) OAuthService {
+	return &oauthService{
+		registry:         registry,
+		identityRepo:     identityRepo,
+		userRepo:         userRepo,
+		twoFactorService: twoFactorService,
+	}
+}
+
+func (s *oauthService) Providers() []string {
+	return s.registry.Names()
+}
+
+func (s *oauthService) Begin(providerName string) (string, string, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return "", "", errors.New(constants.ErrOAuthProviderNotFound)
+	}
+
+	flow, err := oauth.NewFlowState(provider.Name(), 0)
+	if err != nil {
+		return "", "", err
+	}
+
+	sealed, err := flow.Seal()
+	if err != nil {
+		return "", "", err
+	}
+
+	return provider.AuthCodeURL(flow.State, oauth.CodeChallengeS256(flow.CodeVerifier), flow.Nonce), sealed, nil
+}
+
+func (s *oauthService) Callback(ctx context.Context, providerName, code, state, sealedState string) (*OAuthCallbackResult, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return nil, errors.New(constants.ErrOAuthProviderNotFound)
+	}
+
+	var flow *oauth.FlowState
+	if oauth.IsLinkState(state) {
+		flow, err = oauth.OpenLinkState(state, provider.Name())
+	} else {
+		flow, err = oauth.OpenFlowState(sealedState, provider.Name(), state)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := provider.Exchange(ctx, code, flow.CodeVerifier, flow.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if flow.LinkUserID != 0 {
+		// Whoever completes the consent screen reaches this point, so the
+		// identity is only linked once the user confirms it while signed in
+		if existing, err := s.identityRepo.GetByProviderSubject(identity.Provider, identity.Subject); err == nil && existing.IDUser != flow.LinkUserID {
+			return nil, errors.New(constants.ErrIdentityAlreadyLinked)
+		}
+
+		pending := &oauth.PendingLink{
+			UserID:    flow.LinkUserID,
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			ExpiresAt: time.Now().Add(oauth.PendingLinkTTL),
+		}
+		linkCode, err := pending.Seal()
+		if err != nil {
+			return nil, err
+		}
+		return &OAuthCallbackResult{LinkCode: linkCode}, nil
+	}
+
+	user, err := s.resolveUser(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeCode, err := oauth.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.identityRepo.CreateExchangeCode(&model.OAuthExchangeCode{
+		CodeHash:  hashExchangeCode(exchangeCode),
+		IDUser:    user.ID,
+		ExpiresAt: time.Now().Add(oauthExchangeCodeTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &OAuthCallbackResult{ExchangeCode: exchangeCode}, nil
+}
+
+func (s *oauthService) Exchange(req *request.OAuthExchangeRequest) (*response.AuthResponse, error) {
+	exchangeCode, err := s.identityRepo.ConsumeExchangeCode(hashExchangeCode(req.Code))
+	if err != nil {
+		return nil, errors.New(constants.ErrInvalidExchangeCode)
+	}
+
+	user, err := s.userRepo.GetByID(exchangeCode.IDUser)
+	if err != nil {
+		return nil, errors.New(constants.ErrUserNotFound)
+	}
+
+	// Issue token, or a 2FA challenge if enabled
+	return s.twoFactorService.CompleteLogin(user)
+}
+
+func (s *oauthService) BeginLink(userID int, providerName string) (*response.OAuthLinkResponse, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return nil, errors.New(constants.ErrOAuthProviderNotFound)
+	}
+
+	flow, err := oauth.NewFlowState(provider.Name(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := flow.LinkState()
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.OAuthLinkResponse{
+		AuthorizationURL: provider.AuthCodeURL(state, oauth.CodeChallengeS256(flow.CodeVerifier), flow.Nonce),
+	}, nil
+}
+
+func (s *oauthService) ConfirmLink(userID int, providerName string, req *request.ConfirmOAuthLinkRequest) (*response.IdentityResponse, error) {
+	pending, err := oauth.OpenPendingLink(req.Code)
+	if err != nil || pending.UserID != userID || pending.Provider != providerName {
+		return nil, errors.New(constants.ErrInvalidLinkCode)
+	}
+
+	identity := &oauth.Identity{
+		Provider: pending.Provider,
+		Subject:  pending.Subject,
+		Email:    pending.Email,
+	}
+	if err := s.link(userID, identity); err != nil {
+		return nil, err
+	}
+
+	linked, err := s.identityRepo.GetByProviderSubject(identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	return &response.IdentityResponse{
+		Provider:  linked.Provider,
+		Email:     linked.Email,
+		CreatedAt: linked.CreatedAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+func (s *oauthService) ListIdentities(userID int) ([]response.IdentityResponse, error) {
+	identities, err := s.identityRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var identityResponses []response.IdentityResponse
+	for _, identity := range identities {
+		identityResponses = append(identityResponses, response.IdentityResponse{
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return identityResponses, nil
+}
+
+func (s *oauthService) Unlink(userID int, provider string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New(constants.ErrUserNotFound)
+	}
+
+	identities, err := s.identityRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	// Don't let users lock themselves out of an account without a password
+	if !hasPassword(user) && len(identities) <= 1 {
+		return errors.New(constants.ErrCannotUnlinkLastLogin)
+	}
+
+	deleted, err := s.identityRepo.Delete(userID, provider)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New(constants.ErrIdentityNotFound)
+	}
+	return nil
+}
+
+// link attaches an identity to a signed-in user.
+func (s *oauthService) link(userID int, identity *oauth.Identity) error {
+	existing, err := s.identityRepo.GetByProviderSubject(identity.Provider, identity.Subject)
+	if err == nil {
+		if existing.IDUser != userID {
+			return errors.New(constants.ErrIdentityAlreadyLinked)
+		}
+		return nil
+	}
+
+	return s.identityRepo.Create(&model.UserIdentity{
+		IDUser:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+}
+
+// resolveUser finds the user for an identity, linking it to an existing
+// account with the same email when both sides verified it, or creating a
+// new account.
+func (s *oauthService) resolveUser(identity *oauth.Identity) (*model.User, error) {
+	if existing, err := s.identityRepo.GetByProviderSubject(identity.Provider, identity.Subject); err == nil {
+		return s.userRepo.GetByID(existing.IDUser)
+	}
+
+	// Matching on email is only safe when the provider has verified it
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errors.New(constants.ErrEmailNotVerified)
+	}
+
+	user, err := s.userRepo.GetByEmail(identity.Email)
+	if err != nil {
+		user, err = s.createUser(identity)
+		if err != nil {
+			return nil, err
+		}
+	} else if user.EmailVerifiedAt == nil {
+		// Anyone can register an unverified address, so linking to such an
+		// account could hand it to whoever registered it first. The owner
+		// has to sign in and link the provider explicitly.
+		return nil, errors.New(constants.ErrOAuthAccountExists)
+	} else if identity.Picture != "" && user.PhotoURL == "" {
+		// Update photo if provided and not already set
+		user.PhotoURL = identity.Picture
+		_ = s.userRepo.Update(user)
+	}
+
+	if err := s.identityRepo.Create(&model.UserIdentity{
+		IDUser:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createUser creates a minimal user profile for a new external identity.
+// Required non-null fields are filled with defaults.
+func (s *oauthService) createUser(identity *oauth.Identity) (*model.User, error) {
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+
+	now := time.Now()
+	user := &model.User{
+		Nama:         name,
+		KataSandi:    identity.Provider + "-oauth", // not a bcrypt hash, so password login is impossible
+		NoTelp:       identity.Provider + "-" + identity.Subject,
+		PhotoURL:     identity.Picture,
+		TanggalLahir: time.Now(),
+		Email:        identity.Email,
+		IsAdmin:      false,
+		// resolveUser only creates users for emails the provider verified
+		EmailVerifiedAt: &now,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// hasPassword reports whether the user can sign in with a password.
+func hasPassword(user *model.User) bool {
+	_, err := bcrypt.Cost([]byte(user.KataSandi))
+	return err == nil
+}
+
+func hashExchangeCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
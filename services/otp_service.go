@@ -0,0 +1,231 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/utils"
+)
+
+type OTPService interface {
+	RequestPhoneVerification(userID int) error
+	VerifyPhone(userID int, req *request.VerifyPhoneRequest) (*response.UserProfile, error)
+	RequestLoginOTP(req *request.RequestLoginOTPRequest) error
+	LoginWithOTP(req *request.LoginOTPRequest) (*response.AuthResponse, error)
+}
+
+type otpService struct {
+	otpRepo          repositories.OTPRepository
+	userRepo         repositories.UserRepository
+	smsSender        SMSSender
+	twoFactorService TwoFactorService
+}
+
+func NewOTPService(otpRepo repositories.OTPRepository, userRepo repositories.UserRepository, smsSender SMSSender, twoFactorService TwoFactorService) OTPService {
+	return &otpService{
+		otpRepo:          otpRepo,
+		userRepo:         userRepo,
+		smsSender:        smsSender,
+		twoFactorService: twoFactorService,
+	}
+}
+
+func (s *otpService) RequestPhoneVerification(userID int) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New(constants.ErrUserNotFound)
+	}
+
+	if user.NoTelpVerifiedAt != nil {
+		return errors.New(constants.ErrPhoneAlreadyVerified)
+	}
+
+	noTelp, err := utils.NormalizePhoneNumber(user.NoTelp)
+	if err != nil {
+		return errors.New(constants.ErrInvalidPhone)
+	}
+
+	return s.issue(noTelp, constants.OTPPurposePhoneVerification, &user.ID)
+}
+
+func (s *otpService) VerifyPhone(userID int, req *request.VerifyPhoneRequest) (*response.UserProfile, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New(constants.ErrUserNotFound)
+	}
+
+	if user.NoTelpVerifiedAt != nil {
+		return nil, errors.New(constants.ErrPhoneAlreadyVerified)
+	}
+
+	noTelp, err := utils.NormalizePhoneNumber(user.NoTelp)
+	if err != nil {
+		return nil, errors.New(constants.ErrInvalidPhone)
+	}
+
+	if err := s.verify(noTelp, constants.OTPPurposePhoneVerification, req.Kode); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.NoTelp = noTelp
+	user.NoTelpVerifiedAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	userProfile := mapUserToProfile(user)
+	return &userProfile, nil
+}
+
+func (s *otpService) RequestLoginOTP(req *request.RequestLoginOTPRequest) error {
+	noTelp, err := utils.NormalizePhoneNumber(req.NoTelp)
+	if err != nil {
+		return errors.New(constants.ErrInvalidPhone)
+	}
+
+	// For security, don't reveal if the phone number is registered or not:
+	// unregistered numbers go through the same cooldown, but get no SMS
+	var userID *int
+	if user, err := s.userRepo.GetByPhone(noTelp); err == nil {
+		userID = &user.ID
+	}
+
+	return s.issue(noTelp, constants.OTPPurposeLogin, userID)
+}
+
+func (s *otpService) LoginWithOTP(req *request.LoginOTPRequest) (*response.AuthResponse, error) {
+	noTelp, err := utils.NormalizePhoneNumber(req.NoTelp)
+	if err != nil {
+		return nil, errors.New(constants.ErrInvalidPhone)
+	}
+
+	user, err := s.userRepo.GetByPhone(noTelp)
+	if err != nil {
+		return nil, errors.New(constants.ErrInvalidOTP)
+	}
+
+	if err := s.verify(noTelp, constants.OTPPurposeLogin, req.Kode); err != nil {
+		return nil, err
+	}
+
+	// Receiving the code proves ownership of the number
+	if user.NoTelpVerifiedAt == nil {
+		now := time.Now()
+		user.NoTelpVerifiedAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.twoFactorService.CompleteLogin(user)
+}
+
+// issue generates a new code for the phone, enforcing the resend cooldown,
+// and delivers it via SMS when the number belongs to a user. Older
+// outstanding codes are invalidated.
+func (s *otpService) issue(noTelp, purpose string, userID *int) error {
+	if latest, err := s.otpRepo.GetLatest(noTelp, purpose); err == nil {
+		if time.Since(latest.CreatedAt) < constants.OTPResendCooldown {
+			return errors.New(constants.ErrOTPResendCooldown)
+		}
+	}
+
+	code, err := generateNumericCode(constants.OTPLength)
+	if err != nil {
+		return err
+	}
+
+	if err := s.otpRepo.InvalidateActive(noTelp, purpose); err != nil {
+		return err
+	}
+
+	otp := &model.PhoneOTP{
+		NoTelp:    noTelp,
+		Purpose:   purpose,
+		CodeHash:  hashOTP(noTelp, purpose, code),
+		ExpiresAt: time.Now().Add(constants.OTPTTL),
+		IDUser:    userID,
+	}
+	if err := s.otpRepo.Create(otp); err != nil {
+		return err
+	}
+
+	if userID == nil {
+		return nil
+	}
+
+	message := fmt.Sprintf("Kode OTP Warung Budeh Ramah Anda: %s. Berlaku %d menit. Jangan berikan kode ini kepada siapa pun.", code, int(constants.OTPTTL.Minutes()))
+	if err := s.smsSender.SendSMS(noTelp, message); err != nil {
+		return fmt.Errorf("failed to send OTP: %w", err)
+	}
+
+	return nil
+}
+
+// verify checks a code against the latest issued OTP and consumes it on success.
+func (s *otpService) verify(noTelp, purpose, code string) error {
+	otp, err := s.otpRepo.GetLatest(noTelp, purpose)
+	if err != nil || otp.ConsumedAt != nil {
+		return errors.New(constants.ErrInvalidOTP)
+	}
+
+	if time.Now().After(otp.ExpiresAt) {
+		return errors.New(constants.ErrOTPExpired)
+	}
+
+	// Every guess takes an attempt before the code is compared, so parallel
+	// guesses cannot get past the limit
+	taken, err := s.otpRepo.TakeAttempt(otp.ID, constants.OTPMaxAttempts)
+	if err != nil {
+		return err
+	}
+	if !taken {
+		return errors.New(constants.ErrOTPTooManyAttempts)
+	}
+
+	if !hmac.Equal([]byte(otp.CodeHash), []byte(hashOTP(noTelp, purpose, code))) {
+		return errors.New(constants.ErrInvalidOTP)
+	}
+
+	// Only one of several concurrent correct submissions consumes the code
+	consumed, err := s.otpRepo.MarkConsumed(otp.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return errors.New(constants.ErrInvalidOTP)
+	}
+	return nil
+}
+
+// hashOTP derives a keyed hash so stored codes are useless without SECRET_KEY.
+func hashOTP(noTelp, purpose, code string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SECRET_KEY")))
+	mac.Write([]byte(purpose + ":" + noTelp + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateNumericCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
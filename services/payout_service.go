@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+)
+
+type PayoutService interface {
+	GetBankAccount(userID, shopID int) (*response.BankAccountResponse, error)
+	SaveBankAccount(userID, shopID int, req *request.SaveBankAccountRequest) (*response.BankAccountResponse, error)
+	// RequestPayout reserves part of the shop's available balance to be
+	// transferred to its bank account.
+	RequestPayout(userID, shopID int, req *request.RequestPayoutRequest) (*response.PayoutResponse, error)
+	GetShopPayouts(userID, shopID int) ([]response.PayoutResponse, error)
+	GetPayouts(query *request.PayoutQuery) ([]response.PayoutResponse, error)
+	// Approve records that the payout was transferred.
+	Approve(actorID, id int, req *request.ReviewPayoutRequest) (*response.PayoutResponse, error)
+	// Reject returns the payout's amount to the shop's available balance.
+	Reject(actorID, id int, req *request.ReviewPayoutRequest) (*response.PayoutResponse, error)
+}
+
+type payoutService struct {
+	payoutRepo              repositories.PayoutRepository
+	shopMemberService       ShopMemberService
+	shopVerificationService ShopVerificationService
+}
+
+func NewPayoutService(payoutRepo repositories.PayoutRepository, shopMemberService ShopMemberService, shopVerificationService ShopVerificationService) PayoutService {
+	return &payoutService{
+		payoutRepo:              payoutRepo,
+		shopMemberService:       shopMemberService,
+		shopVerificationService: shopVerificationService,
+	}
+}
+
+func (s *payoutService) GetBankAccount(userID, shopID int) (*response.BankAccountResponse, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermFinance); err != nil {
+		return nil, err
+	}
+
+	account, err := s.payoutRepo.GetBankAccount(shopID)
+	if err != nil {
+		return nil, errors.New(constants.ErrBankAccountRequired)
+	}
+	return mapBankAccountToResponse(account), nil
+}
+
+func (s *payoutService) SaveBankAccount(userID, shopID int, req *request.SaveBankAccountRequest) (*response.BankAccountResponse, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermFinance); err != nil {
+		return nil, err
+	}
+
+	account, err := s.payoutRepo.GetBankAccount(shopID)
+	if err != nil {
+		account = &model.ShopBankAccount{IDToko: shopID}
+	}
+	account.NamaBank = strings.TrimSpace(req.NamaBank)
+	account.NomorRekening = req.NomorRekening
+	account.NamaPemilik = strings.TrimSpace(req.NamaPemilik)
+	if err := s.payoutRepo.SaveBankAccount(account); err != nil {
+		return nil, err
+	}
+	return mapBankAccountToResponse(account), nil
+}
+
+func (s *payoutService) RequestPayout(userID, shopID int, req *request.RequestPayoutRequest) (*response.PayoutResponse, error) {
+	shop, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermFinance)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.shopVerificationService.CheckPayoutAllowed(shop); err != nil {
+		return nil, err
+	}
+	if req.Jumlah < constants.MinPayoutAmount {
+		return nil, errors.New(constants.ErrPayoutBelowMinimum)
+	}
+
+	account, err := s.payoutRepo.GetBankAccount(shopID)
+	if err != nil {
+		return nil, errors.New(constants.ErrBankAccountRequired)
+	}
+
+	payout := &model.Payout{
+		IDToko:        shopID,
+		IDUser:        userID,
+		Jumlah:        req.Jumlah,
+		NamaBank:      account.NamaBank,
+		NomorRekening: account.NomorRekening,
+		NamaPemilik:   account.NamaPemilik,
+		Status:        constants.PayoutPending,
+	}
+	journal := &model.LedgerJournal{
+		Jenis:      constants.LedgerJournalPayoutRequest,
+		Keterangan: fmt.Sprintf("Penarikan ke %s %s", account.NamaBank, account.NomorRekening),
+		Entri: []model.LedgerEntry{
+			{Akun: constants.LedgerAccountShopAvailable, IDToko: &shopID, Debit: req.Jumlah},
+			{Akun: constants.LedgerAccountShopPayout, IDToko: &shopID, Kredit: req.Jumlah},
+		},
+	}
+	created, err := s.payoutRepo.Create(payout, journal)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, errors.New(constants.ErrInsufficientBalance)
+	}
+
+	payout.Toko = *shop
+	payoutResponse := mapPayoutToResponse(*payout)
+	return &payoutResponse, nil
+}
+
+func (s *payoutService) GetShopPayouts(userID, shopID int) ([]response.PayoutResponse, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermFinance); err != nil {
+		return nil, err
+	}
+
+	payouts, err := s.payoutRepo.GetByShopID(shopID)
+	if err != nil {
+		return nil, err
+	}
+	return mapPayoutsToResponse(payouts), nil
+}
+
+func (s *payoutService) GetPayouts(query *request.PayoutQuery) ([]response.PayoutResponse, error) {
+	payouts, err := s.payoutRepo.GetAll(query.Status)
+	if err != nil {
+		return nil, err
+	}
+	return mapPayoutsToResponse(payouts), nil
+}
+
+func (s *payoutService) Approve(actorID, id int, req *request.ReviewPayoutRequest) (*response.PayoutResponse, error) {
+	return s.review(actorID, id, constants.PayoutApproved, req, func(payout *model.Payout) *model.LedgerJournal {
+		return &model.LedgerJournal{
+			Jenis:      constants.LedgerJournalPayoutApproved,
+			Keterangan: fmt.Sprintf("Penarikan ditransfer ke %s %s", payout.NamaBank, payout.NomorRekening),
+			Entri: []model.LedgerEntry{
+				{Akun: constants.LedgerAccountShopPayout, IDToko: &payout.IDToko, Debit: payout.Jumlah},
+				{Akun: constants.LedgerAccountPlatformCash, IDToko: &payout.IDToko, Kredit: payout.Jumlah},
+			},
+		}
+	})
+}
+
+func (s *payoutService) Reject(actorID, id int, req *request.ReviewPayoutRequest) (*response.PayoutResponse, error) {
+	return s.review(actorID, id, constants.PayoutRejected, req, func(payout *model.Payout) *model.LedgerJournal {
+		return &model.LedgerJournal{
+			Jenis:      constants.LedgerJournalPayoutRejected,
+			Keterangan: "Penarikan ditolak",
+			Entri: []model.LedgerEntry{
+				{Akun: constants.LedgerAccountShopPayout, IDToko: &payout.IDToko, Debit: payout.Jumlah},
+				{Akun: constants.LedgerAccountShopAvailable, IDToko: &payout.IDToko, Kredit: payout.Jumlah},
+			},
+		}
+	})
+}
+
+// review decides a pending payout, posting the journal that settles its
+// reserved amount in the same transaction.
+func (s *payoutService) review(actorID, id int, to string, req *request.ReviewPayoutRequest, settle func(*model.Payout) *model.LedgerJournal) (*response.PayoutResponse, error) {
+	payout, err := s.payoutRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New(constants.ErrPayoutNotFound)
+	}
+	if payout.Status != constants.PayoutPending {
+		return nil, errors.New(constants.ErrPayoutNotPending)
+	}
+
+	now := time.Now()
+	payout.Status = to
+	payout.Catatan = strings.TrimSpace(req.Catatan)
+	payout.ReviewedBy = &actorID
+	payout.ReviewedAt = &now
+	payout.UpdatedAt = now
+	reviewed, err := s.payoutRepo.Review(payout, settle(payout))
+	if err != nil {
+		return nil, err
+	}
+	// Another reviewer decided it in the meantime
+	if !reviewed {
+		return nil, errors.New(constants.ErrPayoutNotPending)
+	}
+
+	payoutResponse := mapPayoutToResponse(*payout)
+	return &payoutResponse, nil
+}
+
+func mapBankAccountToResponse(account *model.ShopBankAccount) *response.BankAccountResponse {
+	return &response.BankAccountResponse{
+		IDToko:        account.IDToko,
+		NamaBank:      account.NamaBank,
+		NomorRekening: account.NomorRekening,
+		NamaPemilik:   account.NamaPemilik,
+		UpdatedAt:     account.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func mapPayoutsToResponse(payouts []model.Payout) []response.PayoutResponse {
+	payoutResponses := []response.PayoutResponse{}
+	for _, payout := range payouts {
+		payoutResponses = append(payoutResponses, mapPayoutToResponse(payout))
+	}
+	return payoutResponses
+}
+
+func mapPayoutToResponse(payout model.Payout) response.PayoutResponse {
+	payoutResponse := response.PayoutResponse{
+		ID:            payout.ID,
+		IDToko:        payout.IDToko,
+		NamaToko:      payout.Toko.NamaToko,
+		IDUser:        payout.IDUser,
+		Jumlah:        payout.Jumlah,
+		NamaBank:      payout.NamaBank,
+		NomorRekening: payout.NomorRekening,
+		NamaPemilik:   payout.NamaPemilik,
+		Status:        payout.Status,
+		Catatan:       payout.Catatan,
+		ReviewedBy:    payout.ReviewedBy,
+		CreatedAt:     payout.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     payout.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if payout.ReviewedAt != nil {
+		payoutResponse.ReviewedAt = payout.ReviewedAt.Format("2006-01-02 15:04:05")
+	}
+	return payoutResponse
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/media"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/storage"
+)
+
+// Expired uploads removed per cleanup query
+const photoUploadCleanupBatch = 100
+
+// PhotoUploadService lets clients upload product photos straight to media
+// storage instead of through the API.
+type PhotoUploadService interface {
+	// CreateUpload checks the declared file and returns a presigned URL to
+	// upload it to.
+	CreateUpload(ctx context.Context, userID, productID int, req *request.CreatePhotoUploadRequest) (*response.PhotoUploadResponse, error)
+	// ConfirmUpload adds an uploaded file to the product's photos the same
+	// way as a photo uploaded through the API.
+	ConfirmUpload(ctx context.Context, userID, productID, uploadID int) (*response.ProductResponse, error)
+	// CleanupExpiredUploads removes uploads that were never confirmed and
+	// returns how many were removed.
+	CleanupExpiredUploads(ctx context.Context) (int, error)
+	// RunCleanup calls CleanupExpiredUploads every interval until ctx is
+	// done.
+	RunCleanup(ctx context.Context, interval time.Duration)
+}
+
+type photoUploadService struct {
+	uploadRepo        repositories.PhotoUploadRepository
+	productRepo       repositories.ProductRepository
+	shopMemberService ShopMemberService
+	productService    ProductService
+	mediaStorage      storage.MediaStorage
+}
+
+func NewPhotoUploadService(uploadRepo repositories.PhotoUploadRepository, productRepo repositories.ProductRepository, shopMemberService ShopMemberService, productService ProductService, mediaStorage storage.MediaStorage) PhotoUploadService {
+	return &photoUploadService{
+		uploadRepo:        uploadRepo,
+		productRepo:       productRepo,
+		shopMemberService: shopMemberService,
+		productService:    productService,
+		mediaStorage:      mediaStorage,
+	}
+}
+
+func (s *photoUploadService) CreateUpload(ctx context.Context, userID, productID int, req *request.CreatePhotoUploadRequest) (*response.PhotoUploadResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, errors.New(constants.ErrProductNotFound)
+	}
+	if _, err := s.shopMemberService.Authorize(userID, product.IDToko, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+
+	count, err := s.productRepo.CountPhotos(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= constants.MaxProductPhotos {
+		return nil, errors.New(constants.ErrPhotoLimitReached)
+	}
+
+	if !media.IsSupportedType(req.ContentType) {
+		return nil, errors.New(constants.ErrUnsupportedImage)
+	}
+	if req.Size > constants.MaxPhotoFileSize {
+		return nil, errors.New(constants.ErrPhotoTooLarge)
+	}
+
+	baseName := sanitizeFilename(strings.TrimSuffix(req.NamaFile, path.Ext(req.NamaFile)))
+	objectName := fmt.Sprintf("uploads/products/%d/%d_%s", product.ID, time.Now().UnixNano(), baseName)
+
+	presigned, err := s.mediaStorage.PresignUpload(ctx, objectName, req.ContentType, req.Size, constants.PhotoUploadExpiry)
+	if errors.Is(err, storage.ErrPresignNotSupported) {
+		return nil, errors.New(constants.ErrDirectUploadUnsupported)
+	}
+	if err != nil {
+		log.Printf("[Media] failed to presign %s: %v", objectName, err)
+		return nil, errors.New(constants.ErrPhotoUploadFailed)
+	}
+
+	upload := &model.PhotoUpload{
+		IDProduk:    product.ID,
+		IDUser:      userID,
+		NamaFile:    req.NamaFile,
+		ObjectName:  objectName,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		Status:      constants.PhotoUploadPending,
+		ExpiresAt:   presigned.ExpiresAt,
+	}
+	if err := s.uploadRepo.Create(upload); err != nil {
+		return nil, err
+	}
+
+	return &response.PhotoUploadResponse{
+		ID:        upload.ID,
+		IDProduk:  upload.IDProduk,
+		UploadURL: presigned.URL,
+		Method:    presigned.Method,
+		Headers:   presigned.Headers,
+		ExpiresAt: upload.ExpiresAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+func (s *photoUploadService) ConfirmUpload(ctx context.Context, userID, productID, uploadID int) (*response.ProductResponse, error) {
+	upload, err := s.uploadRepo.GetByID(uploadID)
+	if err != nil || upload.IDProduk != productID || upload.IDUser != userID {
+		return nil, errors.New(constants.ErrPhotoUploadNotFound)
+	}
+
+	// Claim the upload so a concurrent confirmation can't add the photo
+	// twice. Unless it is confirmed below, it goes back to pending so the
+	// client can try again before it expires.
+	now := time.Now()
+	claimed, err := s.uploadRepo.Claim(upload.ID, now, now.Add(constants.PhotoUploadExpiry))
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, errors.New(constants.ErrPhotoUploadNotFound)
+	}
+	confirmed := false
+	defer func() {
+		if confirmed {
+			return
+		}
+		if err := s.uploadRepo.Release(upload); err != nil {
+			log.Printf("[Media] failed to release upload %d: %v", upload.ID, err)
+		}
+	}()
+
+	info, err := s.mediaStorage.Stat(ctx, upload.ObjectName)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return nil, errors.New(constants.ErrPhotoUploadIncomplete)
+	}
+	if err != nil {
+		log.Printf("[Media] failed to stat %s: %v", upload.ObjectName, err)
+		return nil, errors.New(constants.ErrPhotoUploadFailed)
+	}
+	if info.Size != upload.Size {
+		// Storage enforces the signed size, so this only happens if the
+		// object was replaced some other way. Let the client upload again.
+		s.deleteObject(ctx, upload.ObjectName)
+		return nil, errors.New(constants.ErrPhotoUploadMismatch)
+	}
+
+	object, err := s.mediaStorage.GetObject(ctx, upload.ObjectName)
+	if err != nil {
+		log.Printf("[Media] failed to read %s: %v", upload.ObjectName, err)
+		return nil, errors.New(constants.ErrPhotoUploadFailed)
+	}
+	defer object.Close()
+
+	// The staging copy is not needed once the photo has been processed,
+	// whether or not it was accepted. After a storage failure it is kept so
+	// the client can confirm again.
+	product, err := s.productService.AddProductPhoto(ctx, userID, productID, upload.NamaFile, object)
+	if err != nil {
+		if err.Error() != constants.ErrPhotoUploadFailed {
+			s.deleteObject(ctx, upload.ObjectName)
+		}
+		return nil, err
+	}
+	s.deleteObject(ctx, upload.ObjectName)
+
+	confirmed = true
+	confirmedAt := time.Now()
+	upload.Status = constants.PhotoUploadConfirmed
+	upload.ConfirmedAt = &confirmedAt
+	if err := s.uploadRepo.Update(upload); err != nil {
+		log.Printf("[Media] failed to mark upload %d confirmed: %v", upload.ID, err)
+	}
+
+	return product, nil
+}
+
+func (s *photoUploadService) CleanupExpiredUploads(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		uploads, err := s.uploadRepo.GetExpired(time.Now(), photoUploadCleanupBatch)
+		if err != nil {
+			return removed, err
+		}
+
+		for _, upload := range uploads {
+			if err := s.mediaStorage.Delete(ctx, upload.ObjectName); err != nil {
+				// Keep the row so the object is retried next time
+				log.Printf("[Media] failed to delete %s: %v", upload.ObjectName, err)
+				return removed, err
+			}
+			if err := s.uploadRepo.Delete(upload.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+
+		if len(uploads) < photoUploadCleanupBatch {
+			return removed, nil
+		}
+	}
+}
+
+func (s *photoUploadService) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := s.CleanupExpiredUploads(ctx)
+		if err != nil {
+			log.Printf("[Media] failed to clean up expired uploads: %v", err)
+		} else if removed > 0 {
+			log.Printf("[Media] removed %d expired uploads", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *photoUploadService) deleteObject(ctx context.Context, objectName string) {
+	if err := s.mediaStorage.Delete(ctx, objectName); err != nil {
+		log.Printf("[Media] failed to delete %s: %v", objectName, err)
+	}
+}
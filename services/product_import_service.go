@@ -0,0 +1,430 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/utils"
+)
+
+type ProductImportService interface {
+	// StartImport checks the file's header and queues its rows to be
+	// validated and, unless dryRun, created as products of the shop.
+	StartImport(userID, shopID int, fileName string, file io.Reader, dryRun bool) (*response.ProductImportResponse, error)
+	GetImports(userID, shopID int) ([]response.ProductImportResponse, error)
+	GetImport(userID, shopID, importID int) (*response.ProductImportResponse, error)
+	// GetErrorReport returns the rejected rows of an import as a CSV file.
+	GetErrorReport(userID, shopID, importID int) ([]byte, error)
+	// ExportProducts returns the shop's catalog in the import format.
+	ExportProducts(userID, shopID int, format string) ([]byte, error)
+	// FailInterrupted marks imports left unfinished by a restart as failed.
+	FailInterrupted() error
+}
+
+type productImportService struct {
+	importRepo        repositories.ProductImportRepository
+	productRepo       repositories.ProductRepository
+	categoryRepo      repositories.CategoryRepository
+	productService    ProductService
+	shopMemberService ShopMemberService
+	validator         *validator.Validate
+}
+
+func NewProductImportService(importRepo repositories.ProductImportRepository, productRepo repositories.ProductRepository, categoryRepo repositories.CategoryRepository, productService ProductService, shopMemberService ShopMemberService) ProductImportService {
+	validate := validator.New()
+	// Report fields by their column names
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "id_category" {
+			return constants.ProductColumnKategori
+		}
+		return name
+	})
+
+	return &productImportService{
+		importRepo:        importRepo,
+		productRepo:       productRepo,
+		categoryRepo:      categoryRepo,
+		productService:    productService,
+		shopMemberService: shopMemberService,
+		validator:         validate,
+	}
+}
+
+// productImportBatchSize is how many rows are processed between progress
+// updates
+const productImportBatchSize = 100
+
+// errImportTooManyRows stops reading a file once it has more rows than an
+// import may have
+var errImportTooManyRows = errors.New("too many rows")
+
+// importRow is a data row of an import file with its row number in the file.
+type importRow struct {
+	baris  int
+	values []string
+}
+
+func (s *productImportService) StartImport(userID, shopID int, fileName string, file io.Reader, dryRun bool) (*response.ProductImportResponse, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+
+	format := utils.SpreadsheetFormat(fileName)
+	if format == "" {
+		return nil, errors.New(constants.ErrUnsupportedSpreadsheet)
+	}
+
+	// Read row by row so an oversized file is rejected at the limit instead
+	// of after reading all of it; blank rows are skipped but keep the row
+	// numbers of the others
+	var header []string
+	var dataRows []importRow
+	baris := 0
+	err := utils.ReadSpreadsheet(format, file, func(values []string) error {
+		baris++
+		if baris == 1 {
+			header = values
+			return nil
+		}
+		if strings.TrimSpace(strings.Join(values, "")) == "" {
+			return nil
+		}
+		if len(dataRows) == constants.ProductImportMaxRows {
+			return errImportTooManyRows
+		}
+		dataRows = append(dataRows, importRow{baris: baris, values: values})
+		return nil
+	})
+	if errors.Is(err, errImportTooManyRows) {
+		return nil, errors.New(constants.ErrImportTooManyRows)
+	}
+	if err != nil || header == nil {
+		return nil, errors.New(constants.ErrInvalidSpreadsheet)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	var missing []string
+	for _, name := range constants.ProductImportRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s: %s", constants.ErrImportMissingColumns, strings.Join(missing, ", "))
+	}
+
+	if len(dataRows) == 0 {
+		return nil, errors.New(constants.ErrImportNoRows)
+	}
+
+	productImport := &model.ProductImport{
+		IDToko:    shopID,
+		IDUser:    userID,
+		NamaFile:  fileName,
+		Format:    format,
+		DryRun:    dryRun,
+		Status:    constants.ProductImportPending,
+		TotalRows: len(dataRows),
+	}
+	if err := s.importRepo.Create(productImport); err != nil {
+		return nil, err
+	}
+
+	go s.runImport(*productImport, columns, dataRows)
+
+	importResponse := mapProductImportToResponse(*productImport)
+	return &importResponse, nil
+}
+
+// runImport validates and creates the rows of an import, saving progress and
+// rejected rows after every batch.
+func (s *productImportService) runImport(productImport model.ProductImport, columns map[string]int, rows []importRow) {
+	productImport.Status = constants.ProductImportProcessing
+	if err := s.importRepo.Update(&productImport); err != nil {
+		log.Printf("[Import] failed to start import %d: %v", productImport.ID, err)
+		return
+	}
+
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		s.finishImport(&productImport, constants.ProductImportFailed, err.Error())
+		return
+	}
+	categoryIDs := make(map[string]int)
+	for _, category := range categories {
+		categoryIDs[strings.ToLower(strings.TrimSpace(category.Nama))] = category.ID
+	}
+
+	var importErrors []model.ProductImportError
+	for i, row := range rows {
+		req, rowErrors := s.parseRow(productImport, columns, categoryIDs, row)
+		if len(rowErrors) == 0 && !productImport.DryRun {
+			if _, err := s.productService.CreateProduct(productImport.IDUser, req); err != nil {
+				rowErrors = append(rowErrors, model.ProductImportError{IDImport: productImport.ID, Baris: row.baris, NamaProduk: req.NamaProduk, Pesan: err.Error()})
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			productImport.Gagal++
+			importErrors = append(importErrors, rowErrors...)
+		} else {
+			productImport.Berhasil++
+		}
+		productImport.Processed++
+
+		if (i+1)%productImportBatchSize == 0 || i == len(rows)-1 {
+			if err := s.importRepo.AddErrors(importErrors); err != nil {
+				s.finishImport(&productImport, constants.ProductImportFailed, err.Error())
+				return
+			}
+			importErrors = nil
+			if err := s.importRepo.Update(&productImport); err != nil {
+				log.Printf("[Import] failed to save progress of import %d: %v", productImport.ID, err)
+			}
+		}
+	}
+
+	s.finishImport(&productImport, constants.ProductImportCompleted, "")
+}
+
+func (s *productImportService) finishImport(productImport *model.ProductImport, status, message string) {
+	now := time.Now()
+	productImport.Status = status
+	productImport.Pesan = message
+	productImport.FinishedAt = &now
+	if err := s.importRepo.Update(productImport); err != nil {
+		log.Printf("[Import] failed to finish import %d: %v", productImport.ID, err)
+	}
+}
+
+// parseRow builds the create request for a row, checked against the same
+// rules as POST /product. It returns the row's errors, at most one per column.
+func (s *productImportService) parseRow(productImport model.ProductImport, columns map[string]int, categoryIDs map[string]int, row importRow) (*request.CreateProductRequest, []model.ProductImportError) {
+	value := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(row.values) {
+			return ""
+		}
+		return strings.TrimSpace(row.values[i])
+	}
+
+	req := &request.CreateProductRequest{
+		NamaProduk: value(constants.ProductColumnNama),
+		Deskripsi:  value(constants.ProductColumnDeskripsi),
+		IDToko:     productImport.IDToko,
+	}
+
+	var rowErrors []model.ProductImportError
+	failed := make(map[string]bool)
+	addError := func(column, message string) {
+		if failed[column] {
+			return
+		}
+		failed[column] = true
+		rowErrors = append(rowErrors, model.ProductImportError{
+			IDImport:   productImport.ID,
+			Baris:      row.baris,
+			Kolom:      column,
+			NamaProduk: req.NamaProduk,
+			Pesan:      message,
+		})
+	}
+
+	parsePrice := func(column string) utils.Rupiah {
+		raw := value(column)
+		if raw == "" {
+			return 0
+		}
+		amount, err := utils.ParseRupiah(raw)
+		if err != nil {
+			addError(column, err.Error())
+		}
+		return amount
+	}
+	parseInt := func(column string) int {
+		raw := value(column)
+		if raw == "" {
+			return 0
+		}
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			addError(column, fmt.Sprintf("%s must be a whole number", column))
+		}
+		return number
+	}
+
+	req.HargaReseller = parsePrice(constants.ProductColumnHargaReseller)
+	req.HargaKonsumen = parsePrice(constants.ProductColumnHargaKonsumen)
+	req.MinKuantitasReseller = parseInt(constants.ProductColumnMinKuantitasReseller)
+	req.Stok = parseInt(constants.ProductColumnStok)
+
+	if kategori := value(constants.ProductColumnKategori); kategori != "" {
+		categoryID, ok := categoryIDs[strings.ToLower(kategori)]
+		if !ok {
+			addError(constants.ProductColumnKategori, constants.ErrCategoryNotFound)
+		}
+		req.IDCategory = categoryID
+	}
+
+	if err := s.validator.Struct(req); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			addError("", err.Error())
+		}
+		for _, fieldError := range validationErrors {
+			addError(fieldError.Field(), validationMessage(fieldError))
+		}
+	}
+
+	if len(rowErrors) == 0 {
+		if err := validatePrices(req.HargaReseller, req.HargaKonsumen); err != nil {
+			addError(constants.ProductColumnHargaReseller, err.Error())
+		}
+	}
+
+	return req, rowErrors
+}
+
+func validationMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fieldError.Field())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", fieldError.Field(), fieldError.Param())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", fieldError.Field(), fieldError.Param())
+	}
+	return fmt.Sprintf("%s is invalid", fieldError.Field())
+}
+
+func (s *productImportService) GetImports(userID, shopID int) ([]response.ProductImportResponse, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+
+	productImports, err := s.importRepo.GetByShopID(shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	importResponses := []response.ProductImportResponse{}
+	for _, productImport := range productImports {
+		importResponses = append(importResponses, mapProductImportToResponse(productImport))
+	}
+	return importResponses, nil
+}
+
+func (s *productImportService) GetImport(userID, shopID, importID int) (*response.ProductImportResponse, error) {
+	productImport, err := s.getShopImport(userID, shopID, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	importResponse := mapProductImportToResponse(*productImport)
+	return &importResponse, nil
+}
+
+func (s *productImportService) GetErrorReport(userID, shopID, importID int) ([]byte, error) {
+	productImport, err := s.getShopImport(userID, shopID, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	importErrors, err := s.importRepo.GetErrors(productImport.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{{"baris", "kolom", constants.ProductColumnNama, "pesan"}}
+	for _, importError := range importErrors {
+		rows = append(rows, []string{strconv.Itoa(importError.Baris), importError.Kolom, importError.NamaProduk, importError.Pesan})
+	}
+	return utils.WriteSpreadsheet(constants.SpreadsheetCSV, rows)
+}
+
+func (s *productImportService) getShopImport(userID, shopID, importID int) (*model.ProductImport, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+
+	productImport, err := s.importRepo.GetByID(importID)
+	if err != nil || productImport.IDToko != shopID {
+		return nil, errors.New(constants.ErrProductImportNotFound)
+	}
+	return productImport, nil
+}
+
+func (s *productImportService) ExportProducts(userID, shopID int, format string) ([]byte, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.GetByShopID(shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{constants.ProductColumns}
+	for _, product := range products {
+		rows = append(rows, []string{
+			product.NamaProduk,
+			product.Category.Nama,
+			strconv.FormatInt(product.HargaReseller, 10),
+			strconv.FormatInt(product.HargaKonsumen, 10),
+			strconv.Itoa(product.MinKuantitasReseller),
+			strconv.Itoa(product.Stok),
+			product.Deskripsi,
+		})
+	}
+	return utils.WriteSpreadsheet(format, rows)
+}
+
+func (s *productImportService) FailInterrupted() error {
+	count, err := s.importRepo.FailUnfinished(constants.ErrProductImportInterrupted)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("[Import] marked %d interrupted imports as failed", count)
+	}
+	return nil
+}
+
+func mapProductImportToResponse(productImport model.ProductImport) response.ProductImportResponse {
+	importResponse := response.ProductImportResponse{
+		ID:        productImport.ID,
+		IDToko:    productImport.IDToko,
+		IDUser:    productImport.IDUser,
+		NamaFile:  productImport.NamaFile,
+		Format:    productImport.Format,
+		DryRun:    productImport.DryRun,
+		Status:    productImport.Status,
+		TotalRows: productImport.TotalRows,
+		Processed: productImport.Processed,
+		Berhasil:  productImport.Berhasil,
+		Gagal:     productImport.Gagal,
+		Pesan:     productImport.Pesan,
+		CreatedAt: productImport.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: productImport.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if productImport.FinishedAt != nil {
+		importResponse.FinishedAt = productImport.FinishedAt.Format("2006-01-02 15:04:05")
+	}
+	return importResponse
+}
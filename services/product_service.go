@@ -1,51 +1,167 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/rdsarjito/marketplace-backend/constants"
 	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
 	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
 	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/media"
 	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/search"
+	"github.com/rdsarjito/marketplace-backend/storage"
+	"github.com/rdsarjito/marketplace-backend/utils"
 	"github.com/gosimple/slug"
 )
 
 type ProductService interface {
-	GetListProduct() ([]response.ProductResponse, error)
+	GetListProduct(query *request.ProductListQuery) (*response.ProductListResponse, error)
 	GetDetailProduct(id int) (*response.ProductResponse, error)
 	CreateProduct(userID int, req *request.CreateProductRequest) (*response.ProductResponse, error)
 	UpdateProduct(userID, id int, req *request.UpdateProductRequest) (*response.ProductResponse, error)
 	DeleteProduct(userID, id int) error
-    AddProductPhoto(userID, productID int, url string) (*response.ProductResponse, error)
+	// AddProductPhoto checks and resizes an uploaded image, stores every
+	// version in media storage and appends it to the product's photos.
+	AddProductPhoto(ctx context.Context, userID, productID int, fileName string, file io.Reader) (*response.ProductResponse, error)
+	ReorderPhotos(userID, productID int, req *request.ReorderPhotosRequest) (*response.ProductResponse, error)
+	SetPrimaryPhoto(userID, productID, photoID int) (*response.ProductResponse, error)
+	// DeleteProductPhoto removes a photo and its objects in media storage.
+	DeleteProductPhoto(ctx context.Context, userID, productID, photoID int) (*response.ProductResponse, error)
+	CreateVariant(userID, productID int, req *request.ProductVariantRequest) (*response.ProductResponse, error)
+	UpdateVariant(userID, productID, variantID int, req *request.ProductVariantRequest) (*response.ProductResponse, error)
+	DeleteVariant(userID, productID, variantID int) (*response.ProductResponse, error)
+	// GetHistory lists the product's snapshots, newest first.
+	GetHistory(userID, productID int) ([]response.LogProductResponse, error)
 }
 
 type productService struct {
-	productRepo  repositories.ProductRepository
-	shopRepo     repositories.ShopRepository
-	categoryRepo repositories.CategoryRepository
+	productRepo       repositories.ProductRepository
+	variantRepo       repositories.ProductVariantRepository
+	logProductRepo    repositories.LogProductRepository
+	shopRepo          repositories.ShopRepository
+	categoryRepo      repositories.CategoryRepository
+	shopMemberService ShopMemberService
+	searchIndex       search.SearchIndex
+	mediaStorage      storage.MediaStorage
+
+	shopVerificationService ShopVerificationService
 }
 
-func NewProductService(productRepo repositories.ProductRepository, shopRepo repositories.ShopRepository, categoryRepo repositories.CategoryRepository) ProductService {
+func NewProductService(productRepo repositories.ProductRepository, variantRepo repositories.ProductVariantRepository, logProductRepo repositories.LogProductRepository, shopRepo repositories.ShopRepository, categoryRepo repositories.CategoryRepository, shopMemberService ShopMemberService, shopVerificationService ShopVerificationService, searchIndex search.SearchIndex, mediaStorage storage.MediaStorage) ProductService {
 	return &productService{
-		productRepo:  productRepo,
-		shopRepo:     shopRepo,
-		categoryRepo: categoryRepo,
+		productRepo:       productRepo,
+		variantRepo:       variantRepo,
+		logProductRepo:    logProductRepo,
+		shopRepo:          shopRepo,
+		categoryRepo:      categoryRepo,
+		shopMemberService: shopMemberService,
+		searchIndex:       searchIndex,
+		mediaStorage:      mediaStorage,
+
+		shopVerificationService: shopVerificationService,
 	}
 }
 
-func (s *productService) GetListProduct() ([]response.ProductResponse, error) {
-	products, err := s.productRepo.GetAll()
+func (s *productService) GetListProduct(query *request.ProductListQuery) (*response.ProductListResponse, error) {
+	if query.MinHarga != nil && query.MaxHarga != nil && *query.MinHarga > *query.MaxHarga {
+		return nil, errors.New(constants.ErrInvalidPriceRange)
+	}
+
+	keyword := strings.TrimSpace(query.Q)
+
+	// Keyword searches are ranked by relevance unless another order is asked for
+	sortBy := query.Sort
+	if sortBy == "" && keyword != "" {
+		sortBy = constants.ProductSortRelevance
+	}
+	if sortBy == "" || (sortBy == constants.ProductSortRelevance && keyword == "") {
+		sortBy = constants.ProductSortNewest
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = constants.ProductPageSizeDefault
+	}
+	if limit > constants.ProductPageSizeMax {
+		limit = constants.ProductPageSizeMax
+	}
+
+	var after *repositories.ProductCursor
+	if query.Cursor != "" {
+		cursor, err := decodeProductCursor(query.Cursor, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		after = cursor
+	}
+
+	listResponse := &response.ProductListResponse{
+		Products: []response.ProductResponse{},
+	}
+
+	var ids []int
+	ranks := map[int]int{}
+	highlights := map[int]map[string][]string{}
+	if keyword != "" {
+		hits, err := s.searchIndex.Search(keyword, constants.ProductSearchMaxHits)
+		if err != nil {
+			return nil, err
+		}
+		if len(hits) == 0 {
+			return listResponse, nil
+		}
+
+		ids = make([]int, 0, len(hits))
+		for i, hit := range hits {
+			ids = append(ids, hit.ID)
+			ranks[hit.ID] = i + 1
+			highlights[hit.ID] = hit.Highlights
+		}
+	}
+
+	// Fetch one extra row to know whether another page exists
+	products, total, err := s.productRepo.Search(repositories.ProductQuery{
+		IDs:        ids,
+		CategoryID: query.IDCategory,
+		ShopID:     query.IDToko,
+		MinPrice:   query.MinHarga,
+		MaxPrice:   query.MaxHarga,
+		InStock:    query.InStock,
+		Sort:       sortBy,
+		After:      after,
+		Limit:      limit + 1,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var productResponses []response.ProductResponse
+	listResponse.Total = total
+	if len(products) > limit {
+		products = products[:limit]
+		last := products[limit-1]
+		listResponse.HasMore = true
+		listResponse.NextCursor = encodeProductCursor(last, sortBy, ranks[last.ID])
+	}
+
 	for _, product := range products {
-		productResponses = append(productResponses, s.mapProductToResponse(product))
+		productResponse := s.mapProductToResponse(product)
+		productResponse.Highlights = highlights[product.ID]
+		listResponse.Products = append(listResponse.Products, productResponse)
 	}
 
-	return productResponses, nil
+	return listResponse, nil
 }
 
 func (s *productService) GetDetailProduct(id int) (*response.ProductResponse, error) {
@@ -59,14 +175,13 @@ func (s *productService) GetDetailProduct(id int) (*response.ProductResponse, er
 }
 
 func (s *productService) CreateProduct(userID int, req *request.CreateProductRequest) (*response.ProductResponse, error) {
-	// Check if shop belongs to user
-	shop, err := s.shopRepo.GetByID(req.IDToko)
+	// Check if user may manage the shop's products
+	shop, err := s.shopMemberService.Authorize(userID, req.IDToko, constants.ShopPermProductManage)
 	if err != nil {
-		return nil, errors.New(constants.ErrShopNotFound)
+		return nil, err
 	}
-
-	if shop.IDUser != userID {
-		return nil, errors.New(constants.ErrForbidden)
+	if err := s.shopVerificationService.CheckProductLimit(shop); err != nil {
+		return nil, err
 	}
 
 	// Check if category exists
@@ -75,29 +190,51 @@ func (s *productService) CreateProduct(userID int, req *request.CreateProductReq
 		return nil, errors.New(constants.ErrCategoryNotFound)
 	}
 
+	if err := validatePrices(req.HargaReseller, req.HargaKonsumen); err != nil {
+		return nil, err
+	}
+
+	// Check variants up front to reject a bad request before writing anything
+	if err := s.validateNewVariants(req.Variants); err != nil {
+		return nil, err
+	}
+
 	// Generate slug
 	productSlug := slug.Make(req.NamaProduk)
 
 	product := &model.Product{
-		NamaProduk:    req.NamaProduk,
-		Slug:          productSlug,
-		HargaReseller: req.HargaReseller,
-		HargaKonsumen: req.HargaKonsumen,
-		Stok:          req.Stok,
-		Deskripsi:     req.Deskripsi,
-		IDToko:        req.IDToko,
-		IDCategory:    req.IDCategory,
+		NamaProduk:           req.NamaProduk,
+		Slug:                 productSlug,
+		HargaReseller:        int64(req.HargaReseller),
+		HargaKonsumen:        int64(req.HargaKonsumen),
+		MinKuantitasReseller: minKuantitasReseller(req.MinKuantitasReseller),
+		Stok:                 req.Stok,
+		Deskripsi:            req.Deskripsi,
+		IDToko:               req.IDToko,
+		IDCategory:           req.IDCategory,
 	}
 
-	if err := s.productRepo.Create(product); err != nil {
+	// The product and its variants are saved together so a variant that
+	// fails (e.g. an SKU taken in the meantime) doesn't leave the product behind
+	err = s.productRepo.CreateWithVariants(product, func(variants repositories.ProductVariantRepository) error {
+		for i := range req.Variants {
+			if err := s.saveVariant(variants, product, &model.ProductVariant{IDProduk: product.ID}, &req.Variants[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.indexProduct(*product)
 
 	// Get created product with relations
 	createdProduct, err := s.productRepo.GetByID(product.ID)
 	if err != nil {
 		return nil, err
 	}
+	s.recordSnapshot(createdProduct, userID, true)
 
 	productResponse := s.mapProductToResponse(*createdProduct)
 	return &productResponse, nil
@@ -109,14 +246,9 @@ func (s *productService) UpdateProduct(userID, id int, req *request.UpdateProduc
 		return nil, errors.New(constants.ErrProductNotFound)
 	}
 
-	// Check if shop belongs to user
-	shop, err := s.shopRepo.GetByID(product.IDToko)
-	if err != nil {
-		return nil, errors.New(constants.ErrShopNotFound)
-	}
-
-	if shop.IDUser != userID {
-		return nil, errors.New(constants.ErrForbidden)
+	// Check if user may manage the shop's products
+	if _, err := s.shopMemberService.Authorize(userID, product.IDToko, constants.ShopPermProductManage); err != nil {
+		return nil, err
 	}
 
 	// Check if category exists
@@ -125,14 +257,19 @@ func (s *productService) UpdateProduct(userID, id int, req *request.UpdateProduc
 		return nil, errors.New(constants.ErrCategoryNotFound)
 	}
 
+	if err := validatePrices(req.HargaReseller, req.HargaKonsumen); err != nil {
+		return nil, err
+	}
+
 	// Generate new slug if product name changed
 	if product.NamaProduk != req.NamaProduk {
 		product.Slug = slug.Make(req.NamaProduk)
 	}
 
 	product.NamaProduk = req.NamaProduk
-	product.HargaReseller = req.HargaReseller
-	product.HargaKonsumen = req.HargaKonsumen
+	product.HargaReseller = int64(req.HargaReseller)
+	product.HargaKonsumen = int64(req.HargaKonsumen)
+	product.MinKuantitasReseller = minKuantitasReseller(req.MinKuantitasReseller)
 	product.Stok = req.Stok
 	product.Deskripsi = req.Deskripsi
 	product.IDCategory = req.IDCategory
@@ -140,12 +277,19 @@ func (s *productService) UpdateProduct(userID, id int, req *request.UpdateProduc
 	if err := s.productRepo.Update(product); err != nil {
 		return nil, err
 	}
+	s.indexProduct(*product)
+
+	// Stock and prices of products with variants follow the variants
+	if err := s.variantRepo.SyncProduct(product.ID); err != nil {
+		return nil, err
+	}
 
 	// Get updated product with relations
 	updatedProduct, err := s.productRepo.GetByID(product.ID)
 	if err != nil {
 		return nil, err
 	}
+	s.recordSnapshot(updatedProduct, userID, true)
 
 	productResponse := s.mapProductToResponse(*updatedProduct)
 	return &productResponse, nil
@@ -157,53 +301,525 @@ func (s *productService) DeleteProduct(userID, id int) error {
 		return errors.New(constants.ErrProductNotFound)
 	}
 
-	// Check if shop belongs to user
-	shop, err := s.shopRepo.GetByID(product.IDToko)
+	// Check if user may manage the shop's products
+	if _, err := s.shopMemberService.Authorize(userID, product.IDToko, constants.ShopPermProductManage); err != nil {
+		return err
+	}
+
+	if err := s.productRepo.Delete(id); err != nil {
+		return err
+	}
+
+	if err := s.searchIndex.Delete(id); err != nil {
+		log.Printf("[Search] failed to remove product %d from index: %v", id, err)
+	}
+	return nil
+}
+
+func (s *productService) CreateVariant(userID, productID int, req *request.ProductVariantRequest) (*response.ProductResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, errors.New(constants.ErrProductNotFound)
+	}
+
+	if _, err := s.shopMemberService.Authorize(userID, product.IDToko, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveVariant(s.variantRepo, product, &model.ProductVariant{IDProduk: product.ID}, req); err != nil {
+		return nil, err
+	}
+
+	return s.variantChangedResponse(userID, product.ID)
+}
+
+func (s *productService) UpdateVariant(userID, productID, variantID int, req *request.ProductVariantRequest) (*response.ProductResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, errors.New(constants.ErrProductNotFound)
+	}
+
+	if _, err := s.shopMemberService.Authorize(userID, product.IDToko, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.variantRepo.GetByID(product.ID, variantID)
+	if err != nil {
+		return nil, errors.New(constants.ErrVariantNotFound)
+	}
+
+	if err := s.saveVariant(s.variantRepo, product, variant, req); err != nil {
+		return nil, err
+	}
+
+	return s.variantChangedResponse(userID, product.ID)
+}
+
+func (s *productService) DeleteVariant(userID, productID, variantID int) (*response.ProductResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, errors.New(constants.ErrProductNotFound)
+	}
+
+	if _, err := s.shopMemberService.Authorize(userID, product.IDToko, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.variantRepo.GetByID(product.ID, variantID)
+	if err != nil {
+		return nil, errors.New(constants.ErrVariantNotFound)
+	}
+
+	if err := s.variantRepo.Delete(variant.ID); err != nil {
+		return nil, err
+	}
+
+	remaining, err := s.variantRepo.GetByProductID(product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without variants the product goes back to its own stock and prices,
+	// which keep the values last synced from the variants
+	if len(remaining) == 0 {
+		if err := s.variantRepo.DeleteOptions(product.ID); err != nil {
+			return nil, err
+		}
+	} else if err := s.variantRepo.SyncProduct(product.ID); err != nil {
+		return nil, err
+	}
+
+	return s.variantChangedResponse(userID, product.ID)
+}
+
+// validateNewVariants checks the variants of a product being created against
+// each other and against SKUs already in use.
+func (s *productService) validateNewVariants(reqs []request.ProductVariantRequest) error {
+	skus := map[string]bool{}
+	var combinations []map[string]string
+	for _, req := range reqs {
+		if req.IDFoto != nil {
+			// A new product has no photos to point at yet
+			return errors.New(constants.ErrPhotoNotFound)
+		}
+		if err := validatePrices(req.HargaReseller, req.HargaKonsumen); err != nil {
+			return err
+		}
+
+		sku := strings.TrimSpace(req.SKU)
+		if skus[sku] {
+			return errors.New(constants.ErrSKUAlreadyUsed)
+		}
+		skus[sku] = true
+		if exists, err := s.variantRepo.SKUExists(sku, 0); err != nil {
+			return err
+		} else if exists {
+			return errors.New(constants.ErrSKUAlreadyUsed)
+		}
+
+		attributes := normalizeAttributes(req.Atribut)
+		for _, other := range combinations {
+			if !sameOptionNames(attributes, other) {
+				return errors.New(constants.ErrVariantAttributesMismatch)
+			}
+			if sameAttributes(attributes, other) {
+				return errors.New(constants.ErrDuplicateVariant)
+			}
+		}
+		combinations = append(combinations, attributes)
+	}
+	return nil
+}
+
+// saveVariant validates req against the product's other variants, creates any
+// new option types/values and saves the variant through variants, then syncs
+// the product's stock and prices.
+func (s *productService) saveVariant(variants repositories.ProductVariantRepository, product *model.Product, variant *model.ProductVariant, req *request.ProductVariantRequest) error {
+	if err := validatePrices(req.HargaReseller, req.HargaKonsumen); err != nil {
+		return err
+	}
+
+	sku := strings.TrimSpace(req.SKU)
+	if exists, err := variants.SKUExists(sku, variant.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New(constants.ErrSKUAlreadyUsed)
+	}
+
+	if req.IDFoto != nil && !hasPhoto(product, *req.IDFoto) {
+		return errors.New(constants.ErrPhotoNotFound)
+	}
+
+	attributes := normalizeAttributes(req.Atribut)
+
+	siblings, err := variants.GetByProductID(product.ID)
+	if err != nil {
+		return err
+	}
+	others := 0
+	for _, other := range siblings {
+		if other.ID == variant.ID {
+			continue
+		}
+		others++
+		otherAttributes := variantAttributes(other)
+		if !sameOptionNames(attributes, otherAttributes) {
+			return errors.New(constants.ErrVariantAttributesMismatch)
+		}
+		if sameAttributes(attributes, otherAttributes) {
+			return errors.New(constants.ErrDuplicateVariant)
+		}
+	}
+
+	// The only variant may switch to different option types
+	if others == 0 {
+		if err := variants.DeleteOptions(product.ID); err != nil {
+			return err
+		}
+	}
+
+	options, err := variants.GetOptions(product.ID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]model.ProductOption, len(options))
+	for _, option := range options {
+		existing[option.Nama] = option
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var values []model.ProductOptionValue
+	for i, name := range names {
+		option, ok := existing[name]
+		if !ok {
+			option = model.ProductOption{IDProduk: product.ID, Nama: name, Posisi: i}
+			if err := variants.FindOrCreateOption(&option); err != nil {
+				return err
+			}
+		}
+
+		value := model.ProductOptionValue{IDOption: option.ID, Nilai: attributes[name], Posisi: len(option.Values)}
+		if err := variants.FindOrCreateOptionValue(&value); err != nil {
+			return err
+		}
+		values = append(values, value)
+	}
+
+	variant.SKU = sku
+	variant.HargaReseller = int64(req.HargaReseller)
+	variant.HargaKonsumen = int64(req.HargaKonsumen)
+	variant.Stok = req.Stok
+	variant.IDFoto = req.IDFoto
+	variant.OptionValues = values
+
+	if variant.ID == 0 {
+		err = variants.Create(variant)
+	} else {
+		err = variants.Update(variant)
+	}
 	if err != nil {
-		return errors.New(constants.ErrShopNotFound)
+		return err
 	}
 
-	if shop.IDUser != userID {
-		return errors.New(constants.ErrForbidden)
+	return variants.SyncProduct(product.ID)
+}
+
+// variantChangedResponse reloads a product after one of its variants changed,
+// snapshotting it if the variant sync changed its prices.
+func (s *productService) variantChangedResponse(userID, id int) (*response.ProductResponse, error) {
+	product, err := s.productRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New(constants.ErrProductNotFound)
 	}
+	s.recordSnapshot(product, userID, false)
+
+	productResponse := s.mapProductToResponse(*product)
+	return &productResponse, nil
+}
 
-	return s.productRepo.Delete(id)
+// indexProduct updates the search index after a product is saved. Failures
+// are only logged; the reindex command brings the index back in sync.
+func (s *productService) indexProduct(product model.Product) {
+	if err := s.searchIndex.Index(search.DocumentFromProduct(product)); err != nil {
+		log.Printf("[Search] failed to index product %d: %v", product.ID, err)
+	}
 }
 
-func (s *productService) AddProductPhoto(userID, productID int, url string) (*response.ProductResponse, error) {
-    product, err := s.productRepo.GetByID(productID)
-    if err != nil {
-        return nil, errors.New(constants.ErrProductNotFound)
-    }
-    shop, err := s.shopRepo.GetByID(product.IDToko)
-    if err != nil {
-        return nil, errors.New(constants.ErrShopNotFound)
-    }
-    if shop.IDUser != userID {
-        return nil, errors.New(constants.ErrForbidden)
-    }
-    photo := &model.PhotoProduct{ IDProduk: productID, URL: url }
-    if err := s.productRepo.AddPhoto(photo); err != nil {
-        return nil, err
-    }
-    updated, err := s.productRepo.GetByID(productID)
-    if err != nil {
-        return nil, err
-    }
-    resp := s.mapProductToResponse(*updated)
-    return &resp, nil
+// recordSnapshot writes the product to its change history, always or only
+// when it differs from the latest snapshot. Failures are logged; checkout
+// snapshots the product again if needed.
+func (s *productService) recordSnapshot(product *model.Product, userID int, always bool) {
+	var err error
+	if always {
+		_, err = s.logProductRepo.Create(product, &userID)
+	} else {
+		_, err = s.logProductRepo.Current(product, &userID)
+	}
+	if err != nil {
+		log.Printf("[History] failed to snapshot product %d: %v", product.ID, err)
+	}
+}
+
+func (s *productService) GetHistory(userID, productID int) ([]response.LogProductResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, errors.New(constants.ErrProductNotFound)
+	}
+
+	// Check if user may manage the shop's products
+	if _, err := s.shopMemberService.Authorize(userID, product.IDToko, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+
+	logs, err := s.logProductRepo.GetByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	logResponses := []response.LogProductResponse{}
+	for _, logProduct := range logs {
+		logResponse := response.LogProductResponse{
+			ID:                   logProduct.ID,
+			IDProduk:             logProduct.IDProduk,
+			NamaProduk:           logProduct.NamaProduk,
+			Slug:                 logProduct.Slug,
+			HargaReseller:        logProduct.HargaReseller,
+			HargaKonsumen:        logProduct.HargaKonsumen,
+			MinKuantitasReseller: logProduct.MinKuantitasReseller,
+			Stok:                 logProduct.Stock,
+			Deskripsi:            logProduct.Deskripsi,
+			IDToko:               logProduct.IDToko,
+			IDCategory:           logProduct.IDCategory,
+			IDUser:               logProduct.IDUser,
+			CreatedAt:            logProduct.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if logProduct.User != nil {
+			logResponse.NamaUser = logProduct.User.Nama
+		}
+		logResponses = append(logResponses, logResponse)
+	}
+
+	return logResponses, nil
+}
+
+func (s *productService) AddProductPhoto(ctx context.Context, userID, productID int, fileName string, file io.Reader) (*response.ProductResponse, error) {
+	product, err := s.getManagedProduct(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.productRepo.CountPhotos(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= constants.MaxProductPhotos {
+		return nil, errors.New(constants.ErrPhotoLimitReached)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, constants.MaxPhotoFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > constants.MaxPhotoFileSize {
+		return nil, errors.New(constants.ErrPhotoTooLarge)
+	}
+
+	images, err := media.ProcessImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep every version of the photo under one prefix:
+	// products/<id>/<time>_<name>/original.jpg, .../thumbnail.jpg, ...
+	name := sanitizeFilename(strings.TrimSuffix(fileName, path.Ext(fileName)))
+	prefix := fmt.Sprintf("products/%d/%d_%s", product.ID, time.Now().UnixNano(), name)
+
+	// The first photo becomes the primary one
+	photo := &model.PhotoProduct{
+		IDProduk:  product.ID,
+		Posisi:    int(count),
+		IsPrimary: count == 0,
+	}
+	var uploaded []string
+	for _, img := range images {
+		objectName := prefix + "/" + img.Name + img.Extension
+		url, err := s.mediaStorage.Upload(ctx, objectName, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType)
+		if err != nil {
+			log.Printf("[Media] failed to upload %s: %v", objectName, err)
+			s.deleteMediaObjects(ctx, uploaded)
+			return nil, errors.New(constants.ErrPhotoUploadFailed)
+		}
+		uploaded = append(uploaded, objectName)
+
+		if img.Name == constants.PhotoOriginal {
+			photo.URL = url
+			photo.ObjectName = objectName
+			continue
+		}
+		photo.Renditions = append(photo.Renditions, model.PhotoRendition{
+			Nama:       img.Name,
+			URL:        url,
+			ObjectName: objectName,
+			Lebar:      img.Width,
+			Tinggi:     img.Height,
+		})
+	}
+
+	if err := s.productRepo.AddPhoto(photo); err != nil {
+		s.deleteMediaObjects(ctx, uploaded)
+		return nil, err
+	}
+
+	return s.getProductResponse(product.ID)
+}
+
+func (s *productService) ReorderPhotos(userID, productID int, req *request.ReorderPhotosRequest) (*response.ProductResponse, error) {
+	product, err := s.getManagedProduct(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every photo must be listed exactly once
+	if len(req.PhotoIDs) != len(product.PhotosProduct) {
+		return nil, errors.New(constants.ErrInvalidPhotoOrder)
+	}
+	seen := make(map[int]bool, len(req.PhotoIDs))
+	for _, photoID := range req.PhotoIDs {
+		if seen[photoID] || !hasPhoto(product, photoID) {
+			return nil, errors.New(constants.ErrInvalidPhotoOrder)
+		}
+		seen[photoID] = true
+	}
+
+	if err := s.productRepo.ReorderPhotos(product.ID, req.PhotoIDs); err != nil {
+		return nil, err
+	}
+
+	return s.getProductResponse(product.ID)
+}
+
+func (s *productService) SetPrimaryPhoto(userID, productID, photoID int) (*response.ProductResponse, error) {
+	product, err := s.getManagedProduct(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasPhoto(product, photoID) {
+		return nil, errors.New(constants.ErrPhotoNotFound)
+	}
+
+	if err := s.productRepo.SetPrimaryPhoto(product.ID, photoID); err != nil {
+		return nil, err
+	}
+
+	return s.getProductResponse(product.ID)
+}
+
+func (s *productService) DeleteProductPhoto(ctx context.Context, userID, productID, photoID int) (*response.ProductResponse, error) {
+	product, err := s.getManagedProduct(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	photo, err := s.productRepo.GetPhoto(product.ID, photoID)
+	if err != nil {
+		return nil, errors.New(constants.ErrPhotoNotFound)
+	}
+
+	if err := s.productRepo.DeletePhoto(photo); err != nil {
+		return nil, err
+	}
+	var objectNames []string
+	if objectName := photoObjectName(photo); objectName != "" {
+		objectNames = append(objectNames, objectName)
+	}
+	for _, rendition := range photo.Renditions {
+		objectNames = append(objectNames, rendition.ObjectName)
+	}
+	s.deleteMediaObjects(ctx, objectNames)
+
+	return s.getProductResponse(product.ID)
+}
+
+// getManagedProduct loads a product the user may manage.
+func (s *productService) getManagedProduct(userID, productID int) (*model.Product, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, errors.New(constants.ErrProductNotFound)
+	}
+
+	if _, err := s.shopMemberService.Authorize(userID, product.IDToko, constants.ShopPermProductManage); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *productService) getProductResponse(id int) (*response.ProductResponse, error) {
+	product, err := s.productRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New(constants.ErrProductNotFound)
+	}
+
+	productResponse := s.mapProductToResponse(*product)
+	return &productResponse, nil
+}
+
+// deleteMediaObjects removes objects from media storage. The database no
+// longer points at them, so a failure only leaves unused objects behind.
+func (s *productService) deleteMediaObjects(ctx context.Context, objectNames []string) {
+	for _, objectName := range objectNames {
+		if err := s.mediaStorage.Delete(ctx, objectName); err != nil {
+			log.Printf("[Media] failed to delete %s: %v", objectName, err)
+		}
+	}
+}
+
+// photoObjectName returns the storage key of a photo. Older photos only
+// have their URL, which ends with the key under products/.
+func photoObjectName(photo *model.PhotoProduct) string {
+	if photo.ObjectName != "" {
+		return photo.ObjectName
+	}
+	if i := strings.Index(photo.URL, "products/"); i >= 0 {
+		return photo.URL[i:]
+	}
+	return ""
+}
+
+// mapPhotoRenditions maps rendition names to their URLs.
+func mapPhotoRenditions(renditions []model.PhotoRendition) map[string]string {
+	if len(renditions) == 0 {
+		return nil
+	}
+	urls := make(map[string]string, len(renditions))
+	for _, rendition := range renditions {
+		urls[rendition.Nama] = rendition.URL
+	}
+	return urls
+}
+
+func sanitizeFilename(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, " ", "_")
+
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') ||
+			(r >= '0' && r <= '9') ||
+			r == '.' || r == '_' || r == '-' {
+			return r
+		}
+		return '-'
+	}, name)
 }
 
 func (s *productService) mapProductToResponse(product model.Product) response.ProductResponse {
 	// Map shop
-	shopResponse := response.ShopResponse{
-		ID:        product.Toko.ID,
-		NamaToko:  product.Toko.NamaToko,
-		URLToko:   product.Toko.URLToko,
-		CreatedAt: product.Toko.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: product.Toko.UpdatedAt.Format("2006-01-02 15:04:05"),
-		IDUser:    product.Toko.IDUser,
-	}
+	shopResponse := mapShopToResponse(&product.Toko)
 
 	// Map category
 	categoryResponse := response.CategoryResponse{
@@ -217,20 +833,39 @@ func (s *productService) mapProductToResponse(product model.Product) response.Pr
 	var photoResponses []response.PhotoProductResponse
 	for _, photo := range product.PhotosProduct {
 		photoResponses = append(photoResponses, response.PhotoProductResponse{
-			ID:        photo.ID,
-			IDProduk:  photo.IDProduk,
-			URL:       photo.URL,
-			CreatedAt: photo.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: photo.UpdatedAt.Format("2006-01-02 15:04:05"),
+			ID:         photo.ID,
+			IDProduk:   photo.IDProduk,
+			URL:        photo.URL,
+			Posisi:     photo.Posisi,
+			IsPrimary:  photo.IsPrimary,
+			Renditions: mapPhotoRenditions(photo.Renditions),
+			CreatedAt:  photo.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:  photo.UpdatedAt.Format("2006-01-02 15:04:05"),
 		})
 	}
 
+	// Map option types and variants
+	var optionResponses []response.ProductOptionResponse
+	for _, option := range product.Options {
+		optionResponse := response.ProductOptionResponse{ID: option.ID, Nama: option.Nama, Nilai: []string{}}
+		for _, value := range option.Values {
+			optionResponse.Nilai = append(optionResponse.Nilai, value.Nilai)
+		}
+		optionResponses = append(optionResponses, optionResponse)
+	}
+
+	var variantResponses []response.ProductVariantResponse
+	for _, variant := range product.Variants {
+		variantResponses = append(variantResponses, mapVariantToResponse(variant))
+	}
+
 	return response.ProductResponse{
 		ID:             product.ID,
 		NamaProduk:     product.NamaProduk,
 		Slug:           product.Slug,
 		HargaReseller:  product.HargaReseller,
 		HargaKonsumen:  product.HargaKonsumen,
+		MinKuantitasReseller: product.MinKuantitasReseller,
 		Stok:           product.Stok,
 		Deskripsi:      product.Deskripsi,
 		CreatedAt:      product.CreatedAt.Format("2006-01-02 15:04:05"),
@@ -240,5 +875,143 @@ func (s *productService) mapProductToResponse(product model.Product) response.Pr
 		Toko:           shopResponse,
 		Category:       categoryResponse,
 		PhotosProduct:  photoResponses,
+		Options:        optionResponses,
+		Variants:       variantResponses,
+	}
+}
+
+func mapVariantToResponse(variant model.ProductVariant) response.ProductVariantResponse {
+	variantResponse := response.ProductVariantResponse{
+		ID:            variant.ID,
+		IDProduk:      variant.IDProduk,
+		SKU:           variant.SKU,
+		Atribut:       variantAttributes(variant),
+		HargaReseller: variant.HargaReseller,
+		HargaKonsumen: variant.HargaKonsumen,
+		Stok:          variant.Stok,
+		IDFoto:        variant.IDFoto,
+		CreatedAt:     variant.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     variant.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if variant.Foto != nil {
+		variantResponse.FotoURL = variant.Foto.URL
+	}
+	return variantResponse
+}
+
+// variantAttributes maps option type names to the variant's values. The
+// variant's OptionValues must be loaded with their Option.
+func variantAttributes(variant model.ProductVariant) map[string]string {
+	attributes := make(map[string]string, len(variant.OptionValues))
+	for _, value := range variant.OptionValues {
+		attributes[value.Option.Nama] = value.Nilai
 	}
+	return attributes
+}
+
+func normalizeAttributes(attributes map[string]string) map[string]string {
+	normalized := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		normalized[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return normalized
+}
+
+func sameOptionNames(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sameAttributes(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, ok := b[name]; !ok || !strings.EqualFold(other, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePrices checks that resellers are not charged more than consumers.
+func validatePrices(hargaReseller, hargaKonsumen utils.Rupiah) error {
+	if hargaReseller > hargaKonsumen {
+		return errors.New(constants.ErrResellerPriceTooHigh)
+	}
+	return nil
+}
+
+func minKuantitasReseller(kuantitas int) int {
+	if kuantitas <= 0 {
+		return constants.DefaultMinKuantitasReseller
+	}
+	return kuantitas
+}
+
+func hasPhoto(product *model.Product, photoID int) bool {
+	for _, photo := range product.PhotosProduct {
+		if photo.ID == photoID {
+			return true
+		}
+	}
+	return false
+}
+
+// productCursorToken is the opaque cursor handed to clients. It records the
+// sort order so a cursor cannot be replayed against a different one.
+type productCursorToken struct {
+	Sort      string    `json:"s"`
+	ID        int       `json:"id"`
+	Rank      int       `json:"r,omitempty"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	Price     int64     `json:"p,omitempty"`
+	Terjual   int64     `json:"t,omitempty"`
+}
+
+func encodeProductCursor(product model.Product, sortBy string, rank int) string {
+	token := productCursorToken{Sort: sortBy, ID: product.ID}
+	switch sortBy {
+	case constants.ProductSortRelevance:
+		token.Rank = rank
+	case constants.ProductSortPriceAsc, constants.ProductSortPriceDesc:
+		token.Price = product.HargaKonsumen
+	case constants.ProductSortBestSelling:
+		token.Terjual = product.Terjual
+	default:
+		token.CreatedAt = product.CreatedAt
+	}
+
+	payload, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func decodeProductCursor(cursor, sortBy string) (*repositories.ProductCursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New(constants.ErrInvalidCursor)
+	}
+
+	var token productCursorToken
+	if err := json.Unmarshal(payload, &token); err != nil || token.Sort != sortBy || token.ID <= 0 {
+		return nil, errors.New(constants.ErrInvalidCursor)
+	}
+	if sortBy == constants.ProductSortRelevance && token.Rank <= 0 {
+		return nil, errors.New(constants.ErrInvalidCursor)
+	}
+
+	return &repositories.ProductCursor{
+		ID:        token.ID,
+		Rank:      token.Rank,
+		CreatedAt: token.CreatedAt,
+		Price:     token.Price,
+		Terjual:   token.Terjual,
+	}, nil
 }
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+)
+
+type ResellerService interface {
+	Apply(userID int, req *request.ApplyResellerRequest) (*response.ResellerApplicationResponse, error)
+	GetMyApplication(userID int) (*response.ResellerApplicationResponse, error)
+	GetApplications(query *request.ResellerApplicationQuery) ([]response.ResellerApplicationResponse, error)
+	// Approve grants the applicant the reseller role.
+	Approve(actorID, id int, req *request.ReviewResellerRequest) (*response.ResellerApplicationResponse, error)
+	Reject(actorID, id int, req *request.ReviewResellerRequest) (*response.ResellerApplicationResponse, error)
+	// Revoke takes the reseller role away from an approved reseller.
+	Revoke(actorID, id int, req *request.ReviewResellerRequest) (*response.ResellerApplicationResponse, error)
+}
+
+type resellerService struct {
+	resellerRepo repositories.ResellerRepository
+	roleRepo     repositories.RoleRepository
+	emailService EmailService
+}
+
+func NewResellerService(resellerRepo repositories.ResellerRepository, roleRepo repositories.RoleRepository, emailService EmailService) ResellerService {
+	return &resellerService{
+		resellerRepo: resellerRepo,
+		roleRepo:     roleRepo,
+		emailService: emailService,
+	}
+}
+
+func (s *resellerService) Apply(userID int, req *request.ApplyResellerRequest) (*response.ResellerApplicationResponse, error) {
+	open, err := s.resellerRepo.HasOpenApplication(userID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, errors.New(constants.ErrResellerApplicationExists)
+	}
+
+	application := &model.ResellerApplication{
+		IDUser:    userID,
+		NamaUsaha: strings.TrimSpace(req.NamaUsaha),
+		Alasan:    strings.TrimSpace(req.Alasan),
+		Status:    constants.ResellerPending,
+	}
+	if err := s.resellerRepo.Create(application); err != nil {
+		return nil, err
+	}
+
+	return s.GetMyApplication(userID)
+}
+
+func (s *resellerService) GetMyApplication(userID int) (*response.ResellerApplicationResponse, error) {
+	application, err := s.resellerRepo.GetLatestByUserID(userID)
+	if err != nil {
+		return nil, errors.New(constants.ErrResellerApplicationNotFound)
+	}
+
+	applicationResponse := mapResellerApplicationToResponse(*application)
+	return &applicationResponse, nil
+}
+
+func (s *resellerService) GetApplications(query *request.ResellerApplicationQuery) ([]response.ResellerApplicationResponse, error) {
+	applications, err := s.resellerRepo.GetAll(query.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	applicationResponses := []response.ResellerApplicationResponse{}
+	for _, application := range applications {
+		applicationResponses = append(applicationResponses, mapResellerApplicationToResponse(application))
+	}
+
+	return applicationResponses, nil
+}
+
+func (s *resellerService) Approve(actorID, id int, req *request.ReviewResellerRequest) (*response.ResellerApplicationResponse, error) {
+	return s.review(actorID, id, constants.ResellerPending, constants.ResellerApproved, req)
+}
+
+func (s *resellerService) Reject(actorID, id int, req *request.ReviewResellerRequest) (*response.ResellerApplicationResponse, error) {
+	return s.review(actorID, id, constants.ResellerPending, constants.ResellerRejected, req)
+}
+
+func (s *resellerService) Revoke(actorID, id int, req *request.ReviewResellerRequest) (*response.ResellerApplicationResponse, error) {
+	return s.review(actorID, id, constants.ResellerApproved, constants.ResellerRevoked, req)
+}
+
+// review moves an application from one status to another together with the
+// matching change to the applicant's reseller role, and emails the applicant
+// about the decision. Approving grants the role and revoking removes it.
+func (s *resellerService) review(actorID, id int, from, to string, req *request.ReviewResellerRequest) (*response.ResellerApplicationResponse, error) {
+	application, err := s.resellerRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New(constants.ErrResellerApplicationNotFound)
+	}
+	notInStatus := errors.New(constants.ErrResellerApplicationNotPending)
+	if from == constants.ResellerApproved {
+		notInStatus = errors.New(constants.ErrResellerApplicationNotApproved)
+	}
+	if application.Status != from {
+		return nil, notInStatus
+	}
+
+	roleID := 0
+	if to == constants.ResellerApproved || to == constants.ResellerRevoked {
+		role, err := s.roleRepo.GetByName(constants.RoleReseller)
+		if err != nil {
+			return nil, err
+		}
+		roleID = role.ID
+	}
+
+	now := time.Now()
+	application.Status = to
+	application.Catatan = strings.TrimSpace(req.Catatan)
+	application.ReviewedBy = &actorID
+	application.ReviewedAt = &now
+	// Another reviewer may have changed the status since it was read
+	reviewed, err := s.resellerRepo.Review(application, from, roleID, to == constants.ResellerApproved)
+	if err != nil {
+		return nil, err
+	}
+	if !reviewed {
+		return nil, notInStatus
+	}
+
+	if err := s.emailService.SendResellerStatusEmail(application.User.Email, application.NamaUsaha, to, application.Catatan); err != nil {
+		log.Printf("[Reseller] Failed to email user %d about application %d: %v", application.IDUser, application.ID, err)
+	}
+
+	applicationResponse := mapResellerApplicationToResponse(*application)
+	return &applicationResponse, nil
+}
+
+func mapResellerApplicationToResponse(application model.ResellerApplication) response.ResellerApplicationResponse {
+	applicationResponse := response.ResellerApplicationResponse{
+		ID:         application.ID,
+		IDUser:     application.IDUser,
+		Nama:       application.User.Nama,
+		Email:      application.User.Email,
+		NamaUsaha:  application.NamaUsaha,
+		Alasan:     application.Alasan,
+		Status:     application.Status,
+		Catatan:    application.Catatan,
+		ReviewedBy: application.ReviewedBy,
+		CreatedAt:  application.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  application.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if application.ReviewedAt != nil {
+		applicationResponse.ReviewedAt = application.ReviewedAt.Format("2006-01-02 15:04:05")
+	}
+	return applicationResponse
+}
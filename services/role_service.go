@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+)
+
+// defaultPermissions is the permission catalog seeded on startup.
+var defaultPermissions = []model.Permission{
+	{Name: constants.PermCategoryManage, Description: "Create, update and delete categories"},
+	{Name: constants.PermRoleManage, Description: "Assign roles to users"},
+	{Name: constants.PermPayoutManage, Description: "Review and process seller payouts"},
+	{Name: constants.PermResellerManage, Description: "Review reseller applications"},
+	{Name: constants.PermResellerPrice, Description: "Buy at reseller prices"},
+	{Name: constants.PermMediaPrivate, Description: "View private files of any user"},
+	{Name: constants.PermShopVerify, Description: "Review shop verification requests"},
+}
+
+// defaultRoles are the built-in roles. Seeding only ever adds permissions, so
+// grants made by an administrator are preserved across restarts.
+var defaultRoles = []struct {
+	role        model.Role
+	permissions []string
+}{
+	{model.Role{Name: constants.RoleAdmin, Description: "Platform administrator", RequiresTwoFactor: true}, []string{
+		constants.PermCategoryManage, constants.PermRoleManage, constants.PermPayoutManage, constants.PermResellerManage,
+		constants.PermMediaPrivate, constants.PermShopVerify,
+	}},
+	{model.Role{Name: constants.RoleSupport, Description: "Customer support", RequiresTwoFactor: true}, []string{
+		constants.PermResellerManage, constants.PermMediaPrivate, constants.PermShopVerify,
+	}},
+	{model.Role{Name: constants.RoleFinance, Description: "Finance and payouts", RequiresTwoFactor: true}, []string{
+		constants.PermPayoutManage,
+	}},
+	{model.Role{Name: constants.RoleSeller, Description: "Shop owner"}, []string{}},
+	{model.Role{Name: constants.RoleReseller, Description: "Approved reseller"}, []string{
+		constants.PermResellerPrice,
+	}},
+}
+
+type RoleService interface {
+	SeedDefaults() error
+	// ResolveAccess returns the effective roles and permissions of a user.
+	// Roles that require 2FA only count when twoFactor is true.
+	ResolveAccess(userID int, twoFactor bool) (*response.UserAccessResponse, error)
+	GetListRole() ([]response.RoleResponse, error)
+	GetUserRoles(userID int) (*response.UserAccessResponse, error)
+	SetUserRoles(actorID, userID int, req *request.SetUserRolesRequest) (*response.UserAccessResponse, error)
+	// HasPermission reports whether the user holds the permission through a
+	// role that does not require 2FA.
+	HasPermission(userID int, permission string) (bool, error)
+}
+
+type roleService struct {
+	roleRepo repositories.RoleRepository
+	userRepo repositories.UserRepository
+}
+
+func NewRoleService(roleRepo repositories.RoleRepository, userRepo repositories.UserRepository) RoleService {
+	return &roleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *roleService) SeedDefaults() error {
+	permissions := make(map[string]model.Permission, len(defaultPermissions))
+	for _, permission := range defaultPermissions {
+		if err := s.roleRepo.SavePermission(&permission); err != nil {
+			return err
+		}
+		permissions[permission.Name] = permission
+	}
+
+	for _, defaultRole := range defaultRoles {
+		role := defaultRole.role
+		if err := s.roleRepo.SaveRole(&role); err != nil {
+			return err
+		}
+
+		grants := make([]model.Permission, 0, len(defaultRole.permissions))
+		for _, name := range defaultRole.permissions {
+			grants = append(grants, permissions[name])
+		}
+		if err := s.roleRepo.AddPermissions(&role, grants); err != nil {
+			return err
+		}
+
+		// Accounts created before roles existed keep their admin access
+		if role.Name == constants.RoleAdmin {
+			if err := s.roleRepo.AssignRoleToAdmins(role.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *roleService) ResolveAccess(userID int, twoFactor bool) (*response.UserAccessResponse, error) {
+	roles, err := s.roleRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(roles, func(role model.Role) bool { return role.Name == constants.DefaultRole }) {
+		if defaultRole, err := s.roleRepo.GetByName(constants.DefaultRole); err == nil {
+			roles = append(roles, *defaultRole)
+		}
+	}
+
+	access := &response.UserAccessResponse{
+		Roles:       []string{},
+		Permissions: []string{},
+	}
+	for _, role := range roles {
+		if role.RequiresTwoFactor && !twoFactor {
+			access.TwoFactorRequired = true
+			continue
+		}
+
+		access.Roles = append(access.Roles, role.Name)
+		for _, permission := range role.Permissions {
+			if !slices.Contains(access.Permissions, permission.Name) {
+				access.Permissions = append(access.Permissions, permission.Name)
+			}
+		}
+	}
+	sort.Strings(access.Permissions)
+
+	return access, nil
+}
+
+func (s *roleService) GetListRole() ([]response.RoleResponse, error) {
+	roles, err := s.roleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var roleResponses []response.RoleResponse
+	for _, role := range roles {
+		permissions := make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions = append(permissions, permission.Name)
+		}
+		sort.Strings(permissions)
+
+		roleResponses = append(roleResponses, response.RoleResponse{
+			ID:                role.ID,
+			Name:              role.Name,
+			Description:       role.Description,
+			RequiresTwoFactor: role.RequiresTwoFactor,
+			Permissions:       permissions,
+		})
+	}
+
+	return roleResponses, nil
+}
+
+func (s *roleService) GetUserRoles(userID int) (*response.UserAccessResponse, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, errors.New(constants.ErrUserNotFound)
+	}
+
+	return s.ResolveAccess(userID, true)
+}
+
+func (s *roleService) SetUserRoles(actorID, userID int, req *request.SetUserRolesRequest) (*response.UserAccessResponse, error) {
+	// Prevents an administrator from revoking their own access by accident
+	if actorID == userID {
+		return nil, errors.New(constants.ErrCannotChangeOwnRoles)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New(constants.ErrUserNotFound)
+	}
+
+	roles, err := s.roleRepo.GetByNames(req.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]int, 0, len(roles))
+	isAdmin := false
+	for _, name := range req.Roles {
+		index := slices.IndexFunc(roles, func(role model.Role) bool { return role.Name == name })
+		if index < 0 {
+			return nil, errors.New(constants.ErrRoleNotFound)
+		}
+		if !slices.Contains(roleIDs, roles[index].ID) {
+			roleIDs = append(roleIDs, roles[index].ID)
+		}
+		if name == constants.RoleAdmin {
+			isAdmin = true
+		}
+	}
+
+	if err := s.roleRepo.SetUserRoles(userID, roleIDs); err != nil {
+		return nil, err
+	}
+
+	// Keep the legacy isAdmin flag in step with the admin role
+	if user.IsAdmin != isAdmin {
+		user.IsAdmin = isAdmin
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.ResolveAccess(userID, true)
+}
+
+func (s *roleService) HasPermission(userID int, permission string) (bool, error) {
+	access, err := s.ResolveAccess(userID, false)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(access.Permissions, permission), nil
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+)
+
+// shopRolePermissions lists what each shop role may do within its shop.
+var shopRolePermissions = map[string][]string{
+	constants.ShopRoleOwner: {
+		constants.ShopPermProductManage, constants.ShopPermOrderRead,
+		constants.ShopPermProfileUpdate, constants.ShopPermMemberManage, constants.ShopPermVerification,
+		constants.ShopPermFinance,
+	},
+	constants.ShopRoleManager: {
+		constants.ShopPermProductManage, constants.ShopPermOrderRead, constants.ShopPermProfileUpdate,
+	},
+	constants.ShopRoleStaff: {
+		constants.ShopPermProductManage, constants.ShopPermOrderRead,
+	},
+}
+
+type ShopMemberService interface {
+	// Authorize returns the shop when the user's shop role grants the permission.
+	Authorize(userID, shopID int, permission string) (*model.Shop, error)
+	GetMyShops(userID int) ([]response.ShopMembershipResponse, error)
+	GetMembers(userID, shopID int) ([]response.ShopMemberResponse, error)
+	UpdateMember(userID, shopID, memberID int, req *request.UpdateShopMemberRequest) (*response.ShopMemberResponse, error)
+	RemoveMember(userID, shopID, memberID int) error
+	Invite(userID, shopID int, req *request.InviteShopMemberRequest) (*response.ShopInvitationResponse, error)
+	GetInvitations(userID, shopID int) ([]response.ShopInvitationResponse, error)
+	RevokeInvitation(userID, shopID, invitationID int) error
+	AcceptInvitation(userID int, req *request.RespondShopInvitationRequest) (*response.ShopMembershipResponse, error)
+	DeclineInvitation(userID int, req *request.RespondShopInvitationRequest) error
+}
+
+type shopMemberService struct {
+	shopMemberRepo repositories.ShopMemberRepository
+	shopRepo       repositories.ShopRepository
+	userRepo       repositories.UserRepository
+	emailService   EmailService
+}
+
+func NewShopMemberService(shopMemberRepo repositories.ShopMemberRepository, shopRepo repositories.ShopRepository, userRepo repositories.UserRepository, emailService EmailService) ShopMemberService {
+	return &shopMemberService{
+		shopMemberRepo: shopMemberRepo,
+		shopRepo:       shopRepo,
+		userRepo:       userRepo,
+		emailService:   emailService,
+	}
+}
+
+func (s *shopMemberService) Authorize(userID, shopID int, permission string) (*model.Shop, error) {
+	shop, err := s.shopRepo.GetByID(shopID)
+	if err != nil {
+		return nil, errors.New(constants.ErrShopNotFound)
+	}
+
+	role, err := s.shopRole(shop, userID)
+	if err != nil || !slices.Contains(shopRolePermissions[role], permission) {
+		return nil, errors.New(constants.ErrForbidden)
+	}
+
+	return shop, nil
+}
+
+func (s *shopMemberService) GetMyShops(userID int) ([]response.ShopMembershipResponse, error) {
+	memberships := []response.ShopMembershipResponse{}
+
+	if shop, err := s.shopRepo.GetByUserID(userID); err == nil {
+		memberships = append(memberships, mapShopMembership(shop, constants.ShopRoleOwner))
+	}
+
+	members, err := s.shopMemberRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		memberships = append(memberships, mapShopMembership(&member.Toko, member.Role))
+	}
+
+	return memberships, nil
+}
+
+func (s *shopMemberService) GetMembers(userID, shopID int) ([]response.ShopMemberResponse, error) {
+	shop, err := s.shopRepo.GetByID(shopID)
+	if err != nil {
+		return nil, errors.New(constants.ErrShopNotFound)
+	}
+
+	// Any member may see who else works in the shop
+	if _, err := s.shopRole(shop, userID); err != nil {
+		return nil, errors.New(constants.ErrForbidden)
+	}
+
+	members, err := s.shopMemberRepo.GetMembers(shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberResponses := []response.ShopMemberResponse{{
+		IDUser:   shop.User.ID,
+		Nama:     shop.User.Nama,
+		Email:    shop.User.Email,
+		Role:     constants.ShopRoleOwner,
+		JoinedAt: shop.CreatedAt.Format("2006-01-02 15:04:05"),
+	}}
+	for _, member := range members {
+		memberResponses = append(memberResponses, mapShopMember(member))
+	}
+
+	return memberResponses, nil
+}
+
+func (s *shopMemberService) UpdateMember(userID, shopID, memberID int, req *request.UpdateShopMemberRequest) (*response.ShopMemberResponse, error) {
+	if _, err := s.Authorize(userID, shopID, constants.ShopPermMemberManage); err != nil {
+		return nil, err
+	}
+
+	member, err := s.shopMemberRepo.GetMember(shopID, memberID)
+	if err != nil {
+		return nil, errors.New(constants.ErrShopMemberNotFound)
+	}
+
+	member.Role = req.Role
+	if err := s.shopMemberRepo.UpdateMember(member); err != nil {
+		return nil, err
+	}
+
+	memberResponse := mapShopMember(*member)
+	return &memberResponse, nil
+}
+
+func (s *shopMemberService) RemoveMember(userID, shopID, memberID int) error {
+	shop, err := s.shopRepo.GetByID(shopID)
+	if err != nil {
+		return errors.New(constants.ErrShopNotFound)
+	}
+
+	if memberID == shop.IDUser {
+		return errors.New(constants.ErrCannotRemoveShopOwner)
+	}
+
+	// Members may always leave a shop on their own
+	if memberID != userID {
+		if _, err := s.Authorize(userID, shopID, constants.ShopPermMemberManage); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := s.shopMemberRepo.DeleteMember(shopID, memberID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New(constants.ErrShopMemberNotFound)
+	}
+	return nil
+}
+
+func (s *shopMemberService) Invite(userID, shopID int, req *request.InviteShopMemberRequest) (*response.ShopInvitationResponse, error) {
+	shop, err := s.Authorize(userID, shopID, constants.ShopPermMemberManage)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if invitee, err := s.userRepo.GetByEmail(email); err == nil {
+		if _, err := s.shopRole(shop, invitee.ID); err == nil {
+			return nil, errors.New(constants.ErrAlreadyShopMember)
+		}
+	}
+
+	if err := s.shopMemberRepo.RevokePendingInvitations(shopID, email); err != nil {
+		return nil, err
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	invitation := &model.ShopInvitation{
+		IDToko:    shopID,
+		Email:     email,
+		Role:      req.Role,
+		TokenHash: hashShopInvitationToken(token),
+		Status:    constants.ShopInvitationPending,
+		IDInviter: userID,
+		ExpiresAt: time.Now().Add(constants.ShopInvitationTTL),
+	}
+	if err := s.shopMemberRepo.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+
+	if err := s.emailService.SendShopInvitationEmail(email, shop.NamaToko, req.Role, token); err != nil {
+		log.Printf("Failed to send shop invitation to %s: %v", email, err)
+	}
+
+	invitationResponse := mapShopInvitation(*invitation)
+	return &invitationResponse, nil
+}
+
+func (s *shopMemberService) GetInvitations(userID, shopID int) ([]response.ShopInvitationResponse, error) {
+	if _, err := s.Authorize(userID, shopID, constants.ShopPermMemberManage); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.shopMemberRepo.GetPendingInvitations(shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	var invitationResponses []response.ShopInvitationResponse
+	for _, invitation := range invitations {
+		invitationResponses = append(invitationResponses, mapShopInvitation(invitation))
+	}
+
+	return invitationResponses, nil
+}
+
+func (s *shopMemberService) RevokeInvitation(userID, shopID, invitationID int) error {
+	if _, err := s.Authorize(userID, shopID, constants.ShopPermMemberManage); err != nil {
+		return err
+	}
+
+	invitation, err := s.shopMemberRepo.GetInvitation(shopID, invitationID)
+	if err != nil || invitation.Status != constants.ShopInvitationPending {
+		return errors.New(constants.ErrShopInvitationNotFound)
+	}
+
+	return s.respond(invitation, constants.ShopInvitationRevoked)
+}
+
+func (s *shopMemberService) AcceptInvitation(userID int, req *request.RespondShopInvitationRequest) (*response.ShopMembershipResponse, error) {
+	invitation, user, err := s.pendingInvitation(userID, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.shopRole(&invitation.Toko, user.ID); err == nil {
+		return nil, errors.New(constants.ErrAlreadyShopMember)
+	}
+
+	member := &model.ShopMember{
+		IDToko: invitation.IDToko,
+		IDUser: user.ID,
+		Role:   invitation.Role,
+	}
+	if err := s.shopMemberRepo.AcceptInvitation(invitation, member); err != nil {
+		return nil, errors.New(constants.ErrShopInvitationNotFound)
+	}
+
+	membership := mapShopMembership(&invitation.Toko, member.Role)
+	return &membership, nil
+}
+
+func (s *shopMemberService) DeclineInvitation(userID int, req *request.RespondShopInvitationRequest) error {
+	invitation, _, err := s.pendingInvitation(userID, req.Token)
+	if err != nil {
+		return err
+	}
+
+	return s.respond(invitation, constants.ShopInvitationDeclined)
+}
+
+// pendingInvitation looks up a usable invitation addressed to the user.
+func (s *shopMemberService) pendingInvitation(userID int, token string) (*model.ShopInvitation, *model.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, nil, errors.New(constants.ErrUserNotFound)
+	}
+
+	invitation, err := s.shopMemberRepo.GetInvitationByTokenHash(hashShopInvitationToken(token))
+	if err != nil || invitation.Status != constants.ShopInvitationPending || time.Now().After(invitation.ExpiresAt) {
+		return nil, nil, errors.New(constants.ErrShopInvitationNotFound)
+	}
+
+	// The token alone is not enough: it must be redeemed by the invited account
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, nil, errors.New(constants.ErrInvitationEmailMismatch)
+	}
+
+	return invitation, user, nil
+}
+
+func (s *shopMemberService) respond(invitation *model.ShopInvitation, status string) error {
+	now := time.Now()
+	invitation.Status = status
+	invitation.RespondedAt = &now
+	return s.shopMemberRepo.UpdateInvitation(invitation)
+}
+
+// shopRole returns the user's role in the shop, or an error if they have none.
+func (s *shopMemberService) shopRole(shop *model.Shop, userID int) (string, error) {
+	if shop.IDUser == userID {
+		return constants.ShopRoleOwner, nil
+	}
+
+	member, err := s.shopMemberRepo.GetMember(shop.ID, userID)
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+func mapShopMembership(shop *model.Shop, role string) response.ShopMembershipResponse {
+	return response.ShopMembershipResponse{
+		Shop:        mapShopToResponse(shop),
+		Role:        role,
+		Permissions: shopRolePermissions[role],
+	}
+}
+
+func mapShopMember(member model.ShopMember) response.ShopMemberResponse {
+	return response.ShopMemberResponse{
+		IDUser:   member.IDUser,
+		Nama:     member.User.Nama,
+		Email:    member.User.Email,
+		Role:     member.Role,
+		JoinedAt: member.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func mapShopInvitation(invitation model.ShopInvitation) response.ShopInvitationResponse {
+	return response.ShopInvitationResponse{
+		ID:        invitation.ID,
+		IDToko:    invitation.IDToko,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		Status:    invitation.Status,
+		ExpiresAt: invitation.ExpiresAt.Format("2006-01-02 15:04:05"),
+		CreatedAt: invitation.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func hashShopInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,47 +1,111 @@
 package services
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/rdsarjito/marketplace-backend/constants"
 	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
 	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/media"
 	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/storage"
+	"github.com/rdsarjito/marketplace-backend/utils"
 )
 
 type ShopService interface {
+	// CreateShop opens a shop owned by userID. Each user can own one shop.
+	CreateShop(userID int, req *request.CreateShopRequest) (*response.ShopResponse, error)
 	MyShop(userID int) (*response.ShopResponse, error)
 	GetListShop() ([]response.ShopResponse, error)
 	GetDetailShop(shopID int) (*response.ShopResponse, error)
+	// GetStorefront returns the shop with the given handle and a page of its
+	// products, filtered and sorted like the product list.
+	GetStorefront(handle string, query *request.ProductListQuery) (*response.StorefrontResponse, error)
 	UpdateProfileShop(userID, shopID int, req *request.UpdateShopRequest) (*response.ShopResponse, error)
+	// UpdateAddress sets the address the shop ships its orders from.
+	UpdateAddress(userID, shopID int, req *request.ShopAddressRequest) (*response.ShopResponse, error)
+	// UpdateOpeningHours replaces the shop's opening hours.
+	UpdateOpeningHours(userID, shopID int, req *request.ShopOpeningHoursRequest) (*response.ShopResponse, error)
+	// SetVacation turns vacation mode on or off. Products of a shop on
+	// vacation stay visible but cannot be ordered.
+	SetVacation(userID, shopID int, req *request.ShopVacationRequest) (*response.ShopResponse, error)
+	// UploadImage replaces the shop's logo or banner, cropped to fit.
+	UploadImage(ctx context.Context, userID, shopID int, kind string, file io.Reader) (*response.ShopResponse, error)
+	DeleteImage(ctx context.Context, userID, shopID int, kind string) (*response.ShopResponse, error)
 }
 
 type shopService struct {
-	shopRepo repositories.ShopRepository
+	shopRepo          repositories.ShopRepository
+	provinceCityRepo  repositories.ProvinceCityRepository
+	shopMemberService ShopMemberService
+	productService    ProductService
+	mediaStorage      storage.MediaStorage
 }
 
-func NewShopService(shopRepo repositories.ShopRepository) ShopService {
+func NewShopService(shopRepo repositories.ShopRepository, provinceCityRepo repositories.ProvinceCityRepository, shopMemberService ShopMemberService, productService ProductService, mediaStorage storage.MediaStorage) ShopService {
 	return &shopService{
-		shopRepo: shopRepo,
+		shopRepo:          shopRepo,
+		provinceCityRepo:  provinceCityRepo,
+		shopMemberService: shopMemberService,
+		productService:    productService,
+		mediaStorage:      mediaStorage,
 	}
 }
 
+// Sizes shop images are cropped to
+var shopImageTransforms = map[string]media.Transform{
+	constants.ShopImageLogo:   {Width: constants.ShopLogoSize, Height: constants.ShopLogoSize, Fit: media.FitCover},
+	constants.ShopImageBanner: {Width: constants.ShopBannerWidth, Height: constants.ShopBannerHeight, Fit: media.FitCover},
+}
+
+func (s *shopService) CreateShop(userID int, req *request.CreateShopRequest) (*response.ShopResponse, error) {
+	if _, err := s.shopRepo.GetByUserID(userID); err == nil {
+		return nil, errors.New(constants.ErrShopAlreadyExists)
+	}
+
+	var handle string
+	if req.URLToko != "" {
+		handle = normalizeShopHandle(req.URLToko)
+		if err := s.checkHandle(handle, 0); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if handle, err = s.uniqueHandle(req.NamaToko); err != nil {
+			return nil, err
+		}
+	}
+
+	shop := &model.Shop{
+		NamaToko:         req.NamaToko,
+		URLToko:          handle,
+		StatusVerifikasi: constants.ShopUnverified,
+		IDUser:           userID,
+	}
+	if err := s.shopRepo.Create(shop); err != nil {
+		return nil, err
+	}
+
+	shopResponse := mapShopToResponse(shop)
+	return &shopResponse, nil
+}
+
 func (s *shopService) MyShop(userID int) (*response.ShopResponse, error) {
 	shop, err := s.shopRepo.GetByUserID(userID)
 	if err != nil {
 		return nil, errors.New(constants.ErrShopNotFound)
 	}
 
-	shopResponse := &response.ShopResponse{
-		ID:        shop.ID,
-		NamaToko:  shop.NamaToko,
-		URLToko:   shop.URLToko,
-		CreatedAt: shop.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: shop.UpdatedAt.Format("2006-01-02 15:04:05"),
-		IDUser:    shop.IDUser,
-	}
-
-	return shopResponse, nil
+	shopResponse := mapShopToResponse(shop)
+	return &shopResponse, nil
 }
 
 func (s *shopService) GetListShop() ([]response.ShopResponse, error) {
@@ -52,14 +116,7 @@ func (s *shopService) GetListShop() ([]response.ShopResponse, error) {
 
 	var shopResponses []response.ShopResponse
 	for _, shop := range shops {
-		shopResponses = append(shopResponses, response.ShopResponse{
-			ID:        shop.ID,
-			NamaToko:  shop.NamaToko,
-			URLToko:   shop.URLToko,
-			CreatedAt: shop.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: shop.UpdatedAt.Format("2006-01-02 15:04:05"),
-			IDUser:    shop.IDUser,
-		})
+		shopResponses = append(shopResponses, mapShopToResponse(&shop))
 	}
 
 	return shopResponses, nil
@@ -71,43 +128,324 @@ func (s *shopService) GetDetailShop(shopID int) (*response.ShopResponse, error)
 		return nil, errors.New(constants.ErrShopNotFound)
 	}
 
-	shopResponse := &response.ShopResponse{
-		ID:        shop.ID,
-		NamaToko:  shop.NamaToko,
-		URLToko:   shop.URLToko,
-		CreatedAt: shop.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: shop.UpdatedAt.Format("2006-01-02 15:04:05"),
-		IDUser:    shop.IDUser,
+	shopResponse := mapShopToResponse(shop)
+	return &shopResponse, nil
+}
+
+func (s *shopService) GetStorefront(handle string, query *request.ProductListQuery) (*response.StorefrontResponse, error) {
+	shop, err := s.shopRepo.GetByHandle(normalizeShopHandle(handle))
+	if err != nil {
+		return nil, errors.New(constants.ErrShopNotFound)
 	}
 
-	return shopResponse, nil
+	query.IDToko = shop.ID
+	products, err := s.productService.GetListProduct(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.StorefrontResponse{
+		Toko:                mapShopToResponse(shop),
+		ProductListResponse: *products,
+	}, nil
 }
 
 func (s *shopService) UpdateProfileShop(userID, shopID int, req *request.UpdateShopRequest) (*response.ShopResponse, error) {
-	shop, err := s.shopRepo.GetByID(shopID)
+	// Owners and managers may edit the shop profile
+	shop, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProfileUpdate)
 	if err != nil {
-		return nil, errors.New(constants.ErrShopNotFound)
+		return nil, err
 	}
 
-	if shop.IDUser != userID {
-		return nil, errors.New(constants.ErrForbidden)
+	handle := normalizeShopHandle(req.URLToko)
+	if handle != shop.URLToko {
+		if err := s.checkHandle(handle, shop.ID); err != nil {
+			return nil, err
+		}
 	}
 
 	shop.NamaToko = req.NamaToko
-	shop.URLToko = req.URLToko
+	shop.URLToko = handle
+	shop.Deskripsi = req.Deskripsi
 
 	if err := s.shopRepo.Update(shop); err != nil {
 		return nil, err
 	}
 
-	shopResponse := &response.ShopResponse{
+	shopResponse := mapShopToResponse(shop)
+	return &shopResponse, nil
+}
+
+func (s *shopService) UpdateAddress(userID, shopID int, req *request.ShopAddressRequest) (*response.ShopResponse, error) {
+	shop, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProfileUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkProvinceCity(req.IDProvinsi, req.IDKota); err != nil {
+		return nil, err
+	}
+
+	shop.DetailAlamat = req.DetailAlamat
+	shop.IDProvinsi = req.IDProvinsi
+	shop.IDKota = req.IDKota
+	shop.KodePos = req.KodePos
+
+	if err := s.shopRepo.Update(shop); err != nil {
+		return nil, err
+	}
+
+	shopResponse := mapShopToResponse(shop)
+	return &shopResponse, nil
+}
+
+// checkProvinceCity checks that the province exists and that the city is one
+// of its cities.
+func (s *shopService) checkProvinceCity(provID, cityID string) error {
+	if _, err := s.provinceCityRepo.GetDetailProvince(provID); err != nil {
+		log.Printf("[Shop] province %s lookup failed: %v", provID, err)
+		return errors.New(constants.ErrProvinceNotFound)
+	}
+	cities, err := s.provinceCityRepo.GetListCity(provID)
+	if err != nil {
+		log.Printf("[Shop] cities of province %s lookup failed: %v", provID, err)
+		return errors.New(constants.ErrCityNotFound)
+	}
+	for _, city := range cities {
+		if id, _ := city["id"].(string); id == cityID {
+			return nil
+		}
+	}
+	return errors.New(constants.ErrCityNotFound)
+}
+
+func (s *shopService) UpdateOpeningHours(userID, shopID int, req *request.ShopOpeningHoursRequest) (*response.ShopResponse, error) {
+	shop, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProfileUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	hours := make([]model.ShopOpeningHour, 0, len(req.JamOperasional))
+	seen := make(map[int]bool, len(req.JamOperasional))
+	for _, day := range req.JamOperasional {
+		// Times are validated as HH:MM, so they compare as strings
+		if seen[day.Hari] || day.Tutup <= day.Buka {
+			return nil, errors.New(constants.ErrInvalidOpeningHours)
+		}
+		seen[day.Hari] = true
+
+		hours = append(hours, model.ShopOpeningHour{
+			IDToko:   shop.ID,
+			Hari:     day.Hari,
+			JamBuka:  day.Buka,
+			JamTutup: day.Tutup,
+		})
+	}
+
+	if err := s.shopRepo.ReplaceOpeningHours(shop.ID, hours); err != nil {
+		return nil, err
+	}
+
+	return s.GetDetailShop(shop.ID)
+}
+
+func (s *shopService) SetVacation(userID, shopID int, req *request.ShopVacationRequest) (*response.ShopResponse, error) {
+	shop, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProfileUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	shop.Libur = req.Libur
+	shop.PesanLibur = ""
+	shop.BukaKembali = nil
+	if req.Libur {
+		shop.PesanLibur = req.PesanLibur
+		if req.BukaKembali != "" {
+			bukaKembali, err := time.ParseInLocation("2006-01-02", req.BukaKembali, time.Local)
+			if err != nil || !bukaKembali.After(time.Now()) {
+				return nil, errors.New(constants.ErrInvalidVacationEnd)
+			}
+			shop.BukaKembali = &bukaKembali
+		}
+	}
+
+	if err := s.shopRepo.Update(shop); err != nil {
+		return nil, err
+	}
+
+	shopResponse := mapShopToResponse(shop)
+	return &shopResponse, nil
+}
+
+func (s *shopService) UploadImage(ctx context.Context, userID, shopID int, kind string, file io.Reader) (*response.ShopResponse, error) {
+	transform, ok := shopImageTransforms[kind]
+	if !ok {
+		return nil, errors.New(constants.ErrInvalidInput)
+	}
+	shop, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProfileUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, constants.MaxPhotoFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > constants.MaxPhotoFileSize {
+		return nil, errors.New(constants.ErrPhotoTooLarge)
+	}
+
+	img, err := transform.Apply(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// shops/<id>/logo_<time>.jpg, so a new image never reuses a cached URL
+	objectName := fmt.Sprintf("%s%d/%s_%d%s", constants.ShopMediaPrefix, shop.ID, kind, time.Now().UnixNano(), img.Extension)
+	url, err := s.mediaStorage.Upload(ctx, objectName, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType)
+	if err != nil {
+		log.Printf("[Media] failed to upload %s: %v", objectName, err)
+		return nil, errors.New(constants.ErrPhotoUploadFailed)
+	}
+
+	previous := setShopImage(shop, kind, url, objectName)
+	if err := s.shopRepo.Update(shop); err != nil {
+		s.deleteShopImage(ctx, objectName)
+		return nil, err
+	}
+	s.deleteShopImage(ctx, previous)
+
+	shopResponse := mapShopToResponse(shop)
+	return &shopResponse, nil
+}
+
+func (s *shopService) DeleteImage(ctx context.Context, userID, shopID int, kind string) (*response.ShopResponse, error) {
+	if _, ok := shopImageTransforms[kind]; !ok {
+		return nil, errors.New(constants.ErrInvalidInput)
+	}
+	shop, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermProfileUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := setShopImage(shop, kind, "", "")
+	if err := s.shopRepo.Update(shop); err != nil {
+		return nil, err
+	}
+	s.deleteShopImage(ctx, previous)
+
+	shopResponse := mapShopToResponse(shop)
+	return &shopResponse, nil
+}
+
+// setShopImage points the shop's logo or banner at a new object and returns
+// the previous object's name.
+func setShopImage(shop *model.Shop, kind, url, objectName string) string {
+	if kind == constants.ShopImageLogo {
+		previous := shop.LogoObjectName
+		shop.LogoURL, shop.LogoObjectName = url, objectName
+		return previous
+	}
+	previous := shop.BannerObjectName
+	shop.BannerURL, shop.BannerObjectName = url, objectName
+	return previous
+}
+
+func (s *shopService) deleteShopImage(ctx context.Context, objectName string) {
+	if objectName == "" {
+		return
+	}
+	if err := s.mediaStorage.Delete(ctx, objectName); err != nil {
+		log.Printf("[Media] failed to delete %s: %v", objectName, err)
+	}
+}
+
+// shopOnVacation reports whether the shop is on vacation at now. Vacation
+// ends by itself on BukaKembali.
+func shopOnVacation(shop *model.Shop, now time.Time) bool {
+	return shop.Libur && (shop.BukaKembali == nil || now.Before(*shop.BukaKembali))
+}
+
+// checkHandle verifies that handle is valid and not used by a shop other
+// than shopID.
+func (s *shopService) checkHandle(handle string, shopID int) error {
+	if !utils.ValidShopHandle(handle) {
+		return errors.New(constants.ErrInvalidShopHandle)
+	}
+	exists, err := s.shopRepo.HandleExists(handle, shopID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New(constants.ErrShopHandleTaken)
+	}
+	return nil
+}
+
+// uniqueHandle makes a handle from a shop name, numbering it if it is
+// already taken: toko-kopi, toko-kopi-2, ...
+func (s *shopService) uniqueHandle(name string) (string, error) {
+	base := utils.ShopHandleFromName(name)
+	handle := base
+	for n := 2; ; n++ {
+		if utils.ValidShopHandle(handle) {
+			exists, err := s.shopRepo.HandleExists(handle, 0)
+			if err != nil {
+				return "", err
+			}
+			if !exists {
+				return handle, nil
+			}
+		}
+		handle = utils.NumberedShopHandle(base, n)
+	}
+}
+
+// normalizeShopHandle accepts handles typed with surrounding spaces or in
+// upper case.
+func normalizeShopHandle(handle string) string {
+	return strings.ToLower(strings.TrimSpace(handle))
+}
+
+func mapShopToResponse(shop *model.Shop) response.ShopResponse {
+	shopResponse := response.ShopResponse{
 		ID:        shop.ID,
 		NamaToko:  shop.NamaToko,
 		URLToko:   shop.URLToko,
+		Deskripsi: shop.Deskripsi,
+		LogoURL:   shop.LogoURL,
+		BannerURL: shop.BannerURL,
 		CreatedAt: shop.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt: shop.UpdatedAt.Format("2006-01-02 15:04:05"),
 		IDUser:    shop.IDUser,
+
+		StatusVerifikasi: shop.StatusVerifikasi,
+	}
+
+	if shop.DetailAlamat != "" {
+		shopResponse.Alamat = &response.ShopAddressResponse{
+			DetailAlamat: shop.DetailAlamat,
+			IDProvinsi:   shop.IDProvinsi,
+			IDKota:       shop.IDKota,
+			KodePos:      shop.KodePos,
+		}
+	}
+
+	for _, hour := range shop.JamOperasional {
+		shopResponse.JamOperasional = append(shopResponse.JamOperasional, response.ShopOpeningHourResponse{
+			Hari:  hour.Hari,
+			Buka:  hour.JamBuka,
+			Tutup: hour.JamTutup,
+		})
+	}
+
+	if shopOnVacation(shop, time.Now()) {
+		shopResponse.Libur = true
+		shopResponse.PesanLibur = shop.PesanLibur
+		if shop.BukaKembali != nil {
+			shopResponse.BukaKembali = shop.BukaKembali.Format("2006-01-02")
+		}
 	}
 
-	return shopResponse, nil
+	return shopResponse
 }
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+)
+
+// UnverifiedShopLimits restricts shops that have not been verified yet.
+type UnverifiedShopLimits struct {
+	// MaxProducts caps the shop's products; 0 means no cap
+	MaxProducts int
+	// AllowPayouts lets unverified shops withdraw their balance
+	AllowPayouts bool
+}
+
+type ShopVerificationService interface {
+	// Submit asks for the shop to be verified. The documents must be the
+	// user's own private files of kategori kyc, including an identity card.
+	Submit(userID, shopID int, req *request.SubmitShopVerificationRequest) (*response.ShopVerificationResponse, error)
+	// GetShopVerification returns the shop's latest verification request.
+	GetShopVerification(userID, shopID int) (*response.ShopVerificationResponse, error)
+	GetVerifications(query *request.ShopVerificationQuery) ([]response.ShopVerificationResponse, error)
+	GetVerification(id int) (*response.ShopVerificationResponse, error)
+	// Approve marks the shop verified, lifting the unverified shop limits.
+	Approve(actorID, id int, req *request.ReviewShopVerificationRequest) (*response.ShopVerificationResponse, error)
+	Reject(actorID, id int, req *request.ReviewShopVerificationRequest) (*response.ShopVerificationResponse, error)
+	// CheckProductLimit fails when an unverified shop already has as many
+	// products as it may.
+	CheckProductLimit(shop *model.Shop) error
+	// CheckPayoutAllowed fails when the shop may not withdraw its balance.
+	CheckPayoutAllowed(shop *model.Shop) error
+}
+
+type shopVerificationService struct {
+	verificationRepo  repositories.ShopVerificationRepository
+	privateMediaRepo  repositories.PrivateMediaRepository
+	productRepo       repositories.ProductRepository
+	shopMemberService ShopMemberService
+	mediaService      MediaService
+	emailService      EmailService
+	limits            UnverifiedShopLimits
+}
+
+func NewShopVerificationService(verificationRepo repositories.ShopVerificationRepository, privateMediaRepo repositories.PrivateMediaRepository, productRepo repositories.ProductRepository, shopMemberService ShopMemberService, mediaService MediaService, emailService EmailService, limits UnverifiedShopLimits) ShopVerificationService {
+	return &shopVerificationService{
+		verificationRepo:  verificationRepo,
+		privateMediaRepo:  privateMediaRepo,
+		productRepo:       productRepo,
+		shopMemberService: shopMemberService,
+		mediaService:      mediaService,
+		emailService:      emailService,
+		limits:            limits,
+	}
+}
+
+func (s *shopVerificationService) Submit(userID, shopID int, req *request.SubmitShopVerificationRequest) (*response.ShopVerificationResponse, error) {
+	shop, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermVerification)
+	if err != nil {
+		return nil, err
+	}
+	if shop.StatusVerifikasi == constants.ShopVerificationPending || shop.StatusVerifikasi == constants.ShopVerified {
+		return nil, errors.New(constants.ErrShopVerificationExists)
+	}
+
+	documents, err := s.checkDocuments(userID, req.Dokumen)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &model.ShopVerification{
+		IDToko:      shop.ID,
+		IDUser:      userID,
+		NamaPemilik: strings.TrimSpace(req.NamaPemilik),
+		NIK:         req.NIK,
+		NPWP:        req.NPWP,
+		Status:      constants.ShopVerificationPending,
+		Dokumen:     documents,
+	}
+	if err := s.verificationRepo.Create(verification); err != nil {
+		return nil, err
+	}
+
+	return s.GetVerification(verification.ID)
+}
+
+// checkDocuments makes sure every document is one of the user's kyc files,
+// listed once, and that an identity card is among them.
+func (s *shopVerificationService) checkDocuments(userID int, requests []request.ShopVerificationDocumentRequest) ([]model.ShopVerificationDocument, error) {
+	documents := make([]model.ShopVerificationDocument, 0, len(requests))
+	seen := make(map[int]bool)
+	hasIdentity := false
+	for _, document := range requests {
+		if seen[document.IDMedia] {
+			return nil, errors.New(constants.ErrInvalidVerificationDocument)
+		}
+		seen[document.IDMedia] = true
+
+		privateMedia, err := s.privateMediaRepo.GetByID(document.IDMedia)
+		if err != nil || privateMedia.IDUser != userID || privateMedia.Kategori != constants.PrivateMediaKYC {
+			return nil, errors.New(constants.ErrInvalidVerificationDocument)
+		}
+		if document.Jenis == constants.KYCDocumentKTP {
+			hasIdentity = true
+		}
+		documents = append(documents, model.ShopVerificationDocument{
+			Jenis:   document.Jenis,
+			IDMedia: privateMedia.ID,
+		})
+	}
+	if !hasIdentity {
+		return nil, errors.New(constants.ErrIdentityDocumentRequired)
+	}
+	return documents, nil
+}
+
+func (s *shopVerificationService) GetShopVerification(userID, shopID int) (*response.ShopVerificationResponse, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermVerification); err != nil {
+		return nil, err
+	}
+
+	verification, err := s.verificationRepo.GetLatestByShopID(shopID)
+	if err != nil {
+		return nil, errors.New(constants.ErrShopVerificationNotFound)
+	}
+
+	verificationResponse := s.mapShopVerificationToResponse(*verification)
+	return &verificationResponse, nil
+}
+
+func (s *shopVerificationService) GetVerifications(query *request.ShopVerificationQuery) ([]response.ShopVerificationResponse, error) {
+	verifications, err := s.verificationRepo.GetAll(query.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationResponses := []response.ShopVerificationResponse{}
+	for _, verification := range verifications {
+		verificationResponses = append(verificationResponses, s.mapShopVerificationToResponse(verification))
+	}
+
+	return verificationResponses, nil
+}
+
+func (s *shopVerificationService) GetVerification(id int) (*response.ShopVerificationResponse, error) {
+	verification, err := s.verificationRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New(constants.ErrShopVerificationNotFound)
+	}
+
+	verificationResponse := s.mapShopVerificationToResponse(*verification)
+	return &verificationResponse, nil
+}
+
+func (s *shopVerificationService) Approve(actorID, id int, req *request.ReviewShopVerificationRequest) (*response.ShopVerificationResponse, error) {
+	return s.review(actorID, id, constants.ShopVerified, req)
+}
+
+func (s *shopVerificationService) Reject(actorID, id int, req *request.ReviewShopVerificationRequest) (*response.ShopVerificationResponse, error) {
+	return s.review(actorID, id, constants.ShopVerificationRejected, req)
+}
+
+// review decides a pending request, which also sets the shop's status, and
+// emails the owner about the decision.
+func (s *shopVerificationService) review(actorID, id int, to string, req *request.ReviewShopVerificationRequest) (*response.ShopVerificationResponse, error) {
+	verification, err := s.verificationRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New(constants.ErrShopVerificationNotFound)
+	}
+	if verification.Status != constants.ShopVerificationPending {
+		return nil, errors.New(constants.ErrShopVerificationNotPending)
+	}
+
+	now := time.Now()
+	verification.Status = to
+	verification.Catatan = strings.TrimSpace(req.Catatan)
+	verification.ReviewedBy = &actorID
+	verification.ReviewedAt = &now
+	if err := s.verificationRepo.Update(verification); err != nil {
+		return nil, err
+	}
+	verification.Toko.StatusVerifikasi = to
+
+	if err := s.emailService.SendShopVerificationStatusEmail(verification.User.Email, verification.Toko.NamaToko, to, verification.Catatan); err != nil {
+		log.Printf("[ShopVerification] Failed to email user %d about verification %d: %v", verification.IDUser, verification.ID, err)
+	}
+
+	verificationResponse := s.mapShopVerificationToResponse(*verification)
+	return &verificationResponse, nil
+}
+
+func (s *shopVerificationService) CheckProductLimit(shop *model.Shop) error {
+	if shop.StatusVerifikasi == constants.ShopVerified || s.limits.MaxProducts <= 0 {
+		return nil
+	}
+
+	count, err := s.productRepo.CountByShopID(shop.ID)
+	if err != nil {
+		return err
+	}
+	if count >= int64(s.limits.MaxProducts) {
+		return errors.New(constants.ErrUnverifiedProductLimit)
+	}
+	return nil
+}
+
+func (s *shopVerificationService) CheckPayoutAllowed(shop *model.Shop) error {
+	if shop.StatusVerifikasi == constants.ShopVerified || s.limits.AllowPayouts {
+		return nil
+	}
+	return errors.New(constants.ErrShopNotVerified)
+}
+
+func (s *shopVerificationService) mapShopVerificationToResponse(verification model.ShopVerification) response.ShopVerificationResponse {
+	verificationResponse := response.ShopVerificationResponse{
+		ID:          verification.ID,
+		IDToko:      verification.IDToko,
+		NamaToko:    verification.Toko.NamaToko,
+		IDUser:      verification.IDUser,
+		Email:       verification.User.Email,
+		NamaPemilik: verification.NamaPemilik,
+		NIK:         verification.NIK,
+		NPWP:        verification.NPWP,
+		Dokumen:     []response.ShopVerificationDocumentResponse{},
+		Status:      verification.Status,
+		Catatan:     verification.Catatan,
+		ReviewedBy:  verification.ReviewedBy,
+		CreatedAt:   verification.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   verification.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	for i := range verification.Dokumen {
+		document := &verification.Dokumen[i]
+		verificationResponse.Dokumen = append(verificationResponse.Dokumen, response.ShopVerificationDocumentResponse{
+			Jenis: document.Jenis,
+			Media: s.mediaService.PrivateMediaToResponse(&document.Media),
+		})
+	}
+	if verification.ReviewedAt != nil {
+		verificationResponse.ReviewedAt = verification.ReviewedAt.Format("2006-01-02 15:04:05")
+	}
+	return verificationResponse
+}
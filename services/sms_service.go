@@ -0,0 +1,64 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMSSender delivers text messages to a phone number in E.164 format.
+type SMSSender interface {
+	SendSMS(phone, message string) error
+}
+
+// NewSMSSenderFromEnv selects an SMSSender based on SMS_DRIVER.
+// Supported drivers: "console" (default) and "file" (writes to SMS_LOG_FILE).
+func NewSMSSenderFromEnv() SMSSender {
+	switch strings.ToLower(getEnv("SMS_DRIVER", "console")) {
+	case "file":
+		return NewFileSMSSender(getEnv("SMS_LOG_FILE", "sms.log"))
+	default:
+		return NewConsoleSMSSender()
+	}
+}
+
+type consoleSMSSender struct{}
+
+// NewConsoleSMSSender returns a sender that prints messages to stdout (for development).
+func NewConsoleSMSSender() SMSSender {
+	return &consoleSMSSender{}
+}
+
+func (s *consoleSMSSender) SendSMS(phone, message string) error {
+	fmt.Printf("=== SMS ===\n")
+	fmt.Printf("To: %s\n", phone)
+	fmt.Printf("Message: %s\n", message)
+	fmt.Printf("===========\n")
+	return nil
+}
+
+type fileSMSSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSMSSender returns a sender that appends messages to a local file (for development).
+func NewFileSMSSender(path string) SMSSender {
+	return &fileSMSSender{path: path}
+}
+
+func (s *fileSMSSender) SendSMS(phone, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open sms log: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "[%s] to=%s message=%q\n", time.Now().Format(time.RFC3339), phone, message)
+	return err
+}
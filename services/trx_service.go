@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
+	"log"
 	"strings"
 	"time"
 
@@ -18,34 +18,46 @@ import (
 type TRXService interface {
 	GetListTRX(userID int) ([]response.TRXResponse, error)
 	GetDetailTRX(userID, trxID int) (*response.TRXResponse, error)
+	GetShopTRX(userID, shopID int) ([]response.ShopTRXResponse, error)
 	CreateTRX(userID int, req *request.CreateTRXRequest) (*response.TRXResponse, error)
 	HandlePaymentWebhook(notification map[string]interface{}) error
 	CheckPaymentStatus(userID, trxID int) (*response.TRXResponse, error)
+	// CompleteTRX lets the buyer confirm a paid order arrived, releasing its
+	// funds to the shops before the hold period ends.
+	CompleteTRX(userID, trxID int) (*response.TRXResponse, error)
 }
 
 type trxService struct {
-	trxRepo         repositories.TRXRepository
-	productRepo     repositories.ProductRepository
-	addressRepo     repositories.AddressRepository
-	shopRepo        repositories.ShopRepository
-	categoryRepo    repositories.CategoryRepository
-	userRepo        repositories.UserRepository
-	midtransService MidtransService
-	emailService    EmailService
-	frontendURL     string // Frontend URL for payment redirect
-}
-
-func NewTRXService(trxRepo repositories.TRXRepository, productRepo repositories.ProductRepository, addressRepo repositories.AddressRepository, shopRepo repositories.ShopRepository, categoryRepo repositories.CategoryRepository, userRepo repositories.UserRepository, midtransService MidtransService, emailService EmailService, frontendURL string) TRXService {
+	trxRepo           repositories.TRXRepository
+	productRepo       repositories.ProductRepository
+	logProductRepo    repositories.LogProductRepository
+	addressRepo       repositories.AddressRepository
+	shopRepo          repositories.ShopRepository
+	categoryRepo      repositories.CategoryRepository
+	userRepo          repositories.UserRepository
+	midtransService   MidtransService
+	emailService      EmailService
+	shopMemberService ShopMemberService
+	roleService       RoleService
+	ledgerService     LedgerService
+	frontendURL       string // Frontend URL for payment redirect
+}
+
+func NewTRXService(trxRepo repositories.TRXRepository, productRepo repositories.ProductRepository, logProductRepo repositories.LogProductRepository, addressRepo repositories.AddressRepository, shopRepo repositories.ShopRepository, categoryRepo repositories.CategoryRepository, userRepo repositories.UserRepository, midtransService MidtransService, emailService EmailService, shopMemberService ShopMemberService, roleService RoleService, ledgerService LedgerService, frontendURL string) TRXService {
 	return &trxService{
-		trxRepo:         trxRepo,
-		productRepo:     productRepo,
-		addressRepo:     addressRepo,
-		shopRepo:        shopRepo,
-		categoryRepo:    categoryRepo,
-		userRepo:        userRepo,
-		midtransService: midtransService,
-		emailService:    emailService,
-		frontendURL:     frontendURL,
+		trxRepo:           trxRepo,
+		productRepo:       productRepo,
+		logProductRepo:    logProductRepo,
+		addressRepo:       addressRepo,
+		shopRepo:          shopRepo,
+		categoryRepo:      categoryRepo,
+		userRepo:          userRepo,
+		midtransService:   midtransService,
+		emailService:      emailService,
+		shopMemberService: shopMemberService,
+		roleService:       roleService,
+		ledgerService:     ledgerService,
+		frontendURL:       frontendURL,
 	}
 }
 
@@ -78,6 +90,27 @@ func (s *trxService) GetDetailTRX(userID, trxID int) (*response.TRXResponse, err
 	return &trxResponse, nil
 }
 
+// GetShopTRX lists the orders a shop has received. Only the shop's own items
+// and where to ship them are included, not the buyer's account or the rest of
+// the order.
+func (s *trxService) GetShopTRX(userID, shopID int) ([]response.ShopTRXResponse, error) {
+	if _, err := s.shopMemberService.Authorize(userID, shopID, constants.ShopPermOrderRead); err != nil {
+		return nil, err
+	}
+
+	trxs, err := s.trxRepo.GetByShopID(shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	var trxResponses []response.ShopTRXResponse
+	for _, trx := range trxs {
+		trxResponses = append(trxResponses, mapShopTRXToResponse(trx))
+	}
+
+	return trxResponses, nil
+}
+
 func (s *trxService) CreateTRX(userID int, req *request.CreateTRXRequest) (*response.TRXResponse, error) {
 	// Validate address belongs to user
 	address, err := s.addressRepo.GetByID(req.IDAlamat)
@@ -89,41 +122,80 @@ func (s *trxService) CreateTRX(userID int, req *request.CreateTRXRequest) (*resp
 		return nil, errors.New(constants.ErrForbidden)
 	}
 
+	// Approved resellers, or anyone given the permission through a role,
+	// get reseller prices
+	reseller, err := s.roleService.HasPermission(userID, constants.PermResellerPrice)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate products and calculate total
-	totalHarga := 0
+	var totalHarga int64
+	items := make([]checkoutItem, 0, len(req.DetailTRX))
 	for _, detail := range req.DetailTRX {
+		// Quantities drive stock changes, so never trust them unchecked
+		if detail.Kuantitas <= 0 {
+			return nil, errors.New(constants.ErrInvalidQuantity)
+		}
+
 		product, err := s.productRepo.GetByID(detail.IDProduk)
 		if err != nil {
 			return nil, errors.New(constants.ErrProductNotFound)
 		}
 
+		// Products with variants are bought per variant
+		item, err := resolveCheckoutItem(product, detail.IDVariant)
+		if err != nil {
+			return nil, err
+		}
+
+		// A price of 0 means the seller has not set one yet
+		if item.hargaKonsumen <= 0 {
+			return nil, errors.New(constants.ErrProductPriceNotSet)
+		}
+
 		// Check stock
-		if product.Stok < detail.Kuantitas {
+		if item.stok < detail.Kuantitas {
 			return nil, errors.New(constants.ErrInsufficientStock)
 		}
 
-		// Validate shop
-		_, err = s.shopRepo.GetByID(detail.IDToko)
+		// Validate shop; the line is credited to the product's shop, so a
+		// request naming another one is rejected
+		if detail.IDToko != product.IDToko {
+			return nil, errors.New(constants.ErrProductNotInShop)
+		}
+		_, err = s.shopRepo.GetByID(product.IDToko)
 		if err != nil {
 			return nil, errors.New(constants.ErrShopNotFound)
 		}
+		item.shopID = product.IDToko
+
+		// Shops on vacation keep their products listed but take no orders
+		if shopOnVacation(&product.Toko, time.Now()) {
+			return nil, errors.New(constants.ErrShopOnVacation)
+		}
 
-		// Calculate price (using harga konsumen)
-		hargaKonsumen, err := strconv.Atoi(product.HargaKonsumen)
+		// Reference the product as it is now, so order history keeps
+		// showing what was bought after the seller edits it
+		snapshot, err := s.logProductRepo.Current(product, nil)
 		if err != nil {
-			return nil, errors.New("Invalid price format")
+			return nil, err
 		}
+		item.logProdukID = &snapshot.ID
 
-		detailHarga := hargaKonsumen * detail.Kuantitas
-		if detailHarga != detail.HargaTotal {
+		// Calculate price from the buyer's price tier
+		item.tierHarga, item.hargaSatuan = item.price(reseller, detail.Kuantitas)
+		detailHarga := item.hargaSatuan * int64(detail.Kuantitas)
+		if detailHarga != int64(detail.HargaTotal) {
 			return nil, errors.New("Price calculation mismatch")
 		}
 
+		items = append(items, item)
 		totalHarga += detailHarga
 	}
 
 	// Validate total price
-	if totalHarga != req.HargaTotal {
+	if totalHarga != int64(req.HargaTotal) {
 		return nil, errors.New("Total price mismatch")
 	}
 
@@ -138,7 +210,7 @@ func (s *trxService) CreateTRX(userID int, req *request.CreateTRXRequest) (*resp
 
 	// Create transaction
 	trx := &model.TRX{
-		HargaTotal:    req.HargaTotal,
+		HargaTotal:    int64(req.HargaTotal),
 		KodeInvoice:   kodeInvoice,
 		MethodBayar:   req.MethodBayar,
 		PaymentStatus: "pending_payment",
@@ -150,30 +222,65 @@ func (s *trxService) CreateTRX(userID int, req *request.CreateTRXRequest) (*resp
 		return nil, err
 	}
 
-    // Create detail transactions and update stock
+	// Put back the stock of the first n lines and fail the transaction
+	failTRX := func(n int) {
+		for j := 0; j < n; j++ {
+			_ = s.productRepo.IncrementStock(req.DetailTRX[j].IDProduk, items[j].variantID, req.DetailTRX[j].Kuantitas)
+		}
+		s.trxRepo.UpdatePaymentStatus(trx.ID, "failed", "", "", "", nil, "", "", "")
+	}
+
+	// Take the stock; if another order got it first, put back what was
+	// already taken and fail the transaction
+	for i, detailReq := range req.DetailTRX {
+		taken, err := s.productRepo.DecrementStock(detailReq.IDProduk, items[i].variantID, detailReq.Kuantitas)
+		if err == nil && !taken {
+			err = errors.New(constants.ErrInsufficientStock)
+		}
+		if err != nil {
+			failTRX(i)
+			return nil, err
+		}
+	}
+
+	// Create detail transactions
 	var itemDetails []map[string]interface{}
-    for _, detailReq := range req.DetailTRX {
-        // Update product stock
-        product, _ := s.productRepo.GetByID(detailReq.IDProduk)
-        product.Stok -= detailReq.Kuantitas
-        s.productRepo.Update(product)
-
-        // Create detail record
-        detail := &model.DetailTRX{
-            IDTRX:      trx.ID,
-            IDProduk:   detailReq.IDProduk,
-            IDToko:     detailReq.IDToko,
-            Kuantitas:  detailReq.Kuantitas,
-            HargaTotal: detailReq.HargaTotal,
-        }
-        _ = s.trxRepo.CreateDetail(detail)
+	for i, detailReq := range req.DetailTRX {
+		item := items[i]
+
+		detail := &model.DetailTRX{
+			IDTRX:      trx.ID,
+			IDProduk:   detailReq.IDProduk,
+			IDVariant:  item.variantID,
+			IDLogProduk: item.logProdukID,
+			IDToko:      item.shopID,
+			Kuantitas:   detailReq.Kuantitas,
+			HargaSatuan: item.hargaSatuan,
+			TierHarga:   item.tierHarga,
+			HargaTotal:  int64(detailReq.HargaTotal),
+		}
+		if item.variantID != nil {
+			atribut, err := json.Marshal(item.variantAtribut)
+			if err != nil {
+				failTRX(len(req.DetailTRX))
+				return nil, err
+			}
+			detail.VariantSKU = item.variantSKU
+			detail.VariantAtribut = string(atribut)
+			detail.VariantHargaReseller = item.hargaReseller
+			detail.VariantHargaKonsumen = item.hargaKonsumen
+		}
+		if err := s.trxRepo.CreateDetail(detail); err != nil {
+			failTRX(len(req.DetailTRX))
+			return nil, err
+		}
 
 		// Build item details for Midtrans
 		itemDetails = append(itemDetails, map[string]interface{}{
-			"id":       fmt.Sprintf("product-%d", product.ID),
-			"price":    product.HargaKonsumen,
+			"id":       item.midtransID,
+			"price":    item.hargaSatuan,
 			"quantity": detailReq.Kuantitas,
-			"name":     product.NamaProduk,
+			"name":     item.name,
 		})
 	}
 
@@ -208,7 +315,7 @@ func (s *trxService) CreateTRX(userID int, req *request.CreateTRXRequest) (*resp
 		// Create payment request
 		midtransReq := &CreatePaymentRequest{
 			OrderID:         kodeInvoice,
-			GrossAmount:     req.HargaTotal,
+			GrossAmount:     int64(req.HargaTotal),
 			PaymentType:     paymentType,
 			CustomerDetails: customerDetails,
 			ItemDetails:     itemDetails,
@@ -222,9 +329,9 @@ func (s *trxService) CreateTRX(userID int, req *request.CreateTRXRequest) (*resp
 		// Call Midtrans service
 		paymentResp, err := s.midtransService.CreatePayment(midtransReq)
 		if err != nil {
-			// If payment creation fails, still return transaction but with error status
-			// Use UpdatePaymentStatus to avoid updating created_at
-			s.trxRepo.UpdatePaymentStatus(trx.ID, "failed", "", "", "", nil, "", "", "")
+			// If payment creation fails, return the stock and mark the
+			// transaction failed
+			failTRX(len(req.DetailTRX))
 			// Return more detailed error message
 			return nil, fmt.Errorf("failed to create payment with Midtrans: %w. Please check your Midtrans Server Key configuration", err)
 		}
@@ -339,6 +446,79 @@ func (s *trxService) CreateTRX(userID int, req *request.CreateTRXRequest) (*resp
 	return &trxResponse, nil
 }
 
+// checkoutItem is what a detail line buys: a product, or one of its variants.
+type checkoutItem struct {
+	variantID            *int
+	variantSKU           string
+	variantAtribut       map[string]string
+	logProdukID          *int
+	shopID               int
+	stok                 int
+	hargaKonsumen        int64
+	hargaReseller        int64
+	minKuantitasReseller int
+	name                 string
+	midtransID           string
+
+	// Chosen for the buyer at checkout
+	tierHarga   string
+	hargaSatuan int64
+}
+
+// price returns the price tier and unit price for a line of kuantitas items.
+// Resellers pay the reseller price once the line reaches the product's
+// minimum quantity.
+func (item checkoutItem) price(reseller bool, kuantitas int) (string, int64) {
+	if reseller && item.hargaReseller > 0 && kuantitas >= item.minKuantitasReseller {
+		return constants.PriceTierReseller, item.hargaReseller
+	}
+	return constants.PriceTierKonsumen, item.hargaKonsumen
+}
+
+func resolveCheckoutItem(product *model.Product, variantID *int) (checkoutItem, error) {
+	if len(product.Variants) == 0 {
+		if variantID != nil {
+			return checkoutItem{}, errors.New(constants.ErrVariantNotFound)
+		}
+		return checkoutItem{
+			stok:                 product.Stok,
+			hargaKonsumen:        product.HargaKonsumen,
+			hargaReseller:        product.HargaReseller,
+			minKuantitasReseller: product.MinKuantitasReseller,
+			name:                 product.NamaProduk,
+			midtransID:           fmt.Sprintf("product-%d", product.ID),
+		}, nil
+	}
+
+	if variantID == nil {
+		return checkoutItem{}, errors.New(constants.ErrVariantRequired)
+	}
+
+	for _, variant := range product.Variants {
+		if variant.ID != *variantID {
+			continue
+		}
+
+		var values []string
+		for _, value := range variant.OptionValues {
+			values = append(values, value.Nilai)
+		}
+		return checkoutItem{
+			variantID:            &variant.ID,
+			variantSKU:           variant.SKU,
+			variantAtribut:       variantAttributes(variant),
+			stok:                 variant.Stok,
+			hargaKonsumen:        variant.HargaKonsumen,
+			hargaReseller:        variant.HargaReseller,
+			minKuantitasReseller: product.MinKuantitasReseller,
+			name:                 fmt.Sprintf("%s (%s)", product.NamaProduk, strings.Join(values, ", ")),
+			midtransID:           fmt.Sprintf("variant-%d", variant.ID),
+		}, nil
+	}
+
+	return checkoutItem{}, errors.New(constants.ErrVariantNotFound)
+}
+
 func (s *trxService) generateInvoiceCode() string {
 	timestamp := time.Now().Unix()
 	return fmt.Sprintf("INV-%d", timestamp)
@@ -408,6 +588,10 @@ func (s *trxService) HandlePaymentWebhook(notification map[string]interface{}) e
 
 	// If status changed, send email and publish SSE event
 	if oldStatus != paymentStatusStr {
+		if paymentStatusStr == constants.PaymentStatusPaid {
+			s.recordPayment(trx)
+		}
+
 		// Get user email from transaction
 		user, err := s.userRepo.GetByID(trx.IDUser)
 		if err == nil {
@@ -498,6 +682,10 @@ func (s *trxService) CheckPaymentStatus(userID, trxID int) (*response.TRXRespons
 
 	// If status changed, send email and publish SSE event
 	if oldStatus != paymentStatusStr {
+		if paymentStatusStr == constants.PaymentStatusPaid {
+			s.recordPayment(trx)
+		}
+
 		user, err := s.userRepo.GetByID(trx.IDUser)
 		if err == nil {
 			go func() {
@@ -529,6 +717,46 @@ func (s *trxService) CheckPaymentStatus(userID, trxID int) (*response.TRXRespons
 	return &trxResponse, nil
 }
 
+// recordPayment notes when the order was paid and credits its shops. A
+// failure is logged; the sale is recorded again when the order completes.
+func (s *trxService) recordPayment(trx *model.TRX) {
+	if err := s.trxRepo.MarkPaid(trx.ID, time.Now()); err != nil {
+		log.Printf("[Ledger] Failed to mark transaction %d paid: %v", trx.ID, err)
+		return
+	}
+	if err := s.ledgerService.RecordSale(trx); err != nil {
+		log.Printf("[Ledger] Failed to record sale of transaction %d: %v", trx.ID, err)
+	}
+}
+
+func (s *trxService) CompleteTRX(userID, trxID int) (*response.TRXResponse, error) {
+	trx, err := s.trxRepo.GetByID(trxID)
+	if err != nil {
+		return nil, errors.New(constants.ErrTRXNotFound)
+	}
+	if trx.IDUser != userID {
+		return nil, errors.New(constants.ErrForbidden)
+	}
+	if trx.SelesaiAt != nil {
+		return nil, errors.New(constants.ErrTRXAlreadyCompleted)
+	}
+
+	completed, err := s.ledgerService.CompleteTRX(trx)
+	if err != nil {
+		return nil, err
+	}
+	if !completed {
+		return nil, errors.New(constants.ErrTRXAlreadyCompleted)
+	}
+
+	updatedTRX, err := s.trxRepo.GetByID(trxID)
+	if err != nil {
+		return nil, err
+	}
+	trxResponse := s.mapTRXToResponse(*updatedTRX)
+	return &trxResponse, nil
+}
+
 func (s *trxService) mapTRXToResponse(trx model.TRX) response.TRXResponse {
 	paymentVANumbers := deserializeVANumbersFromString(trx.PaymentVANumbers)
 	paymentActions := deserializeActionsFromString(trx.PaymentActions)
@@ -562,43 +790,7 @@ func (s *trxService) mapTRXToResponse(trx model.TRX) response.TRXResponse {
 	// Map detail transactions
 	var detailResponses []response.DetailTRXResponse
 	for _, detail := range trx.DetailTRX {
-		// Map product
-		productResponse := response.ProductResponse{
-			ID:            detail.Product.ID,
-			NamaProduk:    detail.Product.NamaProduk,
-			Slug:          detail.Product.Slug,
-			HargaReseller: detail.Product.HargaReseller,
-			HargaKonsumen: detail.Product.HargaKonsumen,
-			Stok:          detail.Product.Stok,
-			Deskripsi:     detail.Product.Deskripsi,
-			CreatedAt:     detail.Product.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:     detail.Product.UpdatedAt.Format("2006-01-02 15:04:05"),
-			IDToko:        detail.Product.IDToko,
-			IDCategory:    detail.Product.IDCategory,
-		}
-
-		// Map shop
-		shopResponse := response.ShopResponse{
-			ID:        detail.Shop.ID,
-			NamaToko:  detail.Shop.NamaToko,
-			URLToko:   detail.Shop.URLToko,
-			CreatedAt: detail.Shop.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: detail.Shop.UpdatedAt.Format("2006-01-02 15:04:05"),
-			IDUser:    detail.Shop.IDUser,
-		}
-
-		detailResponses = append(detailResponses, response.DetailTRXResponse{
-			ID:         detail.ID,
-			IDTRX:      detail.IDTRX,
-			IDProduk:   detail.IDProduk,
-			IDToko:     detail.IDToko,
-			Kuantitas:  detail.Kuantitas,
-			HargaTotal: detail.HargaTotal,
-			CreatedAt:  detail.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:  detail.UpdatedAt.Format("2006-01-02 15:04:05"),
-			Product:    productResponse,
-			Shop:       shopResponse,
-		})
+		detailResponses = append(detailResponses, mapDetailTRXToResponse(detail))
 	}
 
 	// Format payment expired at
@@ -606,6 +798,13 @@ func (s *trxService) mapTRXToResponse(trx model.TRX) response.TRXResponse {
 	if trx.PaymentExpiredAt != nil {
 		paymentExpiredAtStr = trx.PaymentExpiredAt.Format("2006-01-02 15:04:05")
 	}
+	var dibayarAt, selesaiAt string
+	if trx.DibayarAt != nil {
+		dibayarAt = trx.DibayarAt.Format("2006-01-02 15:04:05")
+	}
+	if trx.SelesaiAt != nil {
+		selesaiAt = trx.SelesaiAt.Format("2006-01-02 15:04:05")
+	}
 
 	return response.TRXResponse{
 		ID:               trx.ID,
@@ -618,6 +817,8 @@ func (s *trxService) mapTRXToResponse(trx model.TRX) response.TRXResponse {
 		PaymentVANumbers: paymentVANumbers,
 		PaymentActions:   paymentActions,
 		PaymentQRString:  paymentQRString,
+		DibayarAt:        dibayarAt,
+		SelesaiAt:        selesaiAt,
 		CreatedAt:        trx.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:        trx.UpdatedAt.Format("2006-01-02 15:04:05"),
 		IDUser:           trx.IDUser,
@@ -628,6 +829,115 @@ func (s *trxService) mapTRXToResponse(trx model.TRX) response.TRXResponse {
 	}
 }
 
+// mapDetailTRXToResponse maps an order line, showing the product as it was
+// when ordered if a snapshot was taken.
+func mapDetailTRXToResponse(detail model.DetailTRX) response.DetailTRXResponse {
+	// Map product
+	productResponse := response.ProductResponse{
+		ID:                   detail.Product.ID,
+		NamaProduk:           detail.Product.NamaProduk,
+		Slug:                 detail.Product.Slug,
+		HargaReseller:        detail.Product.HargaReseller,
+		HargaKonsumen:        detail.Product.HargaKonsumen,
+		MinKuantitasReseller: detail.Product.MinKuantitasReseller,
+		Stok:                 detail.Product.Stok,
+		Deskripsi:            detail.Product.Deskripsi,
+		CreatedAt:            detail.Product.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:            detail.Product.UpdatedAt.Format("2006-01-02 15:04:05"),
+		IDToko:               detail.Product.IDToko,
+		IDCategory:           detail.Product.IDCategory,
+	}
+	if detail.LogProduk != nil {
+		productResponse.NamaProduk = detail.LogProduk.NamaProduk
+		productResponse.Slug = detail.LogProduk.Slug
+		productResponse.HargaReseller = detail.LogProduk.HargaReseller
+		productResponse.HargaKonsumen = detail.LogProduk.HargaKonsumen
+		productResponse.MinKuantitasReseller = detail.LogProduk.MinKuantitasReseller
+		productResponse.Deskripsi = detail.LogProduk.Deskripsi
+		productResponse.IDToko = detail.LogProduk.IDToko
+		productResponse.IDCategory = detail.LogProduk.IDCategory
+	}
+
+	// Map shop
+	shopResponse := response.ShopResponse{
+		ID:        detail.Shop.ID,
+		NamaToko:  detail.Shop.NamaToko,
+		URLToko:   detail.Shop.URLToko,
+		CreatedAt: detail.Shop.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: detail.Shop.UpdatedAt.Format("2006-01-02 15:04:05"),
+		IDUser:    detail.Shop.IDUser,
+	}
+
+	// Map variant, as it was when ordered if it was recorded
+	var variantResponse *response.ProductVariantResponse
+	if detail.Variant != nil {
+		mapped := mapVariantToResponse(*detail.Variant)
+		if detail.VariantSKU != "" {
+			mapped.SKU = detail.VariantSKU
+			mapped.HargaReseller = detail.VariantHargaReseller
+			mapped.HargaKonsumen = detail.VariantHargaKonsumen
+			atribut := map[string]string{}
+			if err := json.Unmarshal([]byte(detail.VariantAtribut), &atribut); err == nil {
+				mapped.Atribut = atribut
+			}
+		}
+		variantResponse = &mapped
+	}
+
+	return response.DetailTRXResponse{
+		ID:          detail.ID,
+		IDTRX:       detail.IDTRX,
+		IDProduk:    detail.IDProduk,
+		IDVariant:   detail.IDVariant,
+		IDLogProduk: detail.IDLogProduk,
+		IDToko:      detail.IDToko,
+		Kuantitas:   detail.Kuantitas,
+		HargaSatuan: detail.HargaSatuan,
+		TierHarga:   detail.TierHarga,
+		HargaTotal:  detail.HargaTotal,
+		CreatedAt:   detail.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   detail.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Product:     productResponse,
+		Variant:     variantResponse,
+		Shop:        shopResponse,
+	}
+}
+
+// mapShopTRXToResponse maps an order for one of its shops, whose lines are
+// the only ones loaded.
+func mapShopTRXToResponse(trx model.TRX) response.ShopTRXResponse {
+	var subtotal int64
+	detailResponses := make([]response.DetailTRXResponse, 0, len(trx.DetailTRX))
+	for _, detail := range trx.DetailTRX {
+		subtotal += detail.HargaTotal
+		detailResponses = append(detailResponses, mapDetailTRXToResponse(detail))
+	}
+
+	var dibayarAt, selesaiAt string
+	if trx.DibayarAt != nil {
+		dibayarAt = trx.DibayarAt.Format("2006-01-02 15:04:05")
+	}
+	if trx.SelesaiAt != nil {
+		selesaiAt = trx.SelesaiAt.Format("2006-01-02 15:04:05")
+	}
+
+	return response.ShopTRXResponse{
+		ID:            trx.ID,
+		KodeInvoice:   trx.KodeInvoice,
+		PaymentStatus: trx.PaymentStatus,
+		DibayarAt:     dibayarAt,
+		SelesaiAt:     selesaiAt,
+		CreatedAt:     trx.CreatedAt.Format("2006-01-02 15:04:05"),
+		Subtotal:      subtotal,
+		Pengiriman: response.ShopTRXShipping{
+			NamaPenerima: trx.Address.NamaPenerima,
+			NoTelp:       trx.Address.NoTelp,
+			DetailAlamat: trx.Address.DetailAlamat,
+		},
+		DetailTRX: detailResponses,
+	}
+}
+
 func (s *trxService) attachVANumbersIfNeeded(trx *model.TRX, trxResponse *response.TRXResponse) {
 	if trx == nil || trxResponse == nil {
 		return
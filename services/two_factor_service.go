@@ -0,0 +1,352 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/request"
+	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
+	"github.com/rdsarjito/marketplace-backend/domain/model"
+	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/utils"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorService interface {
+	GetStatus(userID int) (*response.TwoFactorStatusResponse, error)
+	Setup(userID int) (*response.TwoFactorSetupResponse, error)
+	Enable(userID int, req *request.EnableTwoFactorRequest) (*response.RecoveryCodesResponse, error)
+	Disable(userID int, req *request.DisableTwoFactorRequest) error
+	RegenerateRecoveryCodes(userID int, req *request.RegenerateRecoveryCodesRequest) (*response.RecoveryCodesResponse, error)
+	VerifyLogin(req *request.VerifyTwoFactorRequest) (*response.AuthResponse, error)
+	// CompleteLogin is called once the first factor (password, OTP, OAuth) has
+	// been verified. It returns a challenge instead of a token when 2FA is enabled.
+	CompleteLogin(user *model.User) (*response.AuthResponse, error)
+}
+
+type twoFactorService struct {
+	twoFactorRepo        repositories.TwoFactorRepository
+	userRepo             repositories.UserRepository
+	roleRepo             repositories.RoleRepository
+	loginThrottleService LoginThrottleService
+	issuer               string
+}
+
+func NewTwoFactorService(twoFactorRepo repositories.TwoFactorRepository, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, loginThrottleService LoginThrottleService) TwoFactorService {
+	return &twoFactorService{
+		twoFactorRepo:        twoFactorRepo,
+		userRepo:             userRepo,
+		roleRepo:             roleRepo,
+		loginThrottleService: loginThrottleService,
+		issuer:               getEnv("TOTP_ISSUER", "Warung Budeh Ramah"),
+	}
+}
+
+func (s *twoFactorService) GetStatus(userID int) (*response.TwoFactorStatusResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New(constants.ErrUserNotFound)
+	}
+
+	status := &response.TwoFactorStatusResponse{
+		Required: s.requiresTwoFactor(user),
+	}
+
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil || twoFactor.EnabledAt == nil {
+		return status, nil
+	}
+
+	remaining, err := s.twoFactorRepo.CountUnusedRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Enabled = true
+	status.EnabledAt = twoFactor.EnabledAt.Format("2006-01-02 15:04:05")
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+func (s *twoFactorService) Setup(userID int) (*response.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New(constants.ErrUserNotFound)
+	}
+
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if err == nil && twoFactor.EnabledAt != nil {
+		return nil, errors.New(constants.ErrTwoFactorAlreadyEnabled)
+	}
+	if err != nil {
+		twoFactor = &model.UserTwoFactor{IDUser: userID}
+	}
+
+	// Every setup call starts a fresh, unconfirmed enrollment
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := utils.EncryptSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor.SecretEncrypted = encrypted
+	twoFactor.LastUsedStep = 0
+	if err := s.twoFactorRepo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+
+	uri := utils.BuildOTPAuthURI(s.issuer, user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+func (s *twoFactorService) Enable(userID int, req *request.EnableTwoFactorRequest) (*response.RecoveryCodesResponse, error) {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, errors.New(constants.ErrTwoFactorNotSetup)
+	}
+	if twoFactor.EnabledAt != nil {
+		return nil, errors.New(constants.ErrTwoFactorAlreadyEnabled)
+	}
+
+	step, err := s.checkCode(twoFactor, req.Kode)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	twoFactor.EnabledAt = &now
+	twoFactor.LastUsedStep = step
+	if err := s.twoFactorRepo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(userID)
+}
+
+func (s *twoFactorService) Disable(userID int, req *request.DisableTwoFactorRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New(constants.ErrUserNotFound)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.KataSandi), []byte(req.KataSandi)); err != nil {
+		return errors.New(constants.ErrInvalidCredentials)
+	}
+
+	twoFactor, err := s.enabledTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.consumeCode(twoFactor, req.Kode); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepo.Delete(userID)
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(userID int, req *request.RegenerateRecoveryCodesRequest) (*response.RecoveryCodesResponse, error) {
+	twoFactor, err := s.enabledTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.consumeCode(twoFactor, req.Kode); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(userID)
+}
+
+func (s *twoFactorService) VerifyLogin(req *request.VerifyTwoFactorRequest) (*response.AuthResponse, error) {
+	claims, err := utils.ValidateChallengeToken(req.ChallengeToken)
+	if err != nil {
+		return nil, errors.New(constants.ErrInvalidToken)
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, errors.New(constants.ErrUserNotFound)
+	}
+
+	twoFactor, err := s.enabledTwoFactor(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loginThrottleService.CheckTwoFactor(user.ID, claims.ID); err != nil {
+		return nil, err
+	}
+
+	if req.Kode != "" {
+		_, err = s.consumeCode(twoFactor, req.Kode)
+	} else {
+		var used bool
+		used, err = s.twoFactorRepo.UseRecoveryCode(user.ID, hashRecoveryCode(req.RecoveryCode))
+		if err == nil && !used {
+			err = errors.New(constants.ErrInvalidTwoFactorCode)
+		}
+	}
+	if err != nil {
+		if err.Error() == constants.ErrInvalidTwoFactorCode {
+			if recordErr := s.loginThrottleService.RecordTwoFactorFailure(user.ID, claims.ID); recordErr != nil {
+				return nil, recordErr
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.loginThrottleService.CompleteTwoFactor(user.ID, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateTwoFactorToken(user.ID, user.IsAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.AuthResponse{
+		Token: token,
+		User:  mapUserToProfile(user),
+	}, nil
+}
+
+func (s *twoFactorService) CompleteLogin(user *model.User) (*response.AuthResponse, error) {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(user.ID)
+	enabled := err == nil && twoFactor.EnabledAt != nil
+
+	if enabled {
+		challengeToken, err := utils.GenerateChallengeToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &response.AuthResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		}, nil
+	}
+
+	// Admin privileges, and roles that require 2FA, are only granted once the
+	// user has enrolled, so without it the token never carries them
+	token, err := utils.GenerateToken(user.ID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.AuthResponse{
+		Token:                  token,
+		User:                   mapUserToProfile(user),
+		TwoFactorSetupRequired: s.requiresTwoFactor(user),
+	}, nil
+}
+
+// requiresTwoFactor reports whether the user holds a role that is only
+// granted to sessions that completed 2FA.
+func (s *twoFactorService) requiresTwoFactor(user *model.User) bool {
+	if user.IsAdmin {
+		return true
+	}
+
+	roles, err := s.roleRepo.GetByUserID(user.ID)
+	if err != nil {
+		return false
+	}
+	for _, role := range roles {
+		if role.RequiresTwoFactor {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *twoFactorService) enabledTwoFactor(userID int) (*model.UserTwoFactor, error) {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil || twoFactor.EnabledAt == nil {
+		return nil, errors.New(constants.ErrTwoFactorNotEnabled)
+	}
+	return twoFactor, nil
+}
+
+// checkCode validates a TOTP code against the stored secret.
+func (s *twoFactorService) checkCode(twoFactor *model.UserTwoFactor, code string) (int64, error) {
+	secret, err := utils.DecryptSecret(twoFactor.SecretEncrypted)
+	if err != nil {
+		return 0, err
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return 0, errors.New(constants.ErrInvalidTwoFactorCode)
+	}
+	return step, nil
+}
+
+// consumeCode validates a TOTP code and records its time step so it can't be replayed.
+func (s *twoFactorService) consumeCode(twoFactor *model.UserTwoFactor, code string) (int64, error) {
+	step, err := s.checkCode(twoFactor, code)
+	if err != nil {
+		return 0, err
+	}
+
+	fresh, err := s.twoFactorRepo.UpdateLastUsedStep(twoFactor.IDUser, step)
+	if err != nil {
+		return 0, err
+	}
+	if !fresh {
+		return 0, errors.New(constants.ErrInvalidTwoFactorCode)
+	}
+	return step, nil
+}
+
+func (s *twoFactorService) issueRecoveryCodes(userID int) (*response.RecoveryCodesResponse, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]model.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		encoded := hex.EncodeToString(raw)
+		code := encoded[:5] + "-" + encoded[5:]
+
+		codes = append(codes, code)
+		records = append(records, model.RecoveryCode{
+			IDUser:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, records); err != nil {
+		return nil, err
+	}
+
+	return &response.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// hashRecoveryCode normalizes user input (case, separators) before hashing.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
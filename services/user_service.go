@@ -9,6 +9,7 @@ import (
 	"github.com/rdsarjito/marketplace-backend/domain/dto/response"
 	"github.com/rdsarjito/marketplace-backend/domain/model"
 	"github.com/rdsarjito/marketplace-backend/repositories"
+	"github.com/rdsarjito/marketplace-backend/utils"
 )
 
 type UserService interface {
@@ -39,22 +40,9 @@ func (s *userService) GetMyProfile(userID int) (*response.UserProfile, error) {
 		return nil, errors.New(constants.ErrUserNotFound)
 	}
 
-	userProfile := &response.UserProfile{
-		ID:            user.ID,
-		Nama:          user.Nama,
-		NoTelp:        user.NoTelp,
-		TanggalLahir:  user.TanggalLahir.Format("2006-01-02"),
-		JenisKelamin:  user.JenisKelamin,
-		Tentang:       user.Tentang,
-		Pekerjaan:     user.Pekerjaan,
-		Email:         user.Email,
-		IDProvinsi:    user.IDProvinsi,
-		IDKota:        user.IDKota,
-		PhotoURL:      user.PhotoURL,
-		IsAdmin:       user.IsAdmin,
-	}
+	userProfile := mapUserToProfile(user)
 
-	return userProfile, nil
+	return &userProfile, nil
 }
 
 func (s *userService) UpdateProfile(userID int, req *request.UpdateProfileRequest) (*response.UserProfile, error) {
@@ -68,7 +56,18 @@ func (s *userService) UpdateProfile(userID int, req *request.UpdateProfileReques
 		user.Nama = req.Nama
 	}
 	if req.NoTelp != "" {
-		user.NoTelp = req.NoTelp
+		noTelp, err := utils.NormalizePhoneNumber(req.NoTelp)
+		if err != nil {
+			return nil, errors.New(constants.ErrInvalidPhone)
+		}
+		if noTelp != user.NoTelp {
+			if existingUser, _ := s.userRepo.GetByPhone(noTelp); existingUser != nil && existingUser.ID != user.ID {
+				return nil, errors.New(constants.ErrPhoneAlreadyExists)
+			}
+			// A new number has to be verified again
+			user.NoTelp = noTelp
+			user.NoTelpVerifiedAt = nil
+		}
 	}
 	if req.TanggalLahir != "" {
 		tanggalLahir, err := time.Parse("2006-01-02", req.TanggalLahir)
@@ -86,8 +85,9 @@ func (s *userService) UpdateProfile(userID int, req *request.UpdateProfileReques
 	if req.Pekerjaan != "" {
 		user.Pekerjaan = req.Pekerjaan
 	}
-	if req.Email != "" {
+	if req.Email != "" && req.Email != user.Email {
 		user.Email = req.Email
+		user.EmailVerifiedAt = nil
 	}
 	if req.IDProvinsi != "" {
 		user.IDProvinsi = req.IDProvinsi
@@ -100,22 +100,9 @@ func (s *userService) UpdateProfile(userID int, req *request.UpdateProfileReques
 		return nil, err
 	}
 
-	userProfile := &response.UserProfile{
-		ID:            user.ID,
-		Nama:          user.Nama,
-		NoTelp:        user.NoTelp,
-		TanggalLahir:  user.TanggalLahir.Format("2006-01-02"),
-		JenisKelamin:  user.JenisKelamin,
-		Tentang:       user.Tentang,
-		Pekerjaan:     user.Pekerjaan,
-		Email:         user.Email,
-		IDProvinsi:    user.IDProvinsi,
-		IDKota:        user.IDKota,
-		PhotoURL:      user.PhotoURL,
-		IsAdmin:       user.IsAdmin,
-	}
+	userProfile := mapUserToProfile(user)
 
-	return userProfile, nil
+	return &userProfile, nil
 }
 
 func (s *userService) GetMyAddress(userID int) ([]response.AddressResponse, error) {
@@ -233,3 +220,23 @@ func (s *userService) DeleteAddressUser(userID, addressID int) error {
 
 	return s.addressRepo.Delete(addressID)
 }
+
+// mapUserToProfile maps a user model to the profile returned by the API.
+func mapUserToProfile(user *model.User) response.UserProfile {
+	return response.UserProfile{
+		ID:             user.ID,
+		Nama:           user.Nama,
+		NoTelp:         user.NoTelp,
+		NoTelpVerified: user.NoTelpVerifiedAt != nil,
+		TanggalLahir:   user.TanggalLahir.Format("2006-01-02"),
+		JenisKelamin:   user.JenisKelamin,
+		Tentang:        user.Tentang,
+		Pekerjaan:      user.Pekerjaan,
+		Email:          user.Email,
+		EmailVerified:  user.EmailVerifiedAt != nil,
+		IDProvinsi:     user.IDProvinsi,
+		IDKota:         user.IDKota,
+		PhotoURL:       user.PhotoURL,
+		IsAdmin:        user.IsAdmin,
+	}
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// localStorage keeps objects as files under a directory. They are served by
+// the API's /media route, so it needs no other server, but it cannot issue
+// presigned uploads.
+type localStorage struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStorage stores objects under root, creating it if needed.
+func NewLocalStorage(root, baseURL string) (MediaStorage, error) {
+	root = filepath.Clean(root)
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &localStorage{root: root, baseURL: baseURL}, nil
+}
+
+func (s *localStorage) Upload(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) (string, error) {
+	filePath, err := s.path(objectName)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return "", err
+	}
+
+	return objectURL(s.baseURL, objectName), nil
+}
+
+func (s *localStorage) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	return s.open(objectName)
+}
+
+func (s *localStorage) GetObjectRange(ctx context.Context, objectName string, offset, length int64) (io.ReadCloser, error) {
+	file, err := s.open(objectName)
+	if err != nil {
+		return nil, err
+	}
+	return &sectionReadCloser{
+		Reader: io.NewSectionReader(file, offset, length),
+		Closer: file,
+	}, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, objectName string) error {
+	filePath, err := s.path(objectName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// Remove directories left empty, up to the root. os.Remove fails on
+	// directories that still have files, which ends the loop.
+	for dir := filepath.Dir(filePath); dir != s.root && len(dir) > len(s.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *localStorage) Stat(ctx context.Context, objectName string) (*ObjectInfo, error) {
+	filePath, err := s.path(objectName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filePath)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, err := detectContentType(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{
+		Name:        objectName,
+		Size:        info.Size(),
+		ContentType: contentType,
+		// Files are only ever replaced as a whole, so their size and
+		// modification time are enough to tell versions apart
+		ETag:         fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+func (s *localStorage) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	// Walk the deepest directory the prefix names completely, then match
+	// the rest of it against object names
+	dir := s.root
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		var err error
+		if dir, err = s.path(prefix[:i]); err != nil {
+			return err
+		}
+	}
+
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Skip directories and uploads still being written
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return fn(ObjectInfo{Name: name, Size: info.Size(), LastModified: info.ModTime()})
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *localStorage) PresignUpload(ctx context.Context, objectName, contentType string, size int64, expiry time.Duration) (*PresignedUpload, error) {
+	return nil, ErrPresignNotSupported
+}
+
+func (s *localStorage) open(objectName string) (*os.File, error) {
+	filePath, err := s.path(objectName)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return file, err
+}
+
+type sectionReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// path maps an object name to a file under the root. Names are cleaned as
+// absolute paths first so ".." cannot climb out of it.
+func (s *localStorage) path(objectName string) (string, error) {
+	name := path.Clean("/" + objectName)
+	if name == "/" {
+		return "", ErrObjectNotFound
+	}
+	return filepath.Join(s.root, filepath.FromSlash(name)), nil
+}
+
+// detectContentType goes by the file extension, or sniffs the content when
+// the extension is unknown.
+func detectContentType(filePath string) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(filePath)); contentType != "" {
+		return contentType, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
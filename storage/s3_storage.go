@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config configures an S3-compatible bucket. Without AccessKey and
+// SecretKey, credentials are taken from the standard AWS environment
+// variables, the shared credentials file or the instance's IAM role.
+type S3Config struct {
+	Endpoint     string // host[:port], optionally with an http(s):// scheme
+	Secure       bool
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	PathStyle    bool // address the bucket as endpoint/bucket instead of bucket.endpoint
+	CreateBucket bool // create the bucket if it does not exist
+	BaseURL      string
+}
+
+type s3Storage struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+// NewS3Storage connects to an S3-compatible bucket.
+func NewS3Storage(cfg S3Config) (MediaStorage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("s3 storage needs an endpoint and a bucket")
+	}
+
+	creds := credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, "")
+	if cfg.AccessKey == "" && cfg.SecretKey == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{},
+		})
+	}
+
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        creds,
+		Secure:       cfg.Secure,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if !cfg.CreateBucket {
+			return nil, fmt.Errorf("bucket %q does not exist", cfg.Bucket)
+		}
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &s3Storage{
+		client:  client,
+		bucket:  cfg.Bucket,
+		baseURL: cfg.BaseURL,
+	}, nil
+}
+
+// NewS3StorageFromEnv initializes an S3-backed MediaStorage using the S3_*
+// environment variables. The endpoint defaults to AWS S3.
+func NewS3StorageFromEnv() (MediaStorage, error) {
+	endpoint := strings.TrimSpace(os.Getenv("S3_ENDPOINT"))
+	if endpoint == "" {
+		endpoint = "https://s3.amazonaws.com"
+	}
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		return nil, errors.New("s3 storage is not configured (check S3_* envs)")
+	}
+
+	parsed, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	// Unlike MinIO, S3 endpoints without a scheme use HTTPS unless
+	// S3_USE_SSL says otherwise.
+	if parsed.scheme == "" {
+		parsed.secure = !strings.EqualFold(os.Getenv("S3_USE_SSL"), "false")
+	}
+
+	return NewS3Storage(S3Config{
+		Endpoint:  parsed.host,
+		Secure:    parsed.secure,
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    bucket,
+		AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		PathStyle: strings.EqualFold(os.Getenv("S3_FORCE_PATH_STYLE"), "true"),
+		BaseURL:   mediaBaseURL(),
+	})
+}
+
+// NewMinioStorageFromEnv initializes a MinIO-backed MediaStorage using environment variables.
+func NewMinioStorageFromEnv() (MediaStorage, error) {
+	endpoint := strings.TrimSpace(os.Getenv("MINIO_ENDPOINT"))
+	accessKey := os.Getenv("MINIO_ACCESS_KEY")
+	secretKey := os.Getenv("MINIO_SECRET_KEY")
+	bucket := os.Getenv("MINIO_BUCKET_NAME")
+	useSSL := strings.EqualFold(os.Getenv("MINIO_USE_SSL"), "true")
+
+	if endpoint == "" || accessKey == "" || secretKey == "" || bucket == "" {
+		return nil, errors.New("minio storage is not configured (check MINIO_* envs)")
+	}
+
+	parsed, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer scheme from URL unless explicitly overridden via MINIO_USE_SSL.
+	if !strings.EqualFold(os.Getenv("MINIO_USE_SSL"), "") {
+		parsed.secure = useSSL
+	}
+
+	return NewS3Storage(S3Config{
+		Endpoint:     parsed.host,
+		Secure:       parsed.secure,
+		Bucket:       bucket,
+		AccessKey:    accessKey,
+		SecretKey:    secretKey,
+		PathStyle:    true,
+		CreateBucket: true,
+		BaseURL:      mediaBaseURL(),
+	})
+}
+
+func (s *s3Storage) Upload(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) (string, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	_, err := s.client.PutObject(ctx, s.bucket, objectName, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return objectURL(s.baseURL, objectName), nil
+}
+
+func (s *s3Storage) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *s3Storage) GetObjectRange(ctx context.Context, objectName string, offset, length int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(offset, offset+length-1); err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, objectName, opts)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, objectName string) error {
+	return s.client.RemoveObject(ctx, s.bucket, objectName, minio.RemoveObjectOptions{})
+}
+
+func (s *s3Storage) Stat(ctx context.Context, objectName string) (*ObjectInfo, error) {
+	objInfo, err := s.client.StatObject(ctx, s.bucket, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	return &ObjectInfo{
+		Name:         objectName,
+		Size:         objInfo.Size,
+		ContentType:  objInfo.ContentType,
+		ETag:         strings.Trim(objInfo.ETag, `"`),
+		LastModified: objInfo.LastModified,
+	}, nil
+}
+
+func (s *s3Storage) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	// Cancelling stops the listing if fn fails part way
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+		err := fn(ObjectInfo{
+			Name:         obj.Key,
+			Size:         obj.Size,
+			ETag:         strings.Trim(obj.ETag, `"`),
+			LastModified: obj.LastModified,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *s3Storage) PresignUpload(ctx context.Context, objectName, contentType string, size int64, expiry time.Duration) (*PresignedUpload, error) {
+	// Signing the headers makes storage reject uploads of another type or size
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+	headers.Set("Content-Length", strconv.FormatInt(size, 10))
+
+	expiresAt := time.Now().Add(expiry)
+	u, err := s.client.PresignHeader(ctx, http.MethodPut, s.bucket, objectName, expiry, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresignedUpload{
+		URL:    u.String(),
+		Method: http.MethodPut,
+		Headers: map[string]string{
+			"Content-Type":   contentType,
+			"Content-Length": strconv.FormatInt(size, 10),
+		},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+type endpointInfo struct {
+	host   string
+	scheme string
+	secure bool
+}
+
+func parseEndpoint(raw string) (endpointInfo, error) {
+	raw = strings.TrimSpace(raw)
+	info := endpointInfo{}
+
+	if raw == "" {
+		return info, errors.New("empty storage endpoint")
+	}
+
+	if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return info, err
+		}
+		info.host = u.Host
+		info.scheme = u.Scheme
+		info.secure = u.Scheme == "https"
+		return info, nil
+	}
+
+	info.host = raw
+	info.secure = false
+	return info, nil
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Supported MEDIA_STORAGE values
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+	BackendMinio = "minio"
+)
+
+const defaultLocalMediaPath = "data/media"
+
+// MediaStorage defines the capability required by handlers/services to store media assets.
+type MediaStorage interface {
+	// Upload stores an object and returns its public URL.
+	Upload(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) (string, error)
+	// GetObject opens an object for reading. The caller must close it.
+	GetObject(ctx context.Context, objectName string) (io.ReadCloser, error)
+	// GetObjectRange opens length bytes of an object starting at offset.
+	// The range must lie within the object. The caller must close it.
+	GetObjectRange(ctx context.Context, objectName string, offset, length int64) (io.ReadCloser, error)
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, objectName string) error
+	// Stat returns the metadata of a stored object.
+	Stat(ctx context.Context, objectName string) (*ObjectInfo, error)
+	// List calls fn for every object whose name starts with prefix, in no
+	// particular order, and stops at the first error fn returns. Objects
+	// only have their name, size and modification time.
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+	// PresignUpload returns a URL the client can PUT exactly size bytes of
+	// contentType to, without going through the API. Backends that cannot
+	// do this return ErrPresignNotSupported.
+	PresignUpload(ctx context.Context, objectName, contentType string, size int64, expiry time.Duration) (*PresignedUpload, error)
+}
+
+var (
+	// ErrObjectNotFound is returned when there is no object by that name.
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrPresignNotSupported is returned by backends without direct uploads.
+	ErrPresignNotSupported = errors.New("presigned uploads are not supported by this storage")
+)
+
+// ObjectInfo describes a stored object. ETag identifies its content and
+// changes whenever it is replaced; it is given without quotes.
+type ObjectInfo struct {
+	Name         string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// PresignedUpload is a signed request for uploading one object directly to
+// storage. The client must send Headers unchanged.
+type PresignedUpload struct {
+	URL       string
+	Method    string
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
+
+// NewMediaStorageFromEnv initializes the MediaStorage selected by
+// MEDIA_STORAGE. Without it, MinIO is used if MINIO_ENDPOINT is set and the
+// local filesystem otherwise.
+func NewMediaStorageFromEnv() (MediaStorage, error) {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("MEDIA_STORAGE")))
+	if backend == "" {
+		backend = BackendLocal
+		if strings.TrimSpace(os.Getenv("MINIO_ENDPOINT")) != "" {
+			backend = BackendMinio
+		}
+	}
+
+	switch backend {
+	case BackendLocal:
+		path := strings.TrimSpace(os.Getenv("MEDIA_LOCAL_PATH"))
+		if path == "" {
+			path = defaultLocalMediaPath
+		}
+		return NewLocalStorage(path, mediaBaseURL())
+	case BackendS3:
+		return NewS3StorageFromEnv()
+	case BackendMinio:
+		return NewMinioStorageFromEnv()
+	default:
+		return nil, fmt.Errorf("unknown MEDIA_STORAGE %q (use %s, %s or %s)", backend, BackendLocal, BackendS3, BackendMinio)
+	}
+}
+
+// mediaBaseURL is where uploaded objects are served from. If ASSET_BASE_URL
+// points at an API route it is used directly; otherwise objects go through
+// the API's /media route, which streams them from storage.
+func mediaBaseURL() string {
+	baseURL := strings.TrimRight(os.Getenv("ASSET_BASE_URL"), "/")
+	if baseURL != "" && (strings.Contains(baseURL, "/api/") || strings.Contains(baseURL, "/media")) {
+		return baseURL
+	}
+
+	apiBaseURL := os.Getenv("API_BASE_URL")
+	if apiBaseURL == "" {
+		apiBaseURL = "https://api.warungbudehramah.app"
+	}
+	return strings.TrimRight(apiBaseURL, "/") + "/media"
+}
+
+// ObjectURL returns the URL an object is served from by the API.
+func ObjectURL(objectName string) string {
+	return objectURL(mediaBaseURL(), objectName)
+}
+
+func objectURL(baseURL, objectName string) string {
+	return fmt.Sprintf("%s/%s", baseURL, strings.TrimLeft(objectName, "/"))
+}
+
+// IsCleanObjectName reports whether objectName is a relative slash-separated
+// name with no empty, "." or ".." segments. Storage backends normalize names,
+// so prefix checks on any other name may not match the object it reaches.
+func IsCleanObjectName(objectName string) bool {
+	if objectName == "" || strings.HasPrefix(objectName, "/") || strings.Contains(objectName, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(objectName, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return path.Clean(objectName) == objectName
+}
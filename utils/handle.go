@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"github.com/rdsarjito/marketplace-backend/constants"
+)
+
+// ValidShopHandle reports whether handle can identify a shop: 3 to 30
+// lowercase letters, digits and single hyphens, not only digits, so it is
+// never mistaken for a shop ID, and not a reserved word.
+func ValidShopHandle(handle string) bool {
+	if len(handle) < constants.ShopHandleMinLength || len(handle) > constants.ShopHandleMaxLength {
+		return false
+	}
+	if slug.Make(handle) != handle || strings.Trim(handle, "0123456789") == "" {
+		return false
+	}
+	return !slices.Contains(constants.ReservedShopHandles, handle)
+}
+
+// ShopHandleFromName makes a valid handle from a shop name, e.g. "Toko
+// Kopi Nusantara" becomes "toko-kopi-nusantara". Names that give a handle
+// that is too short, numeric or reserved are prefixed with "toko-".
+func ShopHandleFromName(name string) string {
+	handle := truncateHandle(slug.Make(name), constants.ShopHandleMaxLength)
+	if !ValidShopHandle(handle) {
+		handle = truncateHandle("toko-"+handle, constants.ShopHandleMaxLength)
+	}
+	return handle
+}
+
+// NumberedShopHandle returns handle with the suffix -n, shortened if needed
+// to stay within the maximum length.
+func NumberedShopHandle(handle string, n int) string {
+	suffix := "-" + strconv.Itoa(n)
+	return truncateHandle(handle, constants.ShopHandleMaxLength-len(suffix)) + suffix
+}
+
+func truncateHandle(handle string, maxLength int) string {
+	if len(handle) > maxLength {
+		handle = handle[:maxLength]
+	}
+	return strings.Trim(handle, "-")
+}
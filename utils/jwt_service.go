@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -8,21 +10,44 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Purpose-bound tokens are short-lived and rejected by ValidateToken.
+const (
+	// TokenPurposeTwoFactor only allows completing a 2FA login
+	TokenPurposeTwoFactor = "2fa_challenge"
+)
+
+// ChallengeTokenTTL is how long a 2FA challenge can be completed
+const ChallengeTokenTTL = 5 * time.Minute
+
 type JWTClaims struct {
-	UserID  int  `json:"user_id"`
-	IsAdmin bool `json:"is_admin"`
+	UserID  int    `json:"user_id"`
+	IsAdmin bool   `json:"is_admin"`
+	Purpose string `json:"purpose,omitempty"`
+	// TwoFactor is set when the session completed a second login factor
+	TwoFactor bool `json:"two_factor,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func GenerateToken(userID int, isAdmin bool) (string, error) {
+	return generateAccessToken(userID, isAdmin, false)
+}
+
+// GenerateTwoFactorToken issues an access token for a session that completed
+// 2FA. Roles that require 2FA only grant their permissions to such tokens.
+func GenerateTwoFactorToken(userID int, isAdmin bool) (string, error) {
+	return generateAccessToken(userID, isAdmin, true)
+}
+
+func generateAccessToken(userID int, isAdmin, twoFactor bool) (string, error) {
 	secretKey := os.Getenv("SECRET_KEY")
 	if secretKey == "" {
 		return "", errors.New("SECRET_KEY not found")
 	}
 
 	claims := JWTClaims{
-		UserID:  userID,
-		IsAdmin: isAdmin,
+		UserID:    userID,
+		IsAdmin:   isAdmin,
+		TwoFactor: twoFactor,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(30 * time.Minute)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -33,7 +58,67 @@ func GenerateToken(userID int, isAdmin bool) (string, error) {
 	return token.SignedString([]byte(secretKey))
 }
 
+// GenerateChallengeToken issues a short-lived token proving the password step
+// of a login succeeded. It cannot be used as an access token. Its ID lets
+// failed attempts be counted per challenge and the challenge be used once.
+func GenerateChallengeToken(userID int) (string, error) {
+	return generatePurposeToken(userID, TokenPurposeTwoFactor, ChallengeTokenTTL)
+}
+
+// ValidateChallengeToken validates a token created by GenerateChallengeToken.
+func ValidateChallengeToken(tokenString string) (*JWTClaims, error) {
+	return validatePurposeToken(tokenString, TokenPurposeTwoFactor)
+}
+
+func generatePurposeToken(userID int, purpose string, ttl time.Duration) (string, error) {
+	secretKey := os.Getenv("SECRET_KEY")
+	if secretKey == "" {
+		return "", errors.New("SECRET_KEY not found")
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	claims := JWTClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secretKey))
+}
+
+func validatePurposeToken(tokenString, purpose string) (*JWTClaims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose || claims.ID == "" {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// ValidateToken validates an access token. Purpose-bound tokens are rejected.
 func ValidateToken(tokenString string) (*JWTClaims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func parseToken(tokenString string) (*JWTClaims, error) {
 	secretKey := os.Getenv("SECRET_KEY")
 	if secretKey == "" {
 		return nil, errors.New("SECRET_KEY not found")
@@ -41,7 +126,7 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(secretKey), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
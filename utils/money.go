@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rupiah is an amount of money in whole rupiah. In JSON it is written as a
+// number and read from either a number or a formatted string such as
+// "15.000" or "Rp 1.500.000".
+type Rupiah int64
+
+// ParseRupiah parses an amount written with or without thousands separators
+// and an optional "Rp"/"IDR" prefix: "15000", "15.000", "15,000",
+// "Rp 15.000,00" and "15000.00" all give 15000. Fractions of a rupiah and
+// negative amounts are rejected.
+func ParseRupiah(raw string) (Rupiah, error) {
+	s := strings.TrimSpace(raw)
+	upper := strings.ToUpper(s)
+	for _, prefix := range []string{"RP.", "RP", "IDR"} {
+		if strings.HasPrefix(upper, prefix) {
+			s = s[len(prefix):]
+			break
+		}
+	}
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+
+	whole, fraction, ok := splitDecimal(s)
+	if !ok || !isDigits(whole) || (fraction != "" && !isDigits(fraction)) {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	if strings.Trim(fraction, "0") != "" {
+		return 0, fmt.Errorf("amount %q has a fraction of a rupiah", raw)
+	}
+
+	amount, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	return Rupiah(amount), nil
+}
+
+// splitDecimal separates the whole part of s, with thousands separators
+// removed, from its decimal part. The last separator is a decimal separator
+// when "." and "," are both used, or when it appears once and is followed by
+// one or two digits; any other separator must group exactly three digits.
+func splitDecimal(s string) (whole, fraction string, ok bool) {
+	whole = s
+	if last := strings.LastIndexAny(s, ".,"); last >= 0 {
+		tail := s[last+1:]
+		bothUsed := strings.Contains(s, ".") && strings.Contains(s, ",")
+		if bothUsed || (strings.Count(s, s[last:last+1]) == 1 && len(tail) > 0 && len(tail) <= 2) {
+			whole, fraction = s[:last], tail
+		}
+	}
+
+	groups := strings.FieldsFunc(whole, func(r rune) bool { return r == '.' || r == ',' })
+	if len(groups) != strings.Count(whole, ".")+strings.Count(whole, ",")+1 {
+		return "", "", false
+	}
+	if len(groups) > 1 {
+		if len(groups[0]) > 3 {
+			return "", "", false
+		}
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return "", "", false
+			}
+		}
+	}
+	return strings.Join(groups, ""), fraction, true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatRupiah formats an amount the Indonesian way, e.g. "Rp 1.500.000".
+func FormatRupiah(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	var b strings.Builder
+	for i, char := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(char)
+	}
+	return sign + "Rp " + b.String()
+}
+
+func (r Rupiah) String() string {
+	return FormatRupiah(int64(r))
+}
+
+func (r *Rupiah) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		// A JSON number: "." is always its decimal point, so don't let
+		// ParseRupiah read 1.500 as fifteen hundred
+		whole, fraction, _ := strings.Cut(string(data), ".")
+		if !isDigits(whole) || (fraction != "" && strings.Trim(fraction, "0") != "") {
+			return fmt.Errorf("invalid amount %s", data)
+		}
+		text = whole
+	}
+
+	amount, err := ParseRupiah(text)
+	if err != nil {
+		return err
+	}
+	*r = amount
+	return nil
+}
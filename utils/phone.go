@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// NormalizePhoneNumber converts an Indonesian phone number to E.164 format
+// (e.g. "0812-3456-7890" becomes "+6281234567890"). Numbers that already
+// carry a country code are kept as-is after stripping separators.
+func NormalizePhoneNumber(raw string) (string, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(raw))
+
+	if cleaned == "" {
+		return "", errors.New("empty phone number")
+	}
+
+	var digits string
+	switch {
+	case strings.HasPrefix(cleaned, "+"):
+		digits = cleaned[1:]
+	case strings.HasPrefix(cleaned, "00"):
+		digits = cleaned[2:]
+	case strings.HasPrefix(cleaned, "62"):
+		digits = cleaned
+	case strings.HasPrefix(cleaned, "0"):
+		digits = "62" + cleaned[1:]
+	case strings.HasPrefix(cleaned, "8"):
+		digits = "62" + cleaned
+	default:
+		return "", errors.New("unsupported phone number format")
+	}
+
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", errors.New("phone number must only contain digits")
+		}
+	}
+
+	// E.164 allows at most 15 digits; Indonesian mobile numbers are at least 10.
+	if len(digits) < 10 || len(digits) > 15 {
+		return "", errors.New("invalid phone number length")
+	}
+
+	return "+" + digits, nil
+}
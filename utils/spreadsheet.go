@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rdsarjito/marketplace-backend/constants"
+	"github.com/xuri/excelize/v2"
+)
+
+// SpreadsheetFormat returns the spreadsheet format of a file name from its
+// extension, or "" if it is not a supported format.
+func SpreadsheetFormat(fileName string) string {
+	lower := strings.ToLower(fileName)
+	switch {
+	case strings.HasSuffix(lower, ".csv"):
+		return constants.SpreadsheetCSV
+	case strings.HasSuffix(lower, ".xlsx"):
+		return constants.SpreadsheetXLSX
+	}
+	return ""
+}
+
+// ReadSpreadsheet calls fn with each row of a CSV file, or of the first sheet
+// of an XLSX workbook, in order, so callers can stop early without holding
+// the whole file. Rows may have different lengths. An error from fn stops
+// reading and is returned as is.
+func ReadSpreadsheet(format string, r io.Reader, fn func(row []string) error) error {
+	switch format {
+	case constants.SpreadsheetCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		for first := true; ; first = false {
+			row, err := reader.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			// Spreadsheet programs often save CSV with a byte order mark
+			if first && len(row) > 0 {
+				row[0] = strings.TrimPrefix(row[0], "\ufeff")
+			}
+			if err := fn(unescapeSpreadsheetRow(row)); err != nil {
+				return err
+			}
+		}
+	case constants.SpreadsheetXLSX:
+		workbook, err := excelize.OpenReader(r)
+		if err != nil {
+			return err
+		}
+		defer workbook.Close()
+
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			return nil
+		}
+		rows, err := workbook.Rows(sheets[0])
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			row, err := rows.Columns()
+			if err != nil {
+				return err
+			}
+			if err := fn(unescapeSpreadsheetRow(row)); err != nil {
+				return err
+			}
+		}
+		return rows.Error()
+	}
+	return fmt.Errorf("unsupported spreadsheet format %q", format)
+}
+
+// escapeSpreadsheetCell stops a spreadsheet program from running a value as
+// a formula by prefixing it with an apostrophe.
+func escapeSpreadsheetCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeSpreadsheetRow undoes escapeSpreadsheetCell so exported files can
+// be imported again unchanged.
+func unescapeSpreadsheetRow(row []string) []string {
+	for i, value := range row {
+		if len(value) > 1 && value[0] == '\'' && escapeSpreadsheetCell(value[1:]) != value[1:] {
+			row[i] = value[1:]
+		}
+	}
+	return row
+}
+
+// WriteSpreadsheet writes rows as a CSV file or as the only sheet of an XLSX
+// workbook. Values that would start a formula are escaped.
+func WriteSpreadsheet(format string, rows [][]string) ([]byte, error) {
+	escaped := make([][]string, len(rows))
+	for i, row := range rows {
+		escaped[i] = make([]string, len(row))
+		for j, value := range row {
+			escaped[i][j] = escapeSpreadsheetCell(value)
+		}
+	}
+	rows = escaped
+
+	var buf bytes.Buffer
+	switch format {
+	case constants.SpreadsheetCSV:
+		writer := csv.NewWriter(&buf)
+		if err := writer.WriteAll(rows); err != nil {
+			return nil, err
+		}
+	case constants.SpreadsheetXLSX:
+		workbook := excelize.NewFile()
+		defer workbook.Close()
+
+		sheet := workbook.GetSheetName(0)
+		for i, row := range rows {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return nil, err
+			}
+			values := make([]interface{}, len(row))
+			for j, value := range row {
+				values[j] = value
+			}
+			if err := workbook.SetSheetRow(sheet, cell, &values); err != nil {
+				return nil, err
+			}
+		}
+		if err := workbook.Write(&buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported spreadsheet format %q", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// SpreadsheetContentType is the MIME type of a spreadsheet format.
+func SpreadsheetContentType(format string) string {
+	if format == constants.SpreadsheetXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}